@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/auth"
+	"code.local/internal/pkg/migrate"
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/server"
+	"code.local/migrations"
+)
+
+// usage describes the command line subcommands.
+const usage = `usage: backend [command]
+
+Without a command the HTTP server is started.
+
+Commands:
+  migrate up [N]           apply all pending migrations, or the next N
+  migrate down [N]         revert the last applied migration, or the last N
+  migrate status           list migrations and whether they are applied
+  apikey create NAME ROLE [OWNER]
+                           create an API key; ROLE is student, teacher, registrar or admin,
+                           student and teacher keys name the student or teacher ID they act for
+  apikey list              list API keys
+  apikey revoke ID         revoke an API key`
+
+// runCommand runs the subcommand given on the command line.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "apikey":
+		return runAPIKey(args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+}
+
+// runMigrate runs the migrate up, down and status subcommands.
+func runMigrate(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(usage)
+	}
+
+	steps := 0
+
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of migrations %q", args[1])
+		}
+
+		steps = n
+	}
+
+	pool, err := server.InitDB()
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := migrate.New(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, steps)
+		for _, migration := range applied {
+			log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		}
+
+		if err == nil && len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %04d_%s", migration.Version, migration.Name)
+		}
+
+		if err == nil && len(reverted) == 0 {
+			log.Println("No applied migrations")
+		}
+
+		return err
+	case "status":
+		if steps != 0 {
+			return errors.New(usage)
+		}
+
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return tw.Flush()
+	}
+
+	return errors.New(usage)
+}
+
+// runAPIKey runs the apikey create, list and revoke subcommands.
+// Creating the first admin key this way bootstraps access to the admin endpoints.
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	pool, err := server.InitDB()
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	// Key changes are recorded in the audit log as made by the command line
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "cli", Role: auth.RoleAdmin})
+
+	switch {
+	case args[0] == "create" && (len(args) == 3 || len(args) == 4):
+		keyReq := schema.CreateAPIKeyRequest{Name: args[1], Role: args[2]}
+
+		if !auth.ValidRole(keyReq.Role) {
+			return fmt.Errorf("invalid role %q; use student, teacher, registrar or admin", keyReq.Role)
+		}
+
+		ownerRole := keyReq.Role == auth.RoleStudent || keyReq.Role == auth.RoleTeacher
+		if ownerRole != (len(args) == 4) {
+			return fmt.Errorf("an owner ID is required for, and only allowed with, student and teacher keys\n\n%s", usage)
+		}
+
+		if ownerRole {
+			ownerID, err := strconv.Atoi(args[3])
+			if err != nil {
+				return fmt.Errorf("invalid owner ID %q", args[3])
+			}
+
+			if keyReq.Role == auth.RoleStudent {
+				keyReq.StudentID = &ownerID
+			} else {
+				keyReq.TeacherID = &ownerID
+			}
+		}
+
+		created, err := auth.CreateAPIKey(ctx, pool, keyReq)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Created API key %d (%s); it cannot be shown again:", created.ID, created.Prefix)
+		fmt.Println(created.Key)
+
+		return nil
+	case args[0] == "list" && len(args) == 1:
+		keys, err := auth.ListAPIKeys(ctx, pool)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tPREFIX\tNAME\tROLE\tSTATE")
+
+		for _, key := range keys {
+			state := "active"
+
+			switch {
+			case key.RevokedAt != nil:
+				state = "revoked"
+			case key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()):
+				state = "expired"
+			}
+
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", key.ID, key.Prefix, key.Name, key.Role, state)
+		}
+
+		return tw.Flush()
+	case args[0] == "revoke" && len(args) == 2:
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid API key ID %q", args[1])
+		}
+
+		revoked, err := auth.RevokeAPIKey(ctx, pool, id)
+		if err != nil {
+			return err
+		}
+
+		if !revoked {
+			return fmt.Errorf("API key %d not found or already revoked", id)
+		}
+
+		log.Printf("Revoked API key %d", id)
+
+		return nil
+	}
+
+	return errors.New(usage)
+}
+
+// migrateOnStartup applies all pending migrations before the server starts.
+// Concurrent instances wait for each other on the migration advisory lock.
+func migrateOnStartup(pool *pgxpool.Pool) error {
+	migrator, err := migrate.New(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background(), 0)
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+
+	return err
+}
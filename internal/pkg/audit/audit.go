@@ -0,0 +1,101 @@
+// Package audit attributes database changes to the request that made them.
+// The audit_log triggers read the actor and request ID from settings applied to each pooled connection.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/auth"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// settingsKey is the connection custom data key holding the settings last applied to the connection.
+const settingsKey = "audit.settings"
+
+// validRequestID matches client supplied request IDs that are safe to store and echo.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// contextKey is the context key of the request ID.
+type contextKey struct{}
+
+// RequestIDFromContext returns the request ID attached to ctx by Register.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+
+	return requestID
+}
+
+// Register adds request ID middleware to the provided handler.
+// A valid X-Request-ID header is kept, otherwise a random ID is generated; the ID is echoed in the response.
+func Register(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, requestID)))
+	})
+}
+
+// newRequestID returns a random 128-bit request ID.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
+
+// settings are the session settings read by the audit_changes trigger.
+type settings struct {
+	actor     string
+	actorRole string
+	requestID string
+}
+
+// BeforeAcquire is a pgxpool BeforeAcquire hook that applies the actor and request ID of ctx
+// to the connection, so that changes made through it are attributed to the request.
+// Connections used outside requests have the settings cleared.
+func BeforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	next := settings{requestID: RequestIDFromContext(ctx)}
+
+	if principal, ok := auth.FromContext(ctx); ok {
+		next.actor = principal.Subject
+		next.actorRole = principal.Role
+	}
+
+	data := conn.PgConn().CustomData()
+
+	// Skip the round trip when the connection already holds the settings
+	if current, _ := data[settingsKey].(settings); current == next {
+		return true
+	}
+
+	_, err := conn.Exec(ctx, `
+		SELECT
+			set_config('app.actor', $1, FALSE),
+			set_config('app.actor_role', $2, FALSE),
+			set_config('app.request_id', $3, FALSE)
+	`, next.actor, next.actorRole, next.requestID)
+	if err != nil {
+		log.Printf("Failed to apply audit settings: %v", err)
+
+		// The connection is destroyed and another one acquired
+		return false
+	}
+
+	data[settingsKey] = next
+
+	return true
+}
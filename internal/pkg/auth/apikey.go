@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/schema"
+)
+
+// apiKeyScheme starts every API key, which tells them apart from JWTs in the Authorization header.
+const apiKeyScheme = "sk_"
+
+// ErrInvalidAPIKey is returned for API keys that are unknown, revoked or expired.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// GenerateAPIKey creates a random API key of the form sk_<prefix>_<secret> and returns it with its prefix.
+// The prefix is stored in clear to look the key up; the whole key is only stored hashed.
+func GenerateAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 4+32)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(buf[:4])
+	key = apiKeyScheme + prefix + "_" + base64.RawURLEncoding.EncodeToString(buf[4:])
+
+	return key, prefix, nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of an API key.
+// A fast hash is sufficient because keys carry 256 random bits and cannot be guessed.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// isAPIKey reports whether a bearer credential is an API key rather than a JWT.
+func isAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyScheme)
+}
+
+// CreateAPIKey stores a new API key and returns it together with the secret key, which is not stored in clear.
+func CreateAPIKey(ctx context.Context, db *pgxpool.Pool, req schema.CreateAPIKeyRequest) (schema.CreatedAPIKey, error) {
+	key, prefix, err := GenerateAPIKey()
+	if err != nil {
+		return schema.CreatedAPIKey{}, err
+	}
+
+	created := schema.CreatedAPIKey{
+		Key: key,
+		APIKey: schema.APIKey{
+			Name:      req.Name,
+			Prefix:    prefix,
+			Role:      req.Role,
+			ExpiresAt: req.ExpiresAt,
+			StudentID: req.StudentID,
+			TeacherID: req.TeacherID,
+		},
+	}
+
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, role, expires_at, student_id, teacher_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err = db.QueryRow(ctx, query, req.Name, prefix, HashAPIKey(key), req.Role, req.ExpiresAt, req.StudentID, req.TeacherID).
+		Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return schema.CreatedAPIKey{}, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListAPIKeys returns every API key, including revoked and expired ones, ordered by ID.
+func ListAPIKeys(ctx context.Context, db *pgxpool.Pool) ([]schema.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, role::text, student_id, teacher_id, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		ORDER BY id
+	`
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API keys: %w", err)
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (schema.APIKey, error) {
+		var key schema.APIKey
+
+		err := row.Scan(
+			&key.ID, &key.Name, &key.Prefix, &key.Role, &key.StudentID, &key.TeacherID,
+			&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt,
+		)
+
+		return key, err
+	})
+}
+
+// RevokeAPIKey revokes an API key; revoked keys are kept for reference but no longer authenticate.
+// Returns false when no active key has the ID.
+func RevokeAPIKey(ctx context.Context, db *pgxpool.Pool, id int) (bool, error) {
+	result, err := db.Exec(ctx, `
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// authenticateAPIKey looks up an active API key and returns its principal.
+func authenticateAPIKey(ctx context.Context, db *pgxpool.Pool, key string) (Principal, error) {
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(key, apiKeyScheme), "_")
+	if !ok {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	var (
+		id        int
+		keyHash   string
+		role      string
+		expiresAt *time.Time
+		studentID *int
+		teacherID *int
+	)
+
+	err := db.QueryRow(ctx, `
+		SELECT id, key_hash, role::text, expires_at, student_id, teacher_id
+		FROM api_keys
+		WHERE prefix = $1 AND revoked_at IS NULL
+	`, prefix).Scan(&id, &keyHash, &role, &expiresAt, &studentID, &teacherID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Principal{}, ErrInvalidAPIKey
+		}
+
+		return Principal{}, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(HashAPIKey(key))) != 1 {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	if expiresAt != nil && time.Now().After(*expiresAt) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	// Recording every use would write on each request; a minute's precision is enough
+	_, err = db.Exec(ctx, `
+		UPDATE api_keys
+		SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, id)
+	if err != nil {
+		return Principal{}, fmt.Errorf("failed to record API key use: %w", err)
+	}
+
+	principal := Principal{
+		Subject: "api-key:" + strconv.Itoa(id),
+		Role:    role,
+		Method:  MethodAPIKey,
+	}
+
+	if studentID != nil {
+		principal.StudentID = *studentID
+	}
+
+	if teacherID != nil {
+		principal.TeacherID = *teacherID
+	}
+
+	return principal, nil
+}
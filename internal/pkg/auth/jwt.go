@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"code.local/internal/pkg/config"
+)
+
+// clockSkew is the leeway allowed when checking the exp and nbf claims.
+const clockSkew = time.Minute
+
+// ErrInvalidToken is returned for bearer tokens that are malformed, badly signed or expired.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the JWT claims used for authentication. Exp is required; iss and aud are checked when configured.
+// Student and teacher tokens carry the ID of the principal's own record.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Role      string   `json:"role"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	StudentID int      `json:"student_id,omitempty"`
+	TeacherID int      `json:"teacher_id,omitempty"`
+}
+
+// audience is the aud claim, which may be a single string or an array of strings.
+type audience []string
+
+// UnmarshalJSON accepts both forms of the aud claim.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// header is the JOSE header of a JWT.
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// JWTVerifier checks the signature and claims of JWT bearer tokens.
+// HS256 tokens are accepted when a secret is set, RS256 tokens when a public key is set.
+type JWTVerifier struct {
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	secret    []byte
+}
+
+// NewJWTVerifier creates a verifier; issuer and audience are only checked when not empty.
+func NewJWTVerifier(secret []byte, publicKey *rsa.PublicKey, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		publicKey: publicKey,
+		issuer:    issuer,
+		audience:  audience,
+		secret:    secret,
+	}
+}
+
+// JWTVerifierFromEnv creates a verifier from the JWT environment variables.
+// Returns nil when neither an HS256 secret nor an RS256 public key is configured.
+func JWTVerifierFromEnv() (*JWTVerifier, error) {
+	secret := []byte(os.Getenv(config.EnvJWTSecret))
+
+	var publicKey *rsa.PublicKey
+
+	if path := os.Getenv(config.EnvJWTPublicKeyFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+
+		publicKey, err = ParseRSAPublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(secret) == 0 && publicKey == nil {
+		return nil, nil
+	}
+
+	return NewJWTVerifier(secret, publicKey, os.Getenv(config.EnvJWTIssuer), os.Getenv(config.EnvJWTAudience)), nil
+}
+
+// ParseRSAPublicKey parses a PEM encoded RSA public key in PKIX or PKCS #1 form.
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in JWT public key")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("JWT public key is not an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+// Verify checks the token's signature and time, issuer and audience claims, and returns its claims.
+// The algorithm is taken from the token header but must match a configured key,
+// so a token cannot downgrade to "none" or be HMAC-signed with the RSA public key.
+func (v *JWTVerifier) Verify(token string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var hdr header
+
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	digest := sha256.Sum256(signed)
+
+	switch {
+	case hdr.Alg == "HS256" && len(v.secret) > 0:
+		if !hmac.Equal(signature, hmacSHA256(v.secret, signed)) {
+			return Claims{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case hdr.Alg == "RS256" && v.publicKey != nil:
+		if err := rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return Claims{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return Claims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, hdr.Alg)
+	}
+
+	var claims Claims
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	switch {
+	case claims.ExpiresAt == 0:
+		return Claims{}, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)):
+		return Claims{}, fmt.Errorf("%w: token has expired", ErrInvalidToken)
+	case claims.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)):
+		return Claims{}, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return Claims{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	case v.audience != "" && !slices.Contains(claims.Audience, v.audience):
+		return Claims{}, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	case claims.Subject == "":
+		return Claims{}, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	case !ValidRole(claims.Role):
+		return Claims{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, claims.Role)
+	}
+
+	return claims, nil
+}
+
+// SignHS256 creates an HS256 signed token with the given claims,
+// e.g. for development or for services that share the secret.
+func SignHS256(claims Claims, secret []byte) (string, error) {
+	hdr, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(hmacSHA256(secret, []byte(signed))), nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// hmacSHA256 computes the HMAC-SHA256 of data.
+func hmacSHA256(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
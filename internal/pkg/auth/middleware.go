@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/utils"
+)
+
+// Authenticator authenticates requests with API keys stored in the database or JWT bearer tokens.
+type Authenticator struct {
+	db  *pgxpool.Pool
+	jwt *JWTVerifier
+}
+
+// New creates an Authenticator; with a nil verifier only API keys are accepted.
+func New(db *pgxpool.Pool, jwt *JWTVerifier) *Authenticator {
+	return &Authenticator{
+		db:  db,
+		jwt: jwt,
+	}
+}
+
+// Register adds authentication middleware to the provided handler.
+// Every request must carry an "Authorization: Bearer <credential>" header holding an API key (sk_...)
+// or a JWT; the authenticated principal is attached to the request context, see FromContext.
+func (a *Authenticator) Register(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, credential, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || credential == "" {
+			sendUnauthenticated(w, "Missing bearer credentials")
+
+			return
+		}
+
+		var (
+			principal Principal
+			err       error
+		)
+
+		switch {
+		case isAPIKey(credential):
+			principal, err = authenticateAPIKey(r.Context(), a.db, credential)
+		case a.jwt != nil:
+			var claims Claims
+
+			claims, err = a.jwt.Verify(credential, time.Now())
+			principal = Principal{
+				Subject:   claims.Subject,
+				Role:      claims.Role,
+				Method:    MethodJWT,
+				StudentID: claims.StudentID,
+				TeacherID: claims.TeacherID,
+			}
+		default:
+			err = ErrInvalidToken
+		}
+
+		if err != nil {
+			if !errors.Is(err, ErrInvalidAPIKey) && !errors.Is(err, ErrInvalidToken) {
+				log.Printf("Authentication failed: %v", err)
+				utils.SendError(w, http.StatusInternalServerError, "Failed to authenticate request")
+
+				return
+			}
+
+			sendUnauthenticated(w, "Invalid or expired credentials")
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// sendUnauthenticated responds with 401 Unauthorized and a bearer challenge.
+func sendUnauthenticated(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	utils.SendErrorCode(w, http.StatusUnauthorized, "unauthenticated", message)
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/utils"
+)
+
+// Owners looks up who owns the resources that ownership rules refer to.
+type Owners interface {
+	// SectionTeacher returns the ID of the teacher of a section, or 0 when the section does not exist.
+	SectionTeacher(ctx context.Context, sectionID int) (int, error)
+}
+
+// Source locates the ID an ownership rule checks, in a path parameter or the JSON request body.
+type Source struct {
+	path string
+	body func(data []byte) (int, bool)
+}
+
+// Path is the Source of an ID held by the named path parameter.
+func Path(name string) Source {
+	return Source{path: name}
+}
+
+// Body is the Source of an ID read from the JSON request body decoded as T.
+// T must be the handler's own request type, so that the policy checks the same
+// value the handler acts on; encoding/json matches keys case-insensitively and
+// keeps the last duplicate, which a lookup by exact field name would not.
+func Body[T any](id func(T) int) Source {
+	return Source{body: func(data []byte) (int, bool) {
+		var req T
+
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&req); err != nil {
+			return 0, false
+		}
+
+		return id(req), true
+	}}
+}
+
+// id extracts the ID from the request; a consumed body is restored for the handler.
+func (s Source) id(r *http.Request) (int, bool) {
+	if s.path != "" {
+		id, err := strconv.Atoi(r.PathValue(s.path))
+
+		return id, err == nil
+	}
+
+	if s.body == nil || r.Body == nil {
+		return 0, false
+	}
+
+	data, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if err != nil {
+		return 0, false
+	}
+
+	return s.body(data)
+}
+
+// rule grants a role access to resources the principal owns.
+type rule struct {
+	owns   func(ctx context.Context, owners Owners, principal Principal, id int) (bool, error)
+	role   string
+	source Source
+}
+
+// Policy declares which principals may call a route: the roles allowed outright
+// and the ownership rules that let students and teachers act on their own records.
+// Admins are allowed by every policy.
+type Policy struct {
+	roles []string
+	rules []rule
+}
+
+// Allow returns a policy allowing the roles, and admins, on every resource of the route.
+func Allow(roles ...string) Policy {
+	return Policy{roles: roles}
+}
+
+// Predefined policies.
+var (
+	// Authenticated allows every authenticated principal.
+	Authenticated = Allow(Roles...)
+	// AdminOnly allows admins only.
+	AdminOnly = Allow()
+)
+
+// OrStudentSelf additionally allows students whose own student ID is given by source.
+func (p Policy) OrStudentSelf(source Source) Policy {
+	return p.or(rule{role: RoleStudent, source: source, owns: ownsStudent})
+}
+
+// OrTeacherSelf additionally allows teachers whose own teacher ID is given by source.
+func (p Policy) OrTeacherSelf(source Source) Policy {
+	return p.or(rule{role: RoleTeacher, source: source, owns: ownsTeacher})
+}
+
+// OrSectionTeacher additionally allows teachers who teach the section whose ID is given by source.
+func (p Policy) OrSectionTeacher(source Source) Policy {
+	return p.or(rule{role: RoleTeacher, source: source, owns: teachesSection})
+}
+
+// or returns a copy of the policy with one more rule, leaving p unchanged.
+func (p Policy) or(r rule) Policy {
+	p.rules = append(slices.Clip(p.rules), r)
+
+	return p
+}
+
+// Allows reports whether the principal may make the request.
+func (p Policy) Allows(ctx context.Context, owners Owners, principal Principal, r *http.Request) (bool, error) {
+	if principal.Role == RoleAdmin || slices.Contains(p.roles, principal.Role) {
+		return true, nil
+	}
+
+	for _, rule := range p.rules {
+		if rule.role != principal.Role {
+			continue
+		}
+
+		id, ok := rule.source.id(r)
+		if !ok {
+			continue
+		}
+
+		owned, err := rule.owns(ctx, owners, principal, id)
+		if err != nil || owned {
+			return owned, err
+		}
+	}
+
+	return false, nil
+}
+
+// ownsStudent reports whether the student ID is the principal's own.
+func ownsStudent(_ context.Context, _ Owners, principal Principal, id int) (bool, error) {
+	return principal.StudentID != 0 && principal.StudentID == id, nil
+}
+
+// ownsTeacher reports whether the teacher ID is the principal's own.
+func ownsTeacher(_ context.Context, _ Owners, principal Principal, id int) (bool, error) {
+	return principal.TeacherID != 0 && principal.TeacherID == id, nil
+}
+
+// teachesSection reports whether the principal teaches the section.
+func teachesSection(ctx context.Context, owners Owners, principal Principal, sectionID int) (bool, error) {
+	if principal.TeacherID == 0 {
+		return false, nil
+	}
+
+	teacherID, err := owners.SectionTeacher(ctx, sectionID)
+
+	return teacherID == principal.TeacherID, err
+}
+
+// Authorizer enforces policies on handlers.
+type Authorizer struct {
+	owners Owners
+}
+
+// NewAuthorizer creates an Authorizer resolving ownership with owners.
+func NewAuthorizer(owners Owners) *Authorizer {
+	return &Authorizer{
+		owners: owners,
+	}
+}
+
+// Require wraps a handler so that it is only called when the policy allows the request principal.
+func (a *Authorizer) Require(policy Policy, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := FromContext(r.Context())
+		if !ok {
+			sendUnauthenticated(w, "Missing bearer credentials")
+
+			return
+		}
+
+		allowed, err := policy.Allows(r.Context(), a.owners, principal, r)
+		if err != nil {
+			log.Printf("Authorization failed: %v", err)
+			utils.SendError(w, http.StatusInternalServerError, "Failed to authorize request")
+
+			return
+		}
+
+		if !allowed {
+			utils.SendErrorCode(w, http.StatusForbidden, "forbidden", "The "+principal.Role+" role cannot access this resource")
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// dbOwners looks resource owners up in the database.
+type dbOwners struct {
+	db *pgxpool.Pool
+}
+
+// NewOwners creates Owners backed by the database.
+func NewOwners(db *pgxpool.Pool) Owners {
+	return &dbOwners{
+		db: db,
+	}
+}
+
+// SectionTeacher implements Owners.
+func (o *dbOwners) SectionTeacher(ctx context.Context, sectionID int) (int, error) {
+	var teacherID int
+
+	err := o.db.QueryRow(ctx, `SELECT teacher_id FROM sections WHERE id = $1`, sectionID).Scan(&teacherID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to look up section teacher: %w", err)
+	}
+
+	return teacherID, nil
+}
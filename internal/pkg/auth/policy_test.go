@@ -0,0 +1,167 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.local/internal/pkg/auth"
+)
+
+// enrollmentRequest mirrors the request type of an enrollment handler.
+type enrollmentRequest struct {
+	StudentID int `json:"student_id"`
+	SectionID int `json:"section_id"`
+}
+
+// enrollmentStudent returns the student ID of an enrollment request.
+func enrollmentStudent(req enrollmentRequest) int {
+	return req.StudentID
+}
+
+// sectionTeachers maps section IDs to the IDs of their teachers.
+type sectionTeachers map[int]int
+
+// SectionTeacher implements auth.Owners.
+func (s sectionTeachers) SectionTeacher(_ context.Context, sectionID int) (int, error) {
+	if sectionID < 0 {
+		return 0, errors.New("lookup failed")
+	}
+
+	return s[sectionID], nil
+}
+
+func TestPolicyAllows(t *testing.T) {
+	registrar := auth.Allow(auth.RoleRegistrar)
+	ownStudent := registrar.OrStudentSelf(auth.Path("id"))
+	ownEnrollment := registrar.OrStudentSelf(auth.Body(enrollmentStudent))
+	sectionTeacher := registrar.OrSectionTeacher(auth.Path("id"))
+	ownAvailability := registrar.OrTeacherSelf(auth.Path("id"))
+
+	owners := sectionTeachers{10: 7, 11: 8}
+
+	admin := auth.Principal{Role: auth.RoleAdmin}
+	registrarPrincipal := auth.Principal{Role: auth.RoleRegistrar}
+	student := auth.Principal{Role: auth.RoleStudent, StudentID: 5}
+	unlinkedStudent := auth.Principal{Role: auth.RoleStudent}
+	teacher := auth.Principal{Role: auth.RoleTeacher, TeacherID: 7}
+
+	tests := []struct {
+		name      string
+		policy    auth.Policy
+		principal auth.Principal
+		id        string
+		body      string
+		allowed   bool
+	}{
+		{name: "admin on admin only route", policy: auth.AdminOnly, principal: admin, allowed: true},
+		{name: "registrar on admin only route", policy: auth.AdminOnly, principal: registrarPrincipal},
+		{name: "student on authenticated route", policy: auth.Authenticated, principal: student, allowed: true},
+		{name: "registrar on registrar route", policy: registrar, principal: registrarPrincipal, allowed: true},
+		{name: "admin on registrar route", policy: registrar, principal: admin, allowed: true},
+		{name: "student on registrar route", policy: registrar, principal: student},
+		{name: "student on own record", policy: ownStudent, principal: student, id: "5", allowed: true},
+		{name: "student on other record", policy: ownStudent, principal: student, id: "6"},
+		{name: "student without a record", policy: ownStudent, principal: unlinkedStudent, id: "0"},
+		{name: "student with invalid ID", policy: ownStudent, principal: student, id: "abc"},
+		{name: "teacher on student record", policy: ownStudent, principal: teacher, id: "7"},
+		{name: "registrar on any student", policy: ownStudent, principal: registrarPrincipal, id: "6", allowed: true},
+		{name: "student enrolling self", policy: ownEnrollment, principal: student, body: `{"student_id":5,"section_id":3}`, allowed: true},
+		{name: "student enrolling other", policy: ownEnrollment, principal: student, body: `{"student_id":6,"section_id":3}`},
+		{name: "student enrolling without ID", policy: ownEnrollment, principal: student, body: `{"section_id":3}`},
+		{name: "student enrolling other by key case", policy: ownEnrollment, principal: student, body: `{"student_id":5,"STUDENT_ID":6,"section_id":3}`},
+		{name: "student enrolling self by key case", policy: ownEnrollment, principal: student, body: `{"STUDENT_ID":6,"student_id":5,"section_id":3}`, allowed: true},
+		{name: "student with malformed body", policy: ownEnrollment, principal: student, body: `{"student_id":`},
+		{name: "teacher of section", policy: sectionTeacher, principal: teacher, id: "10", allowed: true},
+		{name: "teacher of other section", policy: sectionTeacher, principal: teacher, id: "11"},
+		{name: "teacher of unknown section", policy: sectionTeacher, principal: teacher, id: "12"},
+		{name: "student on section roster", policy: sectionTeacher, principal: student, id: "10"},
+		{name: "teacher on own availability", policy: ownAvailability, principal: teacher, id: "7", allowed: true},
+		{name: "teacher on other availability", policy: ownAvailability, principal: teacher, id: "8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+
+			allowed, err := tt.policy.Allows(context.Background(), owners, tt.principal, req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if allowed != tt.allowed {
+				t.Errorf("Expected allowed to be %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestPolicyRestoresBody(t *testing.T) {
+	policy := auth.Allow(auth.RoleRegistrar).OrStudentSelf(auth.Body(enrollmentStudent))
+	body := `{"student_id":5,"section_id":3}`
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	allowed, err := policy.Allows(context.Background(), sectionTeachers{}, auth.Principal{Role: auth.RoleStudent, StudentID: 5}, req)
+	if err != nil || !allowed {
+		t.Fatalf("Expected the student to be allowed, got %v (%v)", allowed, err)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	if string(data) != body {
+		t.Errorf("Expected the handler to receive body %q, got %q", body, data)
+	}
+}
+
+func TestPolicyOwnerLookupError(t *testing.T) {
+	policy := auth.Allow(auth.RoleRegistrar).OrSectionTeacher(auth.Path("id"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.SetPathValue("id", "-1")
+
+	if _, err := policy.Allows(context.Background(), sectionTeachers{}, auth.Principal{Role: auth.RoleTeacher, TeacherID: 7}, req); err == nil {
+		t.Error("Expected the lookup error to be returned")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	authorizer := auth.NewAuthorizer(sectionTeachers{})
+	handler := authorizer.Require(auth.Allow(auth.RoleRegistrar), func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		status    int
+	}{
+		{name: "unauthenticated", status: http.StatusUnauthorized},
+		{name: "forbidden", principal: &auth.Principal{Role: auth.RoleStudent}, status: http.StatusForbidden},
+		{name: "allowed", principal: &auth.Principal{Role: auth.RoleRegistrar}, status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tt.principal))
+			}
+
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package auth authenticates API requests with hashed API keys or JWT bearer tokens,
+// attaches the authenticated principal to the request context and authorizes it with per-route policies.
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Roles a principal can hold, from least to most privileged.
+const (
+	RoleStudent   = "student"
+	RoleTeacher   = "teacher"
+	RoleRegistrar = "registrar"
+	RoleAdmin     = "admin"
+)
+
+// Roles lists the valid roles, matching the principal_role database type.
+var Roles = []string{RoleStudent, RoleTeacher, RoleRegistrar, RoleAdmin}
+
+// Authentication methods of a principal.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller: the JWT sub claim, or "api-key:<id>" for API keys.
+	Subject string
+	Role    string
+	Method  string
+	// StudentID and TeacherID link student and teacher principals to their own records.
+	StudentID int
+	TeacherID int
+}
+
+// contextKey is the context key of the request principal.
+type contextKey struct{}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal attached to ctx by the authentication middleware.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(Principal)
+
+	return principal, ok
+}
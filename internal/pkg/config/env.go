@@ -22,6 +22,26 @@ const EnvDBPassword = "DB_PASSWORD"
 // Specifies which database to connect to on the PostgreSQL server
 const EnvDBName = "DB_NAME"
 
+// EnvDBAutoMigrate is the environment variable name enabling migrations on startup
+// Example value: "true"; pending migrations are applied before the server starts listening
+const EnvDBAutoMigrate = "DB_AUTO_MIGRATE"
+
+// EnvJWTSecret is the environment variable name for the shared secret of HS256 signed JWTs
+// HS256 tokens are rejected when it is empty
+const EnvJWTSecret = "JWT_HS256_SECRET"
+
+// EnvJWTPublicKeyFile is the environment variable name for the path of the PEM encoded RSA public key
+// used to verify RS256 signed JWTs; RS256 tokens are rejected when it is empty
+const EnvJWTPublicKeyFile = "JWT_RS256_PUBLIC_KEY_FILE"
+
+// EnvJWTIssuer is the environment variable name for the expected iss claim of JWTs
+// Example value: "https://auth.university.edu"; not checked when empty
+const EnvJWTIssuer = "JWT_ISSUER"
+
+// EnvJWTAudience is the environment variable name for the expected aud claim of JWTs
+// Example value: "course-scheduling"; not checked when empty
+const EnvJWTAudience = "JWT_AUDIENCE"
+
 // EnvBindAddrPort is the environment variable name for the web server port
 // Example value: ":8080" (note the colon prefix for Go's HTTP server)
 const EnvBindAddrPort = "APP_PORT"
@@ -17,6 +17,7 @@ func Register(next http.Handler) http.Handler {
 				http.MethodGet,
 				http.MethodPost,
 				http.MethodPut,
+				http.MethodPatch,
 				http.MethodDelete,
 				http.MethodOptions,
 			},
@@ -24,7 +25,7 @@ func Register(next http.Handler) http.Handler {
 		))
 
 		// Define allowed headers for cross-origin requests
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
 
 		// Handle preflight OPTIONS requests immediately with a 200 OK response
 		if r.Method == http.MethodOptions {
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/auth"
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// GetAPIKeys handles HTTP GET requests to list API keys.
+// Returns every key, including revoked and expired ones, without the secret.
+func (h *Handlers) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := auth.ListAPIKeys(r.Context(), h.db)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch API keys")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey handles HTTP POST requests to create an API key for a service integration.
+// Returns 201 Created with the key; the secret is only part of this response and cannot be retrieved later.
+func (h *Handlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var keyReq schema.CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&keyReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateAPIKey(keyReq); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	created, err := auth.CreateAPIKey(r.Context(), h.db, keyReq)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // Foreign key violation
+			utils.SendErrorCode(w, http.StatusNotFound, "reference_not_found", "Student or teacher not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to create API key")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, created)
+}
+
+// RevokeAPIKey handles HTTP DELETE requests to revoke an API key.
+// The key stops authenticating immediately but stays listed with its revocation time.
+func (h *Handlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid API key ID")
+
+		return
+	}
+
+	revoked, err := auth.RevokeAPIKey(r.Context(), h.db, id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to revoke API key")
+
+		return
+	}
+
+	if !revoked {
+		utils.SendError(w, http.StatusNotFound, "API key not found or already revoked")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "API key revoked successfully"})
+}
+
+// validateAPIKey checks the name, role, expiry and owner of an API key create request.
+// Returns the invalid fields, or nil when the request is valid.
+func validateAPIKey(keyReq schema.CreateAPIKeyRequest) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(keyReq.Name == "", "name", "Name is required")
+
+	if !auth.ValidRole(keyReq.Role) {
+		errs.Add("role", "invalid", "Role must be student, teacher, registrar, or admin")
+	}
+
+	if keyReq.ExpiresAt != nil && !keyReq.ExpiresAt.After(time.Now()) {
+		errs.Add("expires_at", "invalid", "Expiry must be in the future")
+	}
+
+	errs.Required(keyReq.Role == auth.RoleStudent && keyReq.StudentID == nil, "student_id", "Student keys require a student ID")
+	errs.Required(keyReq.Role == auth.RoleTeacher && keyReq.TeacherID == nil, "teacher_id", "Teacher keys require a teacher ID")
+
+	if keyReq.Role != auth.RoleStudent && keyReq.StudentID != nil {
+		errs.Add("student_id", "invalid", "Only student keys can have a student ID")
+	}
+
+	if keyReq.Role != auth.RoleTeacher && keyReq.TeacherID != nil {
+		errs.Add("teacher_id", "invalid", "Only teacher keys can have a teacher ID")
+	}
+
+	return errs
+}
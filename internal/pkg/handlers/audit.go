@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// auditList describes the filters and sort orders accepted by GetAuditLog.
+var auditList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "occurred_at": "timestamptz",
+	},
+	defaultSort: "-id",
+	filters: []filter{
+		{param: "entity", cond: "entity = %s", parse: textParam},
+		{param: "entity_id", cond: "entity_id = %s::int", parse: intParam},
+		{param: "actor", cond: "actor = %s", parse: textParam},
+		{param: "action", cond: "action = %s", parse: auditActionParam},
+		{param: "request_id", cond: "request_id = %s", parse: textParam},
+		{param: "since", cond: "occurred_at >= %s::timestamptz", parse: timestampParam},
+	},
+}
+
+// GetAuditLog handles HTTP GET requests to retrieve a page of the audit log.
+// Returns recorded changes newest first with their before and after row images.
+// Supports the entity, entity_id, actor, action, request_id and since filters, sorting and cursor pagination.
+func (h *Handlers) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	list, msg := parseList(r, auditList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
+		SELECT id, occurred_at, actor, actor_role, request_id, action, entity, entity_id, before, after
+		FROM audit_log
+	`)
+
+	rows, err := h.db.Query(r.Context(), query, list.args...)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch audit log")
+
+		return
+	}
+	defer rows.Close()
+
+	entries := []schema.AuditEntry{}
+
+	for rows.Next() {
+		var (
+			entry schema.AuditEntry
+			key   []string
+		)
+
+		err := rows.Scan(
+			&entry.ID, &entry.OccurredAt, &entry.Actor, &entry.ActorRole, &entry.RequestID,
+			&entry.Action, &entry.Entity, &entry.EntityID, &entry.Before, &entry.After, &key,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan audit entry")
+
+			return
+		}
+
+		list.add(entry.ID, key)
+		entries = append(entries, entry)
+	}
+
+	utils.SendJSON(w, http.StatusOK, entries[:list.paginate(w, r)])
+}
+
+// auditActionParam validates an audit log action filter value.
+func auditActionParam(value string) (any, error) {
+	if value != "create" && value != "update" && value != "delete" {
+		return nil, fmt.Errorf("invalid action %q", value)
+	}
+
+	return value, nil
+}
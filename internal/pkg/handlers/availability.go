@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/solver"
+	"code.local/internal/pkg/utils"
+)
+
+// GetTeacherAvailability handles HTTP GET requests to retrieve the weekly windows in which a teacher can teach.
+// An empty list means the teacher is available at any time.
+func (h *Handlers) GetTeacherAvailability(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid teacher ID")
+
+		return
+	}
+
+	if _, err := h.fetchTeacher(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Teacher not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch teacher")
+
+		return
+	}
+
+	windows, err := h.fetchAvailability(r.Context(), id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch availability")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, windows)
+}
+
+// SetTeacherAvailability handles HTTP PUT requests to replace the weekly windows in which a teacher can teach.
+// Accepts a list of windows; an empty list makes the teacher available at any time.
+// Returns the stored windows.
+func (h *Handlers) SetTeacherAvailability(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid teacher ID")
+
+		return
+	}
+
+	var windows []schema.AvailabilityWindow
+
+	if err := json.NewDecoder(r.Body).Decode(&windows); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateAvailability(windows); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	var locked int
+
+	err = tx.QueryRow(r.Context(), `SELECT id FROM teachers WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Teacher not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch teacher")
+
+		return
+	}
+
+	if _, err := tx.Exec(r.Context(), `DELETE FROM teacher_availability WHERE teacher_id = $1`, id); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to clear availability")
+
+		return
+	}
+
+	insertQuery := `
+		INSERT INTO teacher_availability (teacher_id, day, start_time, end_time)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	for _, window := range windows {
+		if _, err := tx.Exec(r.Context(), insertQuery, id, window.Day, window.StartTime, window.EndTime); err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to add availability window")
+
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	stored, err := h.fetchAvailability(r.Context(), id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch availability")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, stored)
+}
+
+// fetchAvailability loads the availability windows of a teacher ordered by day and start time.
+func (h *Handlers) fetchAvailability(ctx context.Context, teacherID int) ([]schema.AvailabilityWindow, error) {
+	query := `
+		SELECT day::text, start_time::text, end_time::text
+		FROM teacher_availability
+		WHERE teacher_id = $1
+		ORDER BY day, start_time
+	`
+
+	rows, err := h.db.Query(ctx, query, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (schema.AvailabilityWindow, error) {
+		var window schema.AvailabilityWindow
+
+		err := row.Scan(&window.Day, &window.StartTime, &window.EndTime)
+
+		return window, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if windows == nil {
+		windows = []schema.AvailabilityWindow{}
+	}
+
+	return windows, nil
+}
+
+// validateAvailability checks the day and times of each availability window.
+// Returns the invalid fields, or nil when the windows are valid.
+func validateAvailability(windows []schema.AvailabilityWindow) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	for i, window := range windows {
+		path := fmt.Sprintf("[%d]", i)
+
+		if _, ok := weekdays[window.Day]; !ok {
+			errs.Add(path+".day", "invalid", "Days must be monday, tuesday, wednesday, thursday, friday, or saturday")
+		}
+
+		start, err := solver.ParseClock(window.StartTime)
+		if err != nil {
+			errs.Add(path+".start_time", "invalid", "Start time must be in HH:MM format")
+
+			continue
+		}
+
+		end, err := solver.ParseClock(window.EndTime)
+		if err != nil {
+			errs.Add(path+".end_time", "invalid", "End time must be in HH:MM format")
+
+			continue
+		}
+
+		if end <= start {
+			errs.Add(path+".end_time", "invalid", "End time must be after start time")
+		}
+	}
+
+	return errs
+}
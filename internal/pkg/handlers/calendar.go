@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/ical"
+	"code.local/internal/pkg/utils"
+)
+
+// weekdays maps day_of_week values to Go weekdays; the scheduling policy decides which of them sections may meet on.
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// DownloadStudentCalendar handles HTTP GET requests to export a student's schedule as iCalendar (.ics).
+// Accepts a student ID path parameter and an optional term_id query parameter.
+// Each meeting pattern of an enrolled section becomes a weekly recurring event bounded by its term's dates.
+// Event UIDs are derived from the classroom, start time and duration of the pattern, so adding, removing
+// or retiming one meeting of a section leaves the events of its other meetings in place on re-import.
+func (h *Handlers) DownloadStudentCalendar(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	termID, err := optionalIntQuery(r, "term_id")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
+	student, err := h.fetchStudent(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Student not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch student info")
+
+		return
+	}
+
+	query := `
+		SELECT
+			sec.id, sub.code, sub.name, sec.section_code,
+			t.first_name, t.last_name, t.email, c.id, c.building, c.room_number,
+			m.start_time::text, m.duration_minutes, m.days::text[],
+			term.start_date::text, term.end_date::text, sec.updated_at
+		FROM enrollments e
+		JOIN sections sec ON e.section_id = sec.id
+		JOIN terms term ON sec.term_id = term.id
+		JOIN subjects sub ON sec.subject_id = sub.id
+		JOIN teachers t ON sec.teacher_id = t.id
+		JOIN section_meeting_view m ON sec.id = m.section_id
+		JOIN classrooms c ON m.classroom_id = c.id
+		WHERE e.student_id = $1 AND ($2::int IS NULL OR sec.term_id = $2)
+		ORDER BY term.start_date, sub.code, sec.section_code, sec.id, m.days[1], m.start_time
+	`
+
+	rows, err := h.db.Query(r.Context(), query, id, termID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch schedule")
+
+		return
+	}
+	defer rows.Close()
+
+	calendar := ical.Calendar{
+		Name: fmt.Sprintf("Schedule for %s %s", student.FirstName, student.LastName),
+	}
+
+	for rows.Next() {
+		var (
+			sectionID, classroomID                int
+			subjectCode, subjectName, sectionCode string
+			firstName, lastName, email            string
+			building, roomNumber                  string
+			startTime                             string
+			durationMinutes                       int
+			days                                  []string
+			startDate, endDate                    string
+			updatedAt                             time.Time
+		)
+
+		err := rows.Scan(
+			&sectionID, &subjectCode, &subjectName, &sectionCode,
+			&firstName, &lastName, &email, &classroomID, &building, &roomNumber,
+			&startTime, &durationMinutes, &days,
+			&startDate, &endDate, &updatedAt,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan schedule item")
+
+			return
+		}
+
+		start, err := time.Parse(time.DateOnly+" "+time.TimeOnly, startDate+" "+startTime)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to parse section start")
+
+			return
+		}
+
+		until, err := time.Parse(time.DateOnly, endDate)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to parse term end date")
+
+			return
+		}
+
+		// The pattern is identified by what the section meeting view groups it by, not by its position
+		uid := fmt.Sprintf("section-%d-room-%d-%s-%dmin-student-%d@course-scheduling",
+			sectionID, classroomID, start.Format("1504"), durationMinutes, student.ID)
+
+		event := ical.Event{
+			Start:          start,
+			Until:          until,
+			Stamp:          updatedAt,
+			UID:            uid,
+			Summary:        fmt.Sprintf("%s-%s %s", subjectCode, sectionCode, subjectName),
+			Description:    fmt.Sprintf("Instructor: %s %s", firstName, lastName),
+			Location:       fmt.Sprintf("%s %s", building, roomNumber),
+			OrganizerName:  fmt.Sprintf("%s %s", firstName, lastName),
+			OrganizerEmail: email,
+			Duration:       time.Duration(durationMinutes) * time.Minute,
+		}
+
+		for _, day := range days {
+			event.Days = append(event.Days, weekdays[day])
+		}
+
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := calendar.WriteTo(&buf); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to generate calendar")
+
+		return
+	}
+
+	// Set headers for download
+	fileName := fmt.Sprintf("schedule_%s_%s.ics", student.FirstName, student.LastName)
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	// Send calendar
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
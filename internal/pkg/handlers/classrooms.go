@@ -1,27 +1,49 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
 	"code.local/internal/pkg/schema"
 	"code.local/internal/pkg/utils"
 )
 
-// GetClassrooms handles HTTP GET requests to retrieve all classrooms.
-// Returns an ordered list of all classroom records from the database.
+// classroomList describes the filters and sort orders accepted by GetClassrooms.
+var classroomList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "building": "text", "room_number": "text", "capacity": "int", "created_at": "timestamptz",
+	},
+	defaultSort: "building,room_number",
+	filters: []filter{
+		{param: "building", cond: "building = %s::text", parse: textParam},
+		{param: "min_capacity", cond: "capacity >= %s::int", parse: intParam},
+	},
+}
+
+// GetClassrooms handles HTTP GET requests to retrieve a page of classrooms.
+// Supports the building and min_capacity filters, sorting and cursor pagination;
+// results are ordered by building, then room number unless a sort parameter is given.
 func (h *Handlers) GetClassrooms(w http.ResponseWriter, r *http.Request) {
-	query := `
+	list, msg := parseList(r, classroomList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
 		SELECT id, building, room_number, capacity, created_at, updated_at
 		FROM classrooms
-		ORDER BY building, room_number
-	`
+	`)
 
-	rows, err := h.db.Query(r.Context(), query)
+	rows, err := h.db.Query(r.Context(), query, list.args...)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch classrooms")
 
@@ -32,11 +54,14 @@ func (h *Handlers) GetClassrooms(w http.ResponseWriter, r *http.Request) {
 	var classrooms []schema.Classroom
 
 	for rows.Next() {
-		var classroom schema.Classroom
+		var (
+			classroom schema.Classroom
+			key       []string
+		)
 
 		err := rows.Scan(
 			&classroom.ID, &classroom.Building, &classroom.RoomNumber,
-			&classroom.Capacity, &classroom.CreatedAt, &classroom.UpdatedAt,
+			&classroom.Capacity, &classroom.CreatedAt, &classroom.UpdatedAt, &key,
 		)
 		if err != nil {
 			utils.SendError(w, http.StatusInternalServerError, "Failed to scan classroom")
@@ -44,10 +69,11 @@ func (h *Handlers) GetClassrooms(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		list.add(classroom.ID, key)
 		classrooms = append(classrooms, classroom)
 	}
 
-	utils.SendJSON(w, http.StatusOK, classrooms)
+	utils.SendJSON(w, http.StatusOK, classrooms[:list.paginate(w, r)])
 }
 
 // CreateClassroom handles HTTP POST requests to create a new classroom.
@@ -61,8 +87,8 @@ func (h *Handlers) CreateClassroom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if classroom.Building == "" || classroom.RoomNumber == "" || classroom.Capacity <= 0 {
-		utils.SendError(w, http.StatusBadRequest, "Building, room number, and a positive capacity are required")
+	if errs := validateClassroom(classroom); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
 
 		return
 	}
@@ -84,7 +110,7 @@ func (h *Handlers) CreateClassroom(w http.ResponseWriter, r *http.Request) {
 		var pgErr *pgconn.PgError
 
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			utils.SendError(w, http.StatusConflict, "A classroom with this building and room number already exists")
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A classroom with this building and room number already exists")
 
 			return
 		}
@@ -96,3 +122,305 @@ func (h *Handlers) CreateClassroom(w http.ResponseWriter, r *http.Request) {
 
 	utils.SendJSON(w, http.StatusCreated, classroom)
 }
+
+// GetClassroomByID handles HTTP GET requests to retrieve a specific classroom by ID.
+// Accepts a classroom ID path parameter and returns the matching classroom record or a not found error.
+func (h *Handlers) GetClassroomByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid classroom ID")
+
+		return
+	}
+
+	classroom, err := h.fetchClassroom(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Classroom not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch classroom")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, classroom)
+}
+
+// GetClassroomOccupancy handles HTTP GET requests to retrieve a classroom's weekly bookings.
+// Accepts a classroom ID path parameter and an optional term_id query parameter,
+// and returns one entry per section meeting ordered by day and start time.
+func (h *Handlers) GetClassroomOccupancy(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid classroom ID")
+
+		return
+	}
+
+	termID, err := optionalIntQuery(r, "term_id")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
+	if _, err := h.fetchClassroom(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Classroom not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch classroom")
+
+		return
+	}
+
+	query := `
+		SELECT
+			m.day::text, m.start_time::text,
+			(m.start_time + (m.duration_minutes || ' minutes')::INTERVAL)::text,
+			sub.code, s.section_code, t.first_name, t.last_name, s.term_id, s.id
+		FROM section_meetings m
+		JOIN sections s ON m.section_id = s.id
+		JOIN subjects sub ON s.subject_id = sub.id
+		JOIN teachers t ON s.teacher_id = t.id
+		WHERE m.classroom_id = $1 AND ($2::int IS NULL OR s.term_id = $2)
+		ORDER BY m.day, m.start_time, s.id
+	`
+
+	rows, err := h.db.Query(r.Context(), query, id, termID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch classroom occupancy")
+
+		return
+	}
+	defer rows.Close()
+
+	var bookings []schema.Booking
+
+	for rows.Next() {
+		var booking schema.Booking
+
+		err := rows.Scan(
+			&booking.Day, &booking.StartTime, &booking.EndTime,
+			&booking.SubjectCode, &booking.SectionCode,
+			&booking.TeacherFirstName, &booking.TeacherLastName,
+			&booking.TermID, &booking.SectionID,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan booking")
+
+			return
+		}
+
+		bookings = append(bookings, booking)
+	}
+
+	utils.SendJSON(w, http.StatusOK, bookings)
+}
+
+// UpdateClassroom handles HTTP PUT requests to replace a classroom record.
+// All fields are required, as with CreateClassroom.
+func (h *Handlers) UpdateClassroom(w http.ResponseWriter, r *http.Request) {
+	h.updateClassroom(w, r, false)
+}
+
+// PatchClassroom handles HTTP PATCH requests to partially update a classroom record.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchClassroom(w http.ResponseWriter, r *http.Request) {
+	h.updateClassroom(w, r, true)
+}
+
+// DeleteClassroom handles HTTP DELETE requests to remove a classroom record.
+// Refuses with a conflict while sections still reference the classroom unless ?cascade=true is given,
+// in which case the sections held in the classroom, the components linked to them and their enrollments are deleted as well.
+func (h *Handlers) DeleteClassroom(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Classroom",
+		query:  `DELETE FROM classrooms WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE section_id IN (
+				SELECT id FROM sections
+				WHERE id IN (SELECT section_id FROM section_meetings WHERE classroom_id = $1)
+				   OR parent_section_id IN (SELECT section_id FROM section_meetings WHERE classroom_id = $1)
+			)`,
+			`DELETE FROM sections WHERE parent_section_id IN (SELECT section_id FROM section_meetings WHERE classroom_id = $1)`,
+			`DELETE FROM sections WHERE id IN (SELECT section_id FROM section_meetings WHERE classroom_id = $1)`,
+		},
+		dependents: "sections",
+	})
+}
+
+// updateClassroom applies a full (PUT) or partial (PATCH) update to a classroom record.
+// For partial updates the request body is decoded on top of the stored record.
+func (h *Handlers) updateClassroom(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid classroom ID")
+
+		return
+	}
+
+	var classroom schema.Classroom
+
+	if partial {
+		classroom, err = h.fetchClassroom(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Classroom not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch classroom")
+
+			return
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&classroom); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateClassroom(classroom); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	query := `
+		UPDATE classrooms
+		SET building = $2, room_number = $3, capacity = $4
+		WHERE id = $1
+		RETURNING id, building, room_number, capacity, created_at, updated_at
+	`
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		id,
+		classroom.Building,
+		classroom.RoomNumber,
+		classroom.Capacity,
+	).Scan(
+		&classroom.ID, &classroom.Building, &classroom.RoomNumber,
+		&classroom.Capacity, &classroom.CreatedAt, &classroom.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Classroom not found")
+
+			return
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch {
+			case pgErr.Code == "23505": // Unique violation
+				utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A classroom with this building and room number already exists")
+
+				return
+			case pgErr.Code == "23514" && pgErr.ConstraintName == "classrooms_capacity_hosted_sections":
+				h.sendCapacityConflict(w, r, id, classroom.Capacity)
+
+				return
+			}
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update classroom: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, classroom)
+}
+
+// sendCapacityConflict responds with 409 Conflict listing the sections held in the classroom
+// whose max enrollment exceeds the requested capacity.
+func (h *Handlers) sendCapacityConflict(w http.ResponseWriter, r *http.Request, classroomID, capacity int) {
+	message := fmt.Sprintf("Classroom capacity %d is below the max enrollment of sections held in it; "+
+		"lower their max enrollment first", capacity)
+
+	query := `
+		SELECT` + sectionColumns + `
+		FROM sections s
+		WHERE s.max_enrollment > $2
+		  AND EXISTS (SELECT 1 FROM section_meetings m WHERE m.section_id = s.id AND m.classroom_id = $1)
+		ORDER BY s.id
+	`
+
+	rows, err := h.db.Query(r.Context(), query, classroomID, capacity)
+	if err != nil {
+		utils.SendErrorCode(w, http.StatusConflict, "classroom_capacity_below_sections", message)
+
+		return
+	}
+	defer rows.Close()
+
+	var sections []schema.Section
+
+	for rows.Next() {
+		section, err := scanSection(rows)
+		if err != nil {
+			utils.SendErrorCode(w, http.StatusConflict, "classroom_capacity_below_sections", message)
+
+			return
+		}
+
+		sections = append(sections, section)
+	}
+
+	utils.SendProblem(w, CapacityConflictResponse{
+		Problem:  utils.NewProblem(http.StatusConflict, "classroom_capacity_below_sections", message),
+		Sections: sections,
+	})
+}
+
+// fetchClassroom loads a single classroom record by ID.
+func (h *Handlers) fetchClassroom(ctx context.Context, id int) (schema.Classroom, error) {
+	var classroom schema.Classroom
+
+	query := `
+		SELECT id, building, room_number, capacity, created_at, updated_at
+		FROM classrooms
+		WHERE id = $1
+	`
+
+	err := h.db.QueryRow(ctx, query, id).Scan(
+		&classroom.ID, &classroom.Building, &classroom.RoomNumber,
+		&classroom.Capacity, &classroom.CreatedAt, &classroom.UpdatedAt,
+	)
+
+	return classroom, err
+}
+
+// validateClassroom checks the fields required for creating or replacing a classroom.
+// Returns the invalid fields, or nil when the classroom is valid.
+func validateClassroom(classroom schema.Classroom) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(classroom.Building == "", "building", "Building is required")
+	errs.Required(classroom.RoomNumber == "", "room_number", "Room number is required")
+
+	switch {
+	case classroom.Capacity == 0:
+		errs.Add("capacity", "required", "Capacity is required")
+	case classroom.Capacity < 0:
+		errs.Add("capacity", "invalid", "Capacity must be positive")
+	}
+
+	return errs
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// GetCompletedCourses handles HTTP GET requests to retrieve the courses a student has completed.
+// Returns the completed course records ordered by subject code.
+func (h *Handlers) GetCompletedCourses(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	query := `
+		SELECT
+			cc.id, cc.student_id, cc.subject_id, s.code, s.name,
+			cc.term_id, cc.grade, cc.created_at
+		FROM completed_courses cc
+		JOIN subjects s ON s.id = cc.subject_id
+		WHERE cc.student_id = $1
+		ORDER BY s.code
+	`
+
+	rows, err := h.db.Query(r.Context(), query, studentID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch completed courses")
+
+		return
+	}
+	defer rows.Close()
+
+	var courses []schema.CompletedCourse
+
+	for rows.Next() {
+		var course schema.CompletedCourse
+
+		err := rows.Scan(
+			&course.ID, &course.StudentID, &course.SubjectID, &course.SubjectCode, &course.SubjectName,
+			&course.TermID, &course.Grade, &course.CreatedAt,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan completed course")
+
+			return
+		}
+
+		courses = append(courses, course)
+	}
+
+	utils.SendJSON(w, http.StatusOK, courses)
+}
+
+// AddCompletedCourse handles HTTP POST requests to record a completed course for a student.
+// Completed courses satisfy prerequisites and corequisites when the student enrolls.
+// Returns the created record.
+func (h *Handlers) AddCompletedCourse(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	var courseReq schema.CompletedCourseRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&courseReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if courseReq.SubjectID <= 0 {
+		utils.SendError(w, http.StatusBadRequest, "Subject ID is required")
+
+		return
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO completed_courses (student_id, subject_id, term_id, grade)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, student_id, subject_id, term_id, grade, created_at
+		)
+		SELECT i.id, i.student_id, i.subject_id, s.code, s.name, i.term_id, i.grade, i.created_at
+		FROM inserted i
+		JOIN subjects s ON s.id = i.subject_id
+	`
+
+	var course schema.CompletedCourse
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		studentID,
+		courseReq.SubjectID,
+		courseReq.TermID,
+		courseReq.Grade,
+	).Scan(
+		&course.ID, &course.StudentID, &course.SubjectID, &course.SubjectCode, &course.SubjectName,
+		&course.TermID, &course.Grade, &course.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // Unique violation
+				utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "Course is already recorded as completed")
+
+				return
+			case "23503": // Foreign key violation
+				utils.SendErrorCode(w, http.StatusNotFound, "reference_not_found", "Student, subject or term not found")
+
+				return
+			}
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to record completed course: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, course)
+}
+
+// DeleteCompletedCourse handles HTTP DELETE requests to remove a completed course from a student's record.
+// Accepts student ID and subject ID path parameters and returns a success message or a not found error.
+func (h *Handlers) DeleteCompletedCourse(w http.ResponseWriter, r *http.Request) {
+	studentIDStr := r.PathValue("id")
+	subjectIDStr := r.PathValue("subject_id")
+
+	studentID, err := strconv.Atoi(studentIDStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	subjectID, err := strconv.Atoi(subjectIDStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid subject ID")
+
+		return
+	}
+
+	query := `
+		DELETE FROM completed_courses
+		WHERE student_id = $1 AND subject_id = $2
+	`
+
+	result, err := h.db.Exec(r.Context(), query, studentID, subjectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to delete completed course")
+
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		utils.SendError(w, http.StatusNotFound, "Completed course not found")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Completed course deleted successfully"})
+}
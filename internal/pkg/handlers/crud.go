@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/utils"
+)
+
+// deletion describes how a single record is removed by deleteRecord.
+type deletion struct {
+	// entity is the human readable resource name used in messages, e.g. "Teacher".
+	entity string
+	// query deletes the record itself; $1 is the record ID.
+	query string
+	// cascade lists statements that remove dependent rows before the record itself
+	// when the client explicitly asks for ?cascade=true; $1 is the record ID.
+	cascade []string
+	// dependents describes what still references the record, e.g. "sections".
+	dependents string
+}
+
+// deleteRecord handles HTTP DELETE requests for a single record identified by the id path parameter.
+// Without ?cascade=true a foreign key violation is reported as 409 Conflict,
+// with it the dependent rows are removed first within the same transaction.
+func (h *Handlers) deleteRecord(w http.ResponseWriter, r *http.Request, d deletion) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s ID", strings.ToLower(d.entity)))
+
+		return
+	}
+
+	cascade := false
+
+	if value := r.URL.Query().Get("cascade"); value != "" {
+		cascade, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "Cascade must be true or false")
+
+			return
+		}
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	if cascade {
+		for _, query := range d.cascade {
+			if _, err := tx.Exec(r.Context(), query, id); err != nil {
+				utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete dependent %s: %v", d.dependents, err))
+
+				return
+			}
+		}
+	}
+
+	result, err := tx.Exec(r.Context(), d.query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // Foreign key violation
+			utils.SendErrorCode(w, http.StatusConflict, "has_dependents", fmt.Sprintf(
+				"%s is still referenced by %s; use ?cascade=true to delete them as well", d.entity, d.dependents,
+			))
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete %s: %v", strings.ToLower(d.entity), err))
+
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		utils.SendError(w, http.StatusNotFound, d.entity+" not found")
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": d.entity + " deleted successfully"})
+}
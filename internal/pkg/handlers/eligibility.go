@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// maxEligibilitySections caps the number of sections checked by one eligibility request.
+const maxEligibilitySections = 100
+
+// eligibilityMessages maps the reason codes of find_enrollment_blockers to the messages EnrollStudent responds with.
+var eligibilityMessages = map[string]string{
+	"section_not_found":    "Section not found",
+	"registration_closed":  "Registration is closed for this term",
+	"requirements_not_met": "Enrollment requirements are not met",
+	"already_enrolled":     "Student is already enrolled in this section",
+	"schedule_conflict":    "Schedule conflict detected",
+	"section_full":         "Section is full",
+	"missing_components":   "Sections do not form a valid combination of a lecture and its components",
+	"duplicate_components": "Sections do not form a valid combination of a lecture and its components",
+}
+
+// GetEnrollmentEligibility handles HTTP GET requests to check whether a student could enroll in sections
+// without enrolling them. Runs the same checks as the enrollment triggers (registration window, requirements,
+// schedule conflicts, capacity, lecture and component combination) and reports every failing reason rather than only the first.
+// With ?section_id=N a single result is returned; with ?section_ids=N,M,... a list of results in request order.
+func (h *Handlers) GetEnrollmentEligibility(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	sectionID, err := optionalIntQuery(r, "section_id")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	sectionIDs, err := intListQuery(r, "section_ids")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section IDs")
+
+		return
+	}
+
+	if (sectionID == nil) == (len(sectionIDs) == 0) {
+		utils.SendError(w, http.StatusBadRequest, "Either section_id or section_ids is required")
+
+		return
+	}
+
+	if len(sectionIDs) > maxEligibilitySections {
+		utils.SendError(w, http.StatusBadRequest, "Too many section IDs")
+
+		return
+	}
+
+	if _, err := h.fetchStudent(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Student not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch student info")
+
+		return
+	}
+
+	if sectionID != nil {
+		eligibility, err := h.checkEligibility(r.Context(), id, *sectionID)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to check eligibility")
+
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, eligibility)
+
+		return
+	}
+
+	results := make([]schema.Eligibility, 0, len(sectionIDs))
+
+	for _, sectionID := range sectionIDs {
+		eligibility, err := h.checkEligibility(r.Context(), id, sectionID)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to check eligibility")
+
+			return
+		}
+
+		results = append(results, eligibility)
+	}
+
+	utils.SendJSON(w, http.StatusOK, results)
+}
+
+// checkEligibility evaluates find_enrollment_blockers for a student and section,
+// adding the conflicting sections, unmet requirement groups and incomplete component types when those are among the reasons.
+func (h *Handlers) checkEligibility(ctx context.Context, studentID, sectionID int) (schema.Eligibility, error) {
+	eligibility := schema.Eligibility{
+		Reasons:   []schema.EligibilityReason{},
+		SectionID: sectionID,
+	}
+
+	rows, err := h.db.Query(ctx, `SELECT find_enrollment_blockers($1, $2)`, studentID, sectionID)
+	if err != nil {
+		return eligibility, err
+	}
+
+	codes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return eligibility, err
+	}
+
+	for _, code := range codes {
+		eligibility.Reasons = append(eligibility.Reasons, schema.EligibilityReason{
+			Code:    code,
+			Message: eligibilityMessages[code],
+		})
+
+		switch code {
+		case "schedule_conflict":
+			eligibility.Conflicts, err = h.findEnrollmentConflicts(ctx, studentID, sectionID, sectionID)
+		case "requirements_not_met":
+			eligibility.Unmet, err = h.fetchUnmetRequirements(ctx, studentID, sectionID)
+		case "missing_components":
+			eligibility.MissingComponentTypes, err = h.fetchCombinationProblems(ctx, studentID, sectionID, "missing")
+		case "duplicate_components":
+			eligibility.DuplicateComponentTypes, err = h.fetchCombinationProblems(ctx, studentID, sectionID, "duplicate")
+		}
+
+		if err != nil {
+			return eligibility, err
+		}
+	}
+
+	eligibility.Eligible = len(eligibility.Reasons) == 0
+
+	return eligibility, nil
+}
+
+// fetchCombinationProblems lists the component types, or "lecture", that enrolling the student in the section alone
+// would leave with the given find_combination_problems problem, "missing" or "duplicate".
+func (h *Handlers) fetchCombinationProblems(ctx context.Context, studentID, sectionID int, problem string) ([]string, error) {
+	query := `
+		SELECT p.component_type
+		FROM sections s
+		CROSS JOIN find_combination_problems($1, COALESCE(s.parent_section_id, s.id), s.id) p
+		WHERE s.id = $2 AND p.problem = $3
+		ORDER BY p.component_type
+	`
+
+	rows, err := h.db.Query(ctx, query, studentID, sectionID, problem)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -1,21 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"slices"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
 	"code.local/internal/pkg/schema"
 	"code.local/internal/pkg/utils"
 )
 
+// maxBatchEnrollments caps the number of items in a batch enrollment request.
+const maxBatchEnrollments = 100
+
 // EnrollStudent handles HTTP POST requests to enroll a student in a course section.
-// Validates the enrollment request, checks for conflicts and capacity via database triggers,
+// Validates the enrollment request, checks for conflicts, capacity and course requirements via database triggers,
 // creates the enrollment record, and returns the enrollment details with ID and timestamp.
+// Schedule conflicts are reported with the conflicting sections, unmet requirements with the unmet groups.
+// A lecture with linked components is enrolled together with the components listed in component_section_ids
+// within one transaction: every section is checked, all failing sections are reported and nothing is stored
+// unless the lecture and exactly one component of each type can be taken together.
 func (h *Handlers) EnrollStudent(w http.ResponseWriter, r *http.Request) {
 	var enrollment EnrollmentRequest
 
@@ -25,46 +34,482 @@ func (h *Handlers) EnrollStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		INSERT INTO enrollments (student_id, section_id)
-		VALUES ($1, $2)
-		RETURNING id, enrollment_date
-	`
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	if len(enrollment.ComponentSectionIDs) == 0 {
+		result, err := insertEnrollment(r.Context(), tx, enrollment.StudentID, enrollment.SectionID)
+		if err == nil {
+			err = checkSectionCombinations(r.Context(), tx)
+		}
+
+		if err != nil {
+			// Lookups of the failure details run outside the aborted transaction
+			tx.Rollback(r.Context())
+			h.sendEnrollmentFailure(w, r, err, enrollment.StudentID, enrollment.SectionID, 0)
+
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+			return
+		}
+
+		utils.SendJSON(w, http.StatusCreated, result)
+
+		return
+	}
+
+	sectionIDs := append([]int{enrollment.SectionID}, enrollment.ComponentSectionIDs...)
+	enrollments := make([]schema.Enrollment, 0, len(sectionIDs))
+
+	var failures []EnrollmentFailure
+
+	for _, sectionID := range sectionIDs {
+		// Each section runs in a savepoint so that every failing section is reported, not just the first
+		savepoint, err := tx.Begin(r.Context())
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to begin savepoint")
+
+			return
+		}
+
+		result, err := insertEnrollment(r.Context(), savepoint, enrollment.StudentID, sectionID)
+		if err != nil {
+			if err := savepoint.Rollback(r.Context()); err != nil {
+				utils.SendError(w, http.StatusInternalServerError, "Failed to roll back savepoint")
+
+				return
+			}
+
+			problem := enrollmentFailure(err)
+			failures = append(failures, EnrollmentFailure{
+				Code: problem.Code, Error: problem.Detail, SectionID: sectionID, Status: problem.Status,
+			})
+
+			continue
+		}
+
+		if err := savepoint.Commit(r.Context()); err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to release savepoint")
+
+			return
+		}
+
+		enrollments = append(enrollments, result)
+	}
+
+	if len(failures) > 0 {
+		problem := utils.NewProblem(failures[0].Status, failures[0].Code,
+			fmt.Sprintf("Could not enroll in %d of the %d sections; nothing was stored", len(failures), len(sectionIDs)))
+
+		utils.SendProblem(w, CombinationFailureResponse{Problem: problem, Failures: failures})
+
+		return
+	}
+
+	if err := checkSectionCombinations(r.Context(), tx); err != nil {
+		tx.Rollback(r.Context())
+		h.sendEnrollmentFailure(w, r, err, enrollment.StudentID, enrollment.SectionID, 0)
+
+		return
+	}
 
-	var (
-		id             int
-		enrollmentDate time.Time
-	)
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
 
-	err := h.db.QueryRow(r.Context(), query, enrollment.StudentID, enrollment.SectionID).
-		Scan(&id, &enrollmentDate)
+		return
+	}
+
+	result := enrollments[0]
+	result.Components = enrollments[1:]
+
+	utils.SendJSON(w, http.StatusCreated, result)
+}
+
+// EnrollStudentsBatch handles HTTP POST requests to create several enrollments at once.
+// With ?mode=atomic (the default) either every enrollment is created or none; with ?mode=best_effort
+// each item succeeds or fails on its own. Items are enrolled in order within one transaction,
+// so the schedule conflict check also sees the sections enrolled earlier in the same batch.
+// In best effort mode the items of a student whose lectures and components would not form a valid combination
+// fail with invalid_section_combination, and the batch is replayed without them.
+// Every result carries the status EnrollStudent would have returned for the item.
+func (h *Handlers) EnrollStudentsBatch(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "atomic"
+	}
+
+	if mode != "atomic" && mode != "best_effort" {
+		utils.SendError(w, http.StatusBadRequest, "Mode must be atomic or best_effort")
+
+		return
+	}
+
+	var batch BatchEnrollmentRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if len(batch.Enrollments) == 0 || len(batch.Enrollments) > maxBatchEnrollments {
+		utils.SendValidationError(w, utils.FieldErrors{{
+			Field:  "enrollments",
+			Code:   "invalid",
+			Detail: fmt.Sprintf("A batch must hold between 1 and %d enrollments", maxBatchEnrollments),
+		}})
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
 	if err != nil {
-		var pgErr *pgconn.PgError
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
 
-		if errors.As(err, &pgErr) {
-			switch pgErr.Message {
-			case "Schedule conflict detected. Cannot enroll in this section.":
-				utils.SendError(w, http.StatusConflict, "Schedule conflict detected")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	studentIDs := make([]int, 0, len(batch.Enrollments))
+	for _, item := range batch.Enrollments {
+		studentIDs = append(studentIDs, item.StudentID)
+	}
+
+	if err := lockStudentSchedules(r.Context(), tx, studentIDs); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to lock student schedules")
+
+		return
+	}
+
+	response := BatchEnrollmentResponse{Mode: mode}
+
+	// Items of students whose sections would not form a valid combination, with the results they get instead
+	skipped := make(map[int]BatchEnrollmentResult)
+
+	for {
+		// Each attempt runs in a savepoint, so a best effort batch can be replayed without some students' items
+		attempt, err := tx.Begin(r.Context())
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to begin savepoint")
+
+			return
+		}
+
+		response.Results, response.Enrolled, err = enrollBatchItems(r.Context(), attempt, batch.Enrollments, skipped)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to enroll batch items")
+
+			return
+		}
+
+		var invalid []int
+
+		if mode == "best_effort" {
+			// A lecture and its components may be split over several items of a student, so combinations are
+			// checked per student once all items ran; only the items of students left incomplete are rolled back
+			invalid, err = findInvalidCombinations(r.Context(), attempt, response.Results)
+			if err != nil {
+				utils.SendError(w, http.StatusInternalServerError, "Failed to check section combinations")
 
 				return
-			case "Section is full. Cannot enroll.":
-				utils.SendError(w, http.StatusConflict, "Section is full")
+			}
+		}
+
+		if len(invalid) == 0 {
+			if err := attempt.Commit(r.Context()); err != nil {
+				utils.SendError(w, http.StatusInternalServerError, "Failed to release savepoint")
 
 				return
 			}
+
+			break
+		}
+
+		if err := attempt.Rollback(r.Context()); err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to roll back savepoint")
+
+			return
+		}
+
+		for i, item := range batch.Enrollments {
+			if !slices.Contains(invalid, item.StudentID) {
+				continue
+			}
+
+			result := response.Results[i]
+			if result.Status == http.StatusCreated {
+				result = BatchEnrollmentResult{
+					Index:  i,
+					Status: http.StatusUnprocessableEntity,
+					Code:   "invalid_section_combination",
+					Error:  "Not enrolled because the student's sections do not form a valid combination of a lecture and its components",
+				}
+			}
+
+			skipped[i] = result
 		}
+	}
 
-		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to enroll student: %v", err))
+	failed := slices.ContainsFunc(response.Results, func(result BatchEnrollmentResult) bool {
+		return result.Status != http.StatusCreated
+	})
+
+	var problem *utils.Problem
+
+	if failed && mode == "atomic" {
+		batchFailed := utils.NewProblem(http.StatusUnprocessableEntity, "batch_failed", "No enrollment was stored because an item of the batch failed")
+		problem = &batchFailed
+	} else if err := checkSectionCombinations(r.Context(), tx); err != nil {
+		// Atomic batches may split a lecture and its components over several items, so combinations are checked
+		// for the whole batch; best effort batches have left out the students with invalid combinations already
+		invalid := enrollmentFailure(err)
+		invalid.Detail = "No enrollment was stored: " + invalid.Detail
+		problem = &invalid
+	}
+
+	if problem != nil {
+		for i := range response.Results {
+			if response.Results[i].Status == http.StatusCreated {
+				response.Results[i].Status = http.StatusFailedDependency
+				response.Results[i].Code = "rolled_back"
+				response.Results[i].Error = "Not enrolled because the batch was rolled back"
+				response.Results[i].Enrollment = nil
+			}
+		}
+
+		response.Enrolled = 0
+		response.Problem = problem
+
+		utils.SendProblem(w, response)
 
 		return
 	}
 
-	result := schema.Enrollment{
-		ID:             id,
-		StudentID:      enrollment.StudentID,
-		SectionID:      enrollment.SectionID,
-		EnrollmentDate: enrollmentDate,
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
 	}
 
-	utils.SendJSON(w, http.StatusCreated, result)
+	if failed {
+		utils.SendJSON(w, http.StatusMultiStatus, response)
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, response)
+}
+
+// enrollBatchItems enrolls the items of a batch in order within tx, each in a savepoint of its own so a failed
+// item does not abort the rest of the batch. Items found in skipped are not enrolled and get the result stored there.
+// Returns the result of every item and the number of items enrolled.
+func enrollBatchItems(
+	ctx context.Context, tx pgx.Tx, items []EnrollmentRequest, skipped map[int]BatchEnrollmentResult,
+) ([]BatchEnrollmentResult, int, error) {
+	results := make([]BatchEnrollmentResult, 0, len(items))
+	enrolled := 0
+
+	for i, item := range items {
+		if result, ok := skipped[i]; ok {
+			results = append(results, result)
+
+			continue
+		}
+
+		result := BatchEnrollmentResult{Index: i}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to begin savepoint: %w", err)
+		}
+
+		enrollment, err := insertEnrollment(ctx, savepoint, item.StudentID, item.SectionID)
+
+		for _, componentID := range item.ComponentSectionIDs {
+			if err != nil {
+				break
+			}
+
+			var component schema.Enrollment
+
+			component, err = insertEnrollment(ctx, savepoint, item.StudentID, componentID)
+			enrollment.Components = append(enrollment.Components, component)
+		}
+
+		if err != nil {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, 0, fmt.Errorf("failed to roll back savepoint: %w", err)
+			}
+
+			problem := enrollmentFailure(err)
+			result.Status, result.Code, result.Error = problem.Status, problem.Code, problem.Detail
+		} else {
+			if err := savepoint.Commit(ctx); err != nil {
+				return nil, 0, fmt.Errorf("failed to release savepoint: %w", err)
+			}
+
+			result.Status = http.StatusCreated
+			result.Enrollment = &enrollment
+			enrolled++
+		}
+
+		results = append(results, result)
+	}
+
+	return results, enrolled, nil
+}
+
+// findInvalidCombinations returns the students whose enrollments created by the batch results leave a lecture
+// and its components an invalid combination, as trg_enforce_section_combination would report at commit.
+func findInvalidCombinations(ctx context.Context, tx pgx.Tx, results []BatchEnrollmentResult) ([]int, error) {
+	var enrollmentIDs []int
+
+	for _, result := range results {
+		if result.Enrollment == nil {
+			continue
+		}
+
+		enrollmentIDs = append(enrollmentIDs, result.Enrollment.ID)
+		for _, component := range result.Enrollment.Components {
+			enrollmentIDs = append(enrollmentIDs, component.ID)
+		}
+	}
+
+	if len(enrollmentIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT e.student_id
+		FROM enrollments e
+		JOIN sections s ON s.id = e.section_id
+		WHERE e.id = ANY($1)
+		  AND EXISTS (SELECT 1 FROM find_combination_problems(e.student_id, COALESCE(s.parent_section_id, s.id)))
+		ORDER BY e.student_id
+	`, enrollmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// insertEnrollment enrolls a student in a section within tx.
+// The enrollment triggers check registration, requirements, schedule conflicts and capacity.
+func insertEnrollment(ctx context.Context, tx pgx.Tx, studentID, sectionID int) (schema.Enrollment, error) {
+	enrollment := schema.Enrollment{
+		StudentID: studentID,
+		SectionID: sectionID,
+	}
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO enrollments (student_id, section_id)
+		VALUES ($1, $2)
+		RETURNING id, enrollment_date
+	`, studentID, sectionID).Scan(&enrollment.ID, &enrollment.EnrollmentDate)
+
+	return enrollment, err
+}
+
+// checkSectionCombinations runs the lecture and component combination checks of tx, which are otherwise
+// deferred until commit, so that an invalid combination is reported as an error of its own.
+func checkSectionCombinations(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SET CONSTRAINTS trg_enforce_section_combination IMMEDIATE`)
+
+	return err
+}
+
+// lockStudentSchedules takes the schedule locks of the students within tx, which the enrollment trigger
+// would otherwise take one by one. Locking them upfront in ascending ID order keeps concurrent batches
+// naming the same students in a different order from deadlocking.
+func lockStudentSchedules(ctx context.Context, tx pgx.Tx, studentIDs []int) error {
+	ids := slices.Clone(studentIDs)
+	slices.Sort(ids)
+
+	_, err := tx.Exec(ctx, `
+		SELECT lock_student_schedule(id)
+		FROM unnest($1::int[]) WITH ORDINALITY AS t(id, n)
+		ORDER BY n
+	`, slices.Compact(ids))
+
+	return err
+}
+
+// enrollmentFailure maps an error raised while inserting an enrollment to a problem with a stable code.
+// Unmet requirements and invalid lecture and component combinations map to 422 Unprocessable Entity;
+// callers may respond with the unmet groups or the combination details instead.
+func enrollmentFailure(err error) utils.Problem {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case sqlStateScheduleConflict:
+			return utils.NewProblem(http.StatusConflict, "schedule_conflict", "Schedule conflict detected")
+		case sqlStateSectionFull:
+			return utils.NewProblem(http.StatusConflict, "section_full", "Section is full")
+		case sqlStateRegistrationClosed:
+			return utils.NewProblem(http.StatusConflict, "registration_closed", "Registration is closed for this term")
+		case sqlStateRequirementsNotMet:
+			return utils.NewProblem(http.StatusUnprocessableEntity, "requirements_not_met", "Enrollment requirements are not met")
+		case sqlStateInvalidCombination:
+			return utils.NewProblem(http.StatusUnprocessableEntity, "invalid_section_combination",
+				"Sections do not form a valid combination of a lecture and its components")
+		case "23505": // Unique violation
+			return utils.NewProblem(http.StatusConflict, "already_enrolled", "Student is already enrolled in this section")
+		case "23503": // Foreign key violation
+			return utils.NewProblem(http.StatusNotFound, "reference_not_found", "Student or section not found")
+		}
+	}
+
+	return utils.NewProblem(http.StatusInternalServerError, "internal_error", fmt.Sprintf("Failed to enroll student: %v", err))
+}
+
+// sendEnrollmentFailure responds with the problem enrollmentFailure maps err to, listing the conflicting sections
+// of schedule conflicts, the unmet groups of unmet requirements and the missing or duplicate component types
+// of invalid combinations. The lookups run outside any transaction; excludeSectionID is left out of the
+// conflict comparison (0 excludes none).
+func (h *Handlers) sendEnrollmentFailure(
+	w http.ResponseWriter, r *http.Request, err error, studentID, sectionID, excludeSectionID int,
+) {
+	switch problem := enrollmentFailure(err); problem.Code {
+	case "schedule_conflict":
+		h.sendEnrollmentConflict(w, r, studentID, sectionID, excludeSectionID)
+	case "requirements_not_met":
+		h.sendUnmetRequirements(w, r, studentID, sectionID)
+	case "invalid_section_combination":
+		sendInvalidCombination(w, err, problem)
+	default:
+		utils.SendProblem(w, problem)
+	}
+}
+
+// sendInvalidCombination responds with 422 Unprocessable Entity naming the lecture and the component types
+// the student would take none or more than one of, as reported in the DETAIL of the SC008 error.
+func sendInvalidCombination(w http.ResponseWriter, err error, problem utils.Problem) {
+	response := InvalidCombinationResponse{Problem: problem}
+
+	var detail struct {
+		Missing   []string `json:"missing_component_types"`
+		Duplicate []string `json:"duplicate_component_types"`
+		SectionID int      `json:"section_id"`
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && json.Unmarshal([]byte(pgErr.Detail), &detail) == nil {
+		response.MissingComponentTypes = detail.Missing
+		response.DuplicateComponentTypes = detail.Duplicate
+		response.LectureSectionID = detail.SectionID
+	}
+
+	utils.SendProblem(w, response)
 }
@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// maxImportBytes limits the size of an import request body.
+const maxImportBytes = 32 << 20
+
+// copyLinePattern extracts the failing row from the context of a COPY error.
+var copyLinePattern = regexp.MustCompile(`COPY \w+, line (\d+)`)
+
+// importer describes how rows of one entity are validated and loaded.
+type importer struct {
+	// table is the destination table.
+	table string
+	// columns are the accepted CSV header fields / JSON keys, which double as table columns.
+	columns []string
+	// unique lists column sets that must be unique, checked within the file and against the table.
+	unique [][]string
+	// row converts a record into column values, validating it with the same rules as the create handler.
+	// Returns the invalid fields, or nil when the record is valid.
+	row func(record map[string]string) ([]any, utils.FieldErrors)
+}
+
+// importers lists the entities accepted by ImportRecords.
+var importers = map[string]importer{
+	"students": {
+		table:   "students",
+		columns: []string{"student_id", "first_name", "last_name", "email"},
+		unique:  [][]string{{"student_id"}, {"email"}},
+		row: func(record map[string]string) ([]any, utils.FieldErrors) {
+			student := schema.CreateStudentRequest{
+				StudentID: record["student_id"],
+				FirstName: record["first_name"],
+				LastName:  record["last_name"],
+				Email:     record["email"],
+			}
+
+			if errs := validateStudent(student); len(errs) > 0 {
+				return nil, errs
+			}
+
+			return []any{student.StudentID, student.FirstName, student.LastName, student.Email}, nil
+		},
+	},
+	"teachers": {
+		table:   "teachers",
+		columns: []string{"first_name", "last_name", "email"},
+		unique:  [][]string{{"email"}},
+		row: func(record map[string]string) ([]any, utils.FieldErrors) {
+			teacher := schema.Teacher{
+				FirstName: record["first_name"],
+				LastName:  record["last_name"],
+				Email:     record["email"],
+			}
+
+			if errs := validateTeacher(teacher); len(errs) > 0 {
+				return nil, errs
+			}
+
+			return []any{teacher.FirstName, teacher.LastName, teacher.Email}, nil
+		},
+	},
+	"subjects": {
+		table:   "subjects",
+		columns: []string{"code", "name", "description"},
+		row: func(record map[string]string) ([]any, utils.FieldErrors) {
+			subject := schema.Subject{
+				Code:        record["code"],
+				Name:        record["name"],
+				Description: record["description"],
+			}
+
+			if errs := validateSubject(subject); len(errs) > 0 {
+				return nil, errs
+			}
+
+			return []any{subject.Code, subject.Name, subject.Description}, nil
+		},
+	},
+	"classrooms": {
+		table:   "classrooms",
+		columns: []string{"building", "room_number", "capacity"},
+		unique:  [][]string{{"building", "room_number"}},
+		row: func(record map[string]string) ([]any, utils.FieldErrors) {
+			classroom := schema.Classroom{
+				Building:   record["building"],
+				RoomNumber: record["room_number"],
+			}
+
+			if value := record["capacity"]; value != "" {
+				capacity, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, utils.FieldErrors{{Field: "capacity", Code: "invalid", Detail: "Capacity must be a whole number"}}
+				}
+
+				classroom.Capacity = capacity
+			}
+
+			if errs := validateClassroom(classroom); len(errs) > 0 {
+				return nil, errs
+			}
+
+			return []any{classroom.Building, classroom.RoomNumber, classroom.Capacity}, nil
+		},
+	},
+}
+
+// importRecord is a single decoded row together with its line in the uploaded file.
+type importRecord struct {
+	fields map[string]string
+	line   int
+}
+
+// ImportRecords handles HTTP POST requests to bulk import students, teachers, subjects or classrooms.
+// Accepts CSV with a header row (text/csv) or JSON lines (application/x-ndjson), selectable with ?format=csv|jsonl.
+// Every row is validated like the matching create handler and checked for duplicates;
+// when all rows are valid they are loaded with COPY in a single transaction.
+// With ?dry_run=true the load is rolled back after validation.
+// Returns a report listing the errors of every rejected row; nothing is imported if any row fails.
+func (h *Handlers) ImportRecords(w http.ResponseWriter, r *http.Request) {
+	entity := r.PathValue("entity")
+
+	imp, ok := importers[entity]
+	if !ok {
+		utils.SendError(w, http.StatusNotFound, "Unknown import entity; use students, teachers, subjects or classrooms")
+
+		return
+	}
+
+	dryRun := false
+
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "Dry run must be true or false")
+
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var (
+		records []importRecord
+		err     error
+	)
+
+	switch importFormat(r) {
+	case "csv":
+		records, err = readCSV(body, imp.columns)
+	case "jsonl":
+		records, err = readJSONLines(body, imp.columns)
+	default:
+		utils.SendError(w, http.StatusUnsupportedMediaType, "Import format must be csv or jsonl")
+
+		return
+	}
+
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid import file: %v", err))
+
+		return
+	}
+
+	report := ImportResponse{
+		Entity:    entity,
+		TotalRows: len(records),
+		DryRun:    dryRun,
+		Errors:    []ImportError{},
+	}
+
+	rows := make([][]any, 0, len(records))
+
+	for _, record := range records {
+		values, errs := imp.row(record.fields)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				report.Errors = append(report.Errors, ImportError{
+					Field:  err.Field,
+					Code:   err.Code,
+					Detail: err.Detail,
+					Line:   record.line,
+				})
+			}
+
+			continue
+		}
+
+		rows = append(rows, values)
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	duplicates, err := findDuplicates(r, tx, imp, records)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to check for duplicates: %v", err))
+
+		return
+	}
+
+	report.Errors = append(report.Errors, duplicates...)
+
+	if len(report.Errors) > 0 {
+		slices.SortStableFunc(report.Errors, func(a, b ImportError) int {
+			return a.Line - b.Line
+		})
+
+		sendImportRejected(w, report)
+
+		return
+	}
+
+	copied, err := tx.CopyFrom(r.Context(), pgx.Identifier{imp.table}, imp.columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			line := 0
+
+			if match := copyLinePattern.FindStringSubmatch(pgErr.Where); match != nil {
+				if n, err := strconv.Atoi(match[1]); err == nil && n >= 1 && n <= len(records) {
+					line = records[n-1].line
+				}
+			}
+
+			report.Errors = append(report.Errors, ImportError{
+				Field:  pgErr.ColumnName,
+				Code:   "constraint_violation",
+				Detail: pgErr.Message,
+				Line:   line,
+			})
+			sendImportRejected(w, report)
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to import %s: %v", entity, err))
+
+		return
+	}
+
+	report.Imported = int(copied)
+
+	if dryRun {
+		utils.SendJSON(w, http.StatusOK, report)
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, report)
+}
+
+// sendImportRejected responds with a 422 Unprocessable Entity problem carrying the import report.
+func sendImportRejected(w http.ResponseWriter, report ImportResponse) {
+	problem := utils.NewProblem(http.StatusUnprocessableEntity, "import_rejected",
+		fmt.Sprintf("Found %d error(s) in the import file; nothing was imported", len(report.Errors)))
+	report.Problem = &problem
+
+	utils.SendProblem(w, report)
+}
+
+// importFormat picks the import format from the format query parameter or the request content type.
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl", "application/jsonlines", "application/x-jsonlines":
+		return "jsonl"
+	case "", "text/csv", "text/plain":
+		return "csv"
+	}
+
+	return mediaType
+}
+
+// readCSV decodes a CSV file whose header row names the columns.
+func readCSV(body io.Reader, columns []string) ([]importRecord, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header row")
+		}
+
+		return nil, err
+	}
+
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(columns, header[i]) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	var records []importRecord
+
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		fields := make(map[string]string, len(header))
+		for i, name := range header {
+			fields[name] = strings.TrimSpace(values[i])
+		}
+
+		records = append(records, importRecord{fields: fields, line: line})
+	}
+
+	return records, nil
+}
+
+// readJSONLines decodes a file holding one JSON object per line; blank lines are skipped.
+func readJSONLines(body io.Reader, columns []string) ([]importRecord, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var records []importRecord
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var object map[string]any
+
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		fields := make(map[string]string, len(object))
+
+		for name, value := range object {
+			if !slices.Contains(columns, name) {
+				return nil, fmt.Errorf("line %d: unknown field %q", line, name)
+			}
+
+			if value != nil {
+				fields[name] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		records = append(records, importRecord{fields: fields, line: line})
+	}
+
+	return records, scanner.Err()
+}
+
+// findDuplicates reports rows that repeat a unique key of an earlier row in the file
+// or of a record already stored in the table.
+func findDuplicates(r *http.Request, tx pgx.Tx, imp importer, records []importRecord) ([]ImportError, error) {
+	var duplicates []ImportError
+
+	for _, columns := range imp.unique {
+		// keyOf returns false for rows missing a key column; validation already reports those
+		keyOf := func(record importRecord) (string, bool) {
+			parts := make([]string, len(columns))
+			for i, column := range columns {
+				if parts[i] = record.fields[column]; parts[i] == "" {
+					return "", false
+				}
+			}
+
+			return strings.Join(parts, "\x1f"), true
+		}
+
+		label := strings.ReplaceAll(strings.Join(columns, " and "), "_", " ")
+		field := strings.Join(columns, ",")
+
+		firstLine := make(map[string]int, len(records))
+		keys := make([]string, 0, len(records))
+
+		for _, record := range records {
+			key, ok := keyOf(record)
+			if !ok {
+				continue
+			}
+
+			if line, ok := firstLine[key]; ok {
+				duplicates = append(duplicates, ImportError{
+					Field:  field,
+					Code:   "duplicate_record",
+					Detail: fmt.Sprintf("Duplicate %s; already used on line %d", label, line),
+					Line:   record.line,
+				})
+
+				continue
+			}
+
+			firstLine[key] = record.line
+			keys = append(keys, key)
+		}
+
+		query := fmt.Sprintf(
+			`SELECT concat_ws(E'\x1f', %[1]s) FROM %[2]s WHERE concat_ws(E'\x1f', %[1]s) = ANY($1)`,
+			strings.Join(columns, ", "), pgx.Identifier{imp.table}.Sanitize(),
+		)
+
+		rows, err := tx.Query(r.Context(), query, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range existing {
+			duplicates = append(duplicates, ImportError{
+				Field:  field,
+				Code:   "duplicate_record",
+				Detail: fmt.Sprintf("A record with this %s already exists", label),
+				Line:   firstLine[key],
+			})
+		}
+	}
+
+	return duplicates, nil
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultPageSize is the number of records returned when no limit is given.
+	defaultPageSize = 50
+	// maxPageSize caps the limit query parameter.
+	maxPageSize = 500
+)
+
+// listSpec describes the filters and sort orders accepted by a list endpoint.
+// Column names refer to the output columns of the endpoint's base query, which must include an id column.
+type listSpec struct {
+	// sorts whitelists the sortable columns mapped to their SQL type, which must not be nullable.
+	sorts map[string]string
+	// defaultSort is used when the request has no sort parameter, e.g. "last_name,first_name".
+	defaultSort string
+	// filters lists the supported filter query parameters.
+	filters []filter
+}
+
+// filter maps a query parameter onto a SQL condition.
+type filter struct {
+	// param is the query parameter name.
+	param string
+	// cond is the SQL condition; %s is replaced by the parameter placeholder.
+	cond string
+	// parse validates and converts the raw parameter value.
+	parse func(string) (any, error)
+}
+
+// sortField is a single column of a parsed sort order.
+type sortField struct {
+	column string
+	typ    string
+	desc   bool
+}
+
+// pageCursor is the opaque position after the last record of a page.
+type pageCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     int      `json:"id"`
+}
+
+// listing is a parsed list request: the filters, sort order, page size and cursor.
+type listing struct {
+	sort   string
+	order  []sortField
+	conds  []string
+	args   []any
+	limit  int
+	count  int
+	cursor *pageCursor
+}
+
+// parseList parses the limit, cursor, sort and filter query parameters of a list request.
+// Returns an error message, or an empty string when the parameters are valid.
+func parseList(r *http.Request, spec listSpec) (*listing, string) {
+	params := r.URL.Query()
+
+	l := &listing{limit: defaultPageSize}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return nil, fmt.Sprintf("Limit must be between 1 and %d", maxPageSize)
+		}
+
+		l.limit = limit
+	}
+
+	l.sort = params.Get("sort")
+	if l.sort == "" {
+		l.sort = spec.defaultSort
+	}
+
+	for field := range strings.SplitSeq(l.sort, ",") {
+		field = strings.TrimSpace(field)
+		column, desc := strings.CutPrefix(field, "-")
+
+		typ, ok := spec.sorts[column]
+		if !ok {
+			return nil, fmt.Sprintf("Cannot sort by %q", column)
+		}
+
+		l.order = append(l.order, sortField{column: column, typ: typ, desc: desc})
+	}
+
+	for _, f := range spec.filters {
+		value := params.Get(f.param)
+		if value == "" {
+			continue
+		}
+
+		arg, err := f.parse(value)
+		if err != nil {
+			return nil, fmt.Sprintf("Invalid %s filter", f.param)
+		}
+
+		l.conds = append(l.conds, fmt.Sprintf(f.cond, l.arg(arg)))
+	}
+
+	if value := params.Get("cursor"); value != "" {
+		cursor, ok := decodeCursor(value)
+		if !ok || cursor.Sort != l.sort || len(cursor.Values) != len(l.order) {
+			return nil, "Invalid cursor"
+		}
+
+		l.conds = append(l.conds, l.after(cursor))
+	}
+
+	return l, ""
+}
+
+// query wraps the base query with the filters, the sort order and the page limit.
+// The result has the base query's columns followed by a text array holding the row's sort key.
+func (l *listing) query(base string) string {
+	keys := make([]string, 0, len(l.order))
+	order := make([]string, 0, len(l.order)+1)
+
+	for _, field := range l.order {
+		keys = append(keys, fmt.Sprintf("page.%s::text", field.column))
+		order = append(order, fmt.Sprintf("page.%s %s", field.column, direction(field.desc)))
+	}
+
+	order = append(order, "page.id "+direction(l.order[len(l.order)-1].desc))
+
+	where := "TRUE"
+	if len(l.conds) > 0 {
+		where = strings.Join(l.conds, " AND ")
+	}
+
+	return fmt.Sprintf(
+		"SELECT page.*, ARRAY[%s]::text[] FROM (%s) AS page WHERE %s ORDER BY %s LIMIT %d",
+		strings.Join(keys, ", "), base, where, strings.Join(order, ", "), l.limit+1,
+	)
+}
+
+// add records a scanned row; the row that ends the page becomes the next page's cursor.
+func (l *listing) add(id int, key []string) {
+	l.count++
+
+	if l.count == l.limit {
+		l.cursor = &pageCursor{Sort: l.sort, Values: key, ID: id}
+	}
+}
+
+// paginate sets the Link header pointing at the next page when more records exist,
+// and returns how many of the scanned rows belong to the current page.
+func (l *listing) paginate(w http.ResponseWriter, r *http.Request) int {
+	if l.count <= l.limit {
+		return l.count
+	}
+
+	params := r.URL.Query()
+	params.Set("cursor", encodeCursor(*l.cursor))
+	params.Set("limit", strconv.Itoa(l.limit))
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, params.Encode()))
+
+	return l.limit
+}
+
+// arg adds a query argument and returns its placeholder.
+func (l *listing) arg(value any) string {
+	l.args = append(l.args, value)
+
+	return "$" + strconv.Itoa(len(l.args))
+}
+
+// after builds the keyset condition selecting the rows that sort after the cursor.
+func (l *listing) after(cursor pageCursor) string {
+	fields := slices.Clone(l.order)
+	fields = append(fields, sortField{column: "id", typ: "int", desc: l.order[len(l.order)-1].desc})
+
+	// Cursor values are sent as text and converted to the column type by the database
+	placeholders := make([]string, len(fields))
+	for i, value := range cursor.Values {
+		placeholders[i] = fmt.Sprintf("%s::text::%s", l.arg(value), fields[i].typ)
+	}
+
+	placeholders[len(fields)-1] = l.arg(cursor.ID) + "::int"
+
+	alternatives := make([]string, 0, len(fields))
+
+	for i, field := range fields {
+		terms := make([]string, 0, i+1)
+
+		for j := range i {
+			terms = append(terms, fmt.Sprintf("page.%s = %s", fields[j].column, placeholders[j]))
+		}
+
+		op := ">"
+		if field.desc {
+			op = "<"
+		}
+
+		terms = append(terms, fmt.Sprintf("page.%s %s %s", field.column, op, placeholders[i]))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// direction returns the SQL sort direction keyword.
+func direction(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+// encodeCursor serializes a cursor into an opaque URL-safe token.
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token produced by encodeCursor.
+func decodeCursor(token string) (pageCursor, bool) {
+	var cursor pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, false
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, false
+	}
+
+	return cursor, true
+}
+
+// intParam parses an integer filter value.
+func intParam(value string) (any, error) {
+	return strconv.Atoi(value)
+}
+
+// textParam accepts any filter value as text.
+func textParam(value string) (any, error) {
+	return value, nil
+}
+
+// boolParam parses a boolean filter value.
+func boolParam(value string) (any, error) {
+	return strconv.ParseBool(value)
+}
+
+// dateParam parses a YYYY-MM-DD filter value.
+func dateParam(value string) (any, error) {
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// timestampParam parses an RFC 3339 timestamp or a YYYY-MM-DD date filter value.
+func timestampParam(value string) (any, error) {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return value, nil
+	}
+
+	return dateParam(value)
+}
+
+// timeParam parses an HH:MM or HH:MM:SS filter value.
+func timeParam(value string) (any, error) {
+	if _, err := time.Parse(time.TimeOnly, value); err == nil {
+		return value, nil
+	}
+
+	if _, err := time.Parse("15:04", value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// dayParam validates a day_of_week filter value.
+func dayParam(value string) (any, error) {
+	if _, ok := weekdays[value]; !ok {
+		return nil, fmt.Errorf("invalid day %q", value)
+	}
+
+	return value, nil
+}
+
+// componentTypeParam validates a section component type filter value.
+func componentTypeParam(value string) (any, error) {
+	if !slices.Contains(componentTypes, value) {
+		return nil, fmt.Errorf("invalid component type %q", value)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// optionalIntQuery parses an optional integer query parameter.
+// Returns nil when the parameter is absent or empty.
+func optionalIntQuery(r *http.Request, name string) (*int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// intListQuery parses an integer list query parameter given as repeated values and/or comma-separated lists.
+// Returns nil when the parameter is absent.
+func intListQuery(r *http.Request, name string) ([]int, error) {
+	var values []int
+
+	for _, param := range r.URL.Query()[name] {
+		for value := range strings.SplitSeq(param, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, n)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/solver"
+	"code.local/internal/pkg/utils"
+)
+
+// schedulingPolicy is the institution's scheduling policy with its daily window in minutes after midnight.
+type schedulingPolicy struct {
+	schema.SchedulingPolicy
+	dayStart int
+	dayEnd   int
+}
+
+// GetPolicy handles HTTP GET requests to retrieve the institution's scheduling policy.
+// Returns the days sections may meet on, the allowed meeting durations, the daily window
+// meetings must fit into and the standard meeting patterns used by the timetable solver.
+func (h *Handlers) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.fetchPolicy(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch scheduling policy")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, policy.SchedulingPolicy)
+}
+
+// fetchPolicy loads the scheduling policy and its meeting patterns.
+func (h *Handlers) fetchPolicy(ctx context.Context) (schedulingPolicy, error) {
+	var policy schedulingPolicy
+
+	query := `
+		SELECT days::text[], durations, day_start::text, day_end::text, updated_at
+		FROM scheduling_policy
+	`
+
+	err := h.db.QueryRow(ctx, query).Scan(
+		&policy.Days, &policy.Durations, &policy.DayStart, &policy.DayEnd, &policy.UpdatedAt,
+	)
+	if err != nil {
+		return policy, err
+	}
+
+	if policy.dayStart, err = solver.ParseClock(policy.DayStart); err != nil {
+		return policy, err
+	}
+
+	if policy.dayEnd, err = solver.ParseClock(policy.DayEnd); err != nil {
+		return policy, err
+	}
+
+	rows, err := h.db.Query(ctx, `SELECT code, days::text[] FROM meeting_patterns ORDER BY id`)
+	if err != nil {
+		return policy, err
+	}
+
+	policy.Patterns, err = pgx.CollectRows(rows, pgx.RowToStructByPos[schema.MeetingPattern])
+
+	return policy, err
+}
+
+// patterns maps the codes of the policy's meeting patterns to their days, as used by the timetable solver.
+func (p schedulingPolicy) patterns() map[string][]string {
+	patterns := make(map[string][]string, len(p.Patterns))
+
+	for _, pattern := range p.Patterns {
+		patterns[pattern.Code] = pattern.Days
+	}
+
+	return patterns
+}
+
+// patternList lists the codes of the policy's meeting patterns for validation messages, e.g. "MWF, TTh".
+func (p schedulingPolicy) patternList() string {
+	codes := make([]string, len(p.Patterns))
+	for i, pattern := range p.Patterns {
+		codes[i] = pattern.Code
+	}
+
+	return strings.Join(codes, ", ")
+}
+
+// durationList lists the policy's meeting durations for validation messages, e.g. "50, 80".
+func (p schedulingPolicy) durationList() string {
+	durations := make([]string, len(p.Durations))
+	for i, duration := range p.Durations {
+		durations[i] = strconv.Itoa(duration)
+	}
+
+	return strings.Join(durations, ", ")
+}
+
+// window describes the policy's daily window for validation messages, e.g. "07:30:00-22:00:00".
+func (p schedulingPolicy) window() string {
+	return solver.FormatClock(p.dayStart) + "-" + solver.FormatClock(p.dayEnd)
+}
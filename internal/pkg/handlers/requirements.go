@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// GetSubjectRequirements handles HTTP GET requests to retrieve the prerequisites and corequisites of a subject.
+// Returns the requirement groups with full subject details for catalog display.
+func (h *Handlers) GetSubjectRequirements(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid subject ID")
+
+		return
+	}
+
+	if _, err := h.fetchSubject(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Subject not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch subject")
+
+		return
+	}
+
+	requirements, err := h.fetchRequirements(r.Context(), id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch requirements")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, requirements)
+}
+
+// SetSubjectRequirements handles HTTP PUT requests to replace the prerequisites and corequisites of a subject.
+// Each group in the request lists alternative subject IDs; prerequisite chains must not form a cycle.
+// Returns the stored requirements.
+func (h *Handlers) SetSubjectRequirements(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid subject ID")
+
+		return
+	}
+
+	var requirementsReq schema.SetRequirementsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&requirementsReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateRequirements(id, requirementsReq); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	// Lock the subject so concurrent updates cannot sneak a prerequisite cycle past the check below
+	var locked int
+
+	err = tx.QueryRow(r.Context(), `SELECT id FROM subjects WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Subject not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch subject")
+
+		return
+	}
+
+	if _, err := tx.Exec(r.Context(), `DELETE FROM subject_requirements WHERE subject_id = $1`, id); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to clear requirements")
+
+		return
+	}
+
+	insertQuery := `
+		INSERT INTO subject_requirements (subject_id, required_subject_id, kind, group_number)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	kinds := []struct {
+		name   string
+		groups [][]int
+	}{
+		{"prerequisite", requirementsReq.Prerequisites},
+		{"corequisite", requirementsReq.Corequisites},
+	}
+
+	for _, kind := range kinds {
+		for i, group := range kind.groups {
+			for _, requiredID := range group {
+				if _, err := tx.Exec(r.Context(), insertQuery, id, requiredID, kind.name, i+1); err != nil {
+					var pgErr *pgconn.PgError
+
+					if errors.As(err, &pgErr) {
+						switch pgErr.Code {
+						case "23503": // Foreign key violation
+							utils.SendErrorCode(w, http.StatusNotFound, "reference_not_found", fmt.Sprintf("Required subject %d not found", requiredID))
+
+							return
+						case "23505": // Unique violation
+							utils.SendErrorCode(w, http.StatusBadRequest, "duplicate_record", "A subject is listed twice in the same requirement group")
+
+							return
+						}
+					}
+
+					utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to add requirement: %v", err))
+
+					return
+				}
+			}
+		}
+	}
+
+	cycleQuery := `
+		WITH RECURSIVE required AS (
+			SELECT required_subject_id
+			FROM subject_requirements
+			WHERE subject_id = $1 AND kind = 'prerequisite'
+			UNION
+			SELECT sr.required_subject_id
+			FROM subject_requirements sr
+			JOIN required req ON sr.subject_id = req.required_subject_id
+			WHERE sr.kind = 'prerequisite'
+		)
+		SELECT EXISTS (SELECT 1 FROM required WHERE required_subject_id = $1)
+	`
+
+	var cyclic bool
+
+	if err := tx.QueryRow(r.Context(), cycleQuery, id).Scan(&cyclic); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to check prerequisite chain")
+
+		return
+	}
+
+	if cyclic {
+		utils.SendErrorCode(w, http.StatusBadRequest, "prerequisite_cycle", "Prerequisites would form a cycle")
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	requirements, err := h.fetchRequirements(r.Context(), id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch requirements")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, requirements)
+}
+
+// sendUnmetRequirements responds with 422 Unprocessable Entity listing the requirement groups
+// of the section's subject that the student does not meet.
+func (h *Handlers) sendUnmetRequirements(w http.ResponseWriter, r *http.Request, studentID, sectionID int) {
+	groups, err := h.fetchUnmetRequirements(r.Context(), studentID, sectionID)
+	if err != nil {
+		utils.SendErrorCode(w, http.StatusUnprocessableEntity, "requirements_not_met", "Enrollment requirements are not met")
+
+		return
+	}
+
+	utils.SendProblem(w, RequirementsResponse{
+		Problem: utils.NewProblem(http.StatusUnprocessableEntity, "requirements_not_met", "Enrollment requirements are not met"),
+		Unmet:   groups,
+	})
+}
+
+// fetchUnmetRequirements loads the requirement groups of the section's subject that the student does not meet.
+func (h *Handlers) fetchUnmetRequirements(ctx context.Context, studentID, sectionID int) ([]schema.RequirementGroup, error) {
+	query := `
+		SELECT u.kind::text, u.group_number, s.id, s.code, s.name, s.description, s.created_at, s.updated_at
+		FROM find_unmet_requirements($1, $2) u
+		JOIN subjects s ON s.id = u.required_subject_id
+		ORDER BY u.kind, u.group_number, s.code
+	`
+
+	rows, err := h.db.Query(ctx, query, studentID, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectRequirementGroups(rows)
+}
+
+// fetchRequirements loads all requirement groups of a subject.
+func (h *Handlers) fetchRequirements(ctx context.Context, subjectID int) (schema.SubjectRequirements, error) {
+	requirements := schema.SubjectRequirements{
+		Prerequisites: []schema.RequirementGroup{},
+		Corequisites:  []schema.RequirementGroup{},
+		SubjectID:     subjectID,
+	}
+
+	query := `
+		SELECT sr.kind::text, sr.group_number, s.id, s.code, s.name, s.description, s.created_at, s.updated_at
+		FROM subject_requirements sr
+		JOIN subjects s ON s.id = sr.required_subject_id
+		WHERE sr.subject_id = $1
+		ORDER BY sr.kind, sr.group_number, s.code
+	`
+
+	rows, err := h.db.Query(ctx, query, subjectID)
+	if err != nil {
+		return requirements, err
+	}
+
+	groups, err := collectRequirementGroups(rows)
+	if err != nil {
+		return requirements, err
+	}
+
+	for _, group := range groups {
+		if group.Kind == "corequisite" {
+			requirements.Corequisites = append(requirements.Corequisites, group)
+		} else {
+			requirements.Prerequisites = append(requirements.Prerequisites, group)
+		}
+	}
+
+	return requirements, nil
+}
+
+// collectRequirementGroups folds requirement rows ordered by kind and group number into groups.
+// Each row holds kind, group number and the required subject's columns.
+func collectRequirementGroups(rows pgx.Rows) ([]schema.RequirementGroup, error) {
+	defer rows.Close()
+
+	var groups []schema.RequirementGroup
+
+	for rows.Next() {
+		var (
+			kind    string
+			group   int
+			subject schema.Subject
+		)
+
+		err := rows.Scan(
+			&kind, &group, &subject.ID, &subject.Code, &subject.Name,
+			&subject.Description, &subject.CreatedAt, &subject.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(groups); n == 0 || groups[n-1].Kind != kind || groups[n-1].Group != group {
+			groups = append(groups, schema.RequirementGroup{Kind: kind, Group: group})
+		}
+
+		last := &groups[len(groups)-1]
+		last.Subjects = append(last.Subjects, subject)
+	}
+
+	return groups, rows.Err()
+}
+
+// validateRequirements checks that every requirement group is non-empty
+// and that a subject does not require itself.
+// Returns the invalid fields, or nil when the requirements are valid.
+func validateRequirements(subjectID int, req schema.SetRequirementsRequest) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	kinds := []struct {
+		field  string
+		groups [][]int
+	}{
+		{"prerequisites", req.Prerequisites},
+		{"corequisites", req.Corequisites},
+	}
+
+	for _, kind := range kinds {
+		for i, group := range kind.groups {
+			field := fmt.Sprintf("%s[%d]", kind.field, i)
+
+			if len(group) == 0 {
+				errs.Add(field, "required", "Requirement groups must not be empty")
+
+				continue
+			}
+
+			for _, requiredID := range group {
+				if requiredID <= 0 {
+					errs.Add(field, "invalid", "Invalid required subject ID")
+				} else if requiredID == subjectID {
+					errs.Add(field, "invalid", "A subject cannot require itself")
+				}
+			}
+		}
+	}
+
+	return errs
+}
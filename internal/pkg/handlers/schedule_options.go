@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/solver"
+	"code.local/internal/pkg/utils"
+)
+
+const (
+	// defaultScheduleOptions is the number of options returned when no limit is given.
+	defaultScheduleOptions = 10
+	// maxScheduleOptions caps the limit of a schedule options request.
+	maxScheduleOptions = 50
+	// maxDesiredSubjects caps the number of subjects combined in one request.
+	maxDesiredSubjects = 10
+)
+
+// BuildScheduleOptions handles HTTP POST requests to build conflict-free schedules for a student.
+// Accepts the desired subject IDs, an optional term ID and preferences (no classes before a time,
+// free days, compact days), and combines one open section per subject so that no two sections
+// and no current enrollment of the student overlap. Lectures with linked components are combined with
+// one open component of each type. Options are ranked by how well they meet the preferences;
+// subjects that cannot be scheduled at all are listed with the reason why.
+func (h *Handlers) BuildScheduleOptions(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	var optionsReq schema.ScheduleOptionsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&optionsReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateScheduleOptions(optionsReq); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	if optionsReq.Limit == 0 {
+		optionsReq.Limit = defaultScheduleOptions
+	}
+
+	prefs := solver.Preferences{
+		FreeDays: optionsReq.Preferences.FreeDays,
+		Compact:  optionsReq.Preferences.CompactDays,
+	}
+
+	if optionsReq.Preferences.NoClassesBefore != "" {
+		prefs.EarliestStart, _ = solver.ParseClock(optionsReq.Preferences.NoClassesBefore)
+	}
+
+	if _, err := h.fetchStudent(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Student not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch student info")
+
+		return
+	}
+
+	// Current enrollments of the term are fixed; like check_schedule_conflict, only sections of the same term can clash
+	enrolledQuery := `
+		SELECT s.subject_id, m.start_time::text, m.duration_minutes, m.days::text[]
+		FROM enrollments e
+		JOIN sections s ON e.section_id = s.id
+		JOIN section_meeting_view m ON s.id = m.section_id
+		WHERE e.student_id = $1 AND s.term_id = $2
+	`
+
+	rows, err := h.db.Query(r.Context(), enrolledQuery, id, optionsReq.TermID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch enrollments")
+
+		return
+	}
+	defer rows.Close()
+
+	var (
+		busy             []solver.Slot
+		enrolledSubjects []int
+	)
+
+	for rows.Next() {
+		var (
+			subjectID       int
+			startTime       string
+			durationMinutes int
+			days            []string
+		)
+
+		if err := rows.Scan(&subjectID, &startTime, &durationMinutes, &days); err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan enrollment")
+
+			return
+		}
+
+		start, _ := solver.ParseClock(startTime)
+		busy = append(busy, solver.Slot{Days: days, Start: start, End: start + durationMinutes})
+		enrolledSubjects = append(enrolledSubjects, subjectID)
+	}
+
+	// One row per meeting; the meetings of a section are adjacent
+	sectionsQuery := `
+		SELECT
+			s.id, s.subject_id, sub.code, s.section_code, s.max_enrollment - s.current_enrollment,
+			s.parent_section_id, s.component_type,
+			ARRAY(SELECT DISTINCT c.component_type FROM sections c WHERE c.parent_section_id = s.id ORDER BY 1),
+			m.start_time::text, m.end_time::text, m.days::text[], m.classroom_id, m.duration_minutes
+		FROM sections s
+		JOIN subjects sub ON s.subject_id = sub.id
+		JOIN section_meeting_view m ON s.id = m.section_id
+		WHERE s.subject_id = ANY($1)
+		  AND s.term_id = $2
+		  AND s.current_enrollment < s.max_enrollment
+		  AND is_registration_open(s.id)
+		ORDER BY sub.code, s.section_code, s.id, m.days[1], m.start_time
+	`
+
+	rows, err = h.db.Query(r.Context(), sectionsQuery, optionsReq.SubjectIDs, optionsReq.TermID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch sections")
+
+		return
+	}
+	defer rows.Close()
+
+	sections := make(map[int]schema.ScheduleOptionSection)
+	courses := make([]solver.Course, len(optionsReq.SubjectIDs))
+
+	// componentTypes holds the component types each lecture with linked components offers, open or not
+	componentTypes := make(map[int][]string)
+
+	for i, subjectID := range optionsReq.SubjectIDs {
+		courses[i].SubjectID = subjectID
+	}
+
+	for rows.Next() {
+		var (
+			section schema.ScheduleOptionSection
+			meeting schema.Meeting
+			types   []string
+		)
+
+		err := rows.Scan(
+			&section.SectionID, &section.SubjectID, &section.SubjectCode, &section.SectionCode, &section.SeatsAvailable,
+			&section.ParentSectionID, &section.ComponentType, &types,
+			&meeting.StartTime, &meeting.EndTime, &meeting.Days, &meeting.ClassroomID, &meeting.DurationMinutes,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan section")
+
+			return
+		}
+
+		start, _ := solver.ParseClock(meeting.StartTime)
+		slot := solver.Slot{Days: meeting.Days, Start: start, End: start + meeting.DurationMinutes}
+
+		i := slices.Index(optionsReq.SubjectIDs, section.SubjectID)
+		choices := courses[i].Choices
+
+		if n := len(choices); n > 0 && choices[n-1].SectionID == section.SectionID {
+			choices[n-1].Slots = append(choices[n-1].Slots, slot)
+		} else {
+			courses[i].Choices = append(choices, solver.Choice{Slots: []solver.Slot{slot}, SectionID: section.SectionID})
+		}
+
+		if stored, ok := sections[section.SectionID]; ok {
+			section = stored
+		}
+
+		section.Meetings = append(section.Meetings, meeting)
+		sections[section.SectionID] = section
+
+		if len(types) > 0 {
+			componentTypes[section.SectionID] = types
+		}
+	}
+
+	// Lectures with linked components are only offered together with one open component of each type,
+	// and components are never offered on their own
+	for i := range courses {
+		var choices []solver.Choice
+
+		for _, choice := range courses[i].Choices {
+			types := componentTypes[choice.SectionID]
+
+			switch {
+			case sections[choice.SectionID].ParentSectionID != nil:
+				continue
+			case len(types) == 0:
+				choices = append(choices, choice)
+
+				continue
+			}
+
+			groups := make([][]solver.Choice, len(types))
+
+			for _, component := range courses[i].Choices {
+				if parentID := sections[component.SectionID].ParentSectionID; parentID != nil && *parentID == choice.SectionID {
+					k := slices.Index(types, sections[component.SectionID].ComponentType)
+					groups[k] = append(groups[k], component)
+				}
+			}
+
+			choices = append(choices, solver.WithComponents(choice, groups)...)
+		}
+
+		courses[i].Choices = choices
+	}
+
+	// Single-meeting sections repeat their meeting in the shorthand fields
+	for sectionID, section := range sections {
+		if len(section.Meetings) == 1 {
+			section.StartTime = section.Meetings[0].StartTime
+			section.EndTime = section.Meetings[0].EndTime
+			section.Days = section.Meetings[0].Days
+			sections[sectionID] = section
+		}
+	}
+
+	response := schema.ScheduleOptionsResponse{
+		Options:     []schema.ScheduleOption{},
+		Unavailable: []schema.UnavailableSubject{},
+	}
+
+	for _, course := range courses {
+		reason := ""
+
+		switch {
+		case slices.Contains(enrolledSubjects, course.SubjectID):
+			reason = "Student is already enrolled in this subject"
+		case len(course.Choices) == 0:
+			reason = "No open section"
+		case !slices.ContainsFunc(course.Choices, func(choice solver.Choice) bool {
+			return !choice.Overlaps(busy)
+		}):
+			reason = "Every open section conflicts with current enrollments"
+		}
+
+		if reason != "" {
+			response.Unavailable = append(response.Unavailable, schema.UnavailableSubject{
+				Reason:    reason,
+				SubjectID: course.SubjectID,
+			})
+		}
+	}
+
+	if len(response.Unavailable) > 0 {
+		utils.SendJSON(w, http.StatusOK, response)
+
+		return
+	}
+
+	for i, combination := range solver.Combine(courses, busy, prefs, optionsReq.Limit) {
+		option := schema.ScheduleOption{
+			Sections:         make([]schema.ScheduleOptionSection, 0, len(combination.Choices)),
+			UnmetPreferences: []string{},
+			Rank:             i + 1,
+			Score:            combination.Penalty,
+		}
+
+		option.UnmetPreferences = append(option.UnmetPreferences, combination.Unmet...)
+
+		for _, choice := range combination.Choices {
+			option.Sections = append(option.Sections, sections[choice.SectionID])
+
+			for _, componentID := range choice.ComponentIDs {
+				option.Sections = append(option.Sections, sections[componentID])
+			}
+		}
+
+		response.Options = append(response.Options, option)
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+// validateScheduleOptions checks the subjects, preferences and limit of a schedule options request.
+// Returns the invalid fields, or nil when the request is valid.
+func validateScheduleOptions(optionsReq schema.ScheduleOptionsRequest) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(optionsReq.TermID == nil, "term_id", "Term ID is required")
+
+	switch {
+	case len(optionsReq.SubjectIDs) == 0:
+		errs.Add("subject_ids", "required", "At least one subject ID is required")
+	case len(optionsReq.SubjectIDs) > maxDesiredSubjects:
+		errs.Add("subject_ids", "invalid", fmt.Sprintf("At most %d subjects can be combined", maxDesiredSubjects))
+	}
+
+	for i, subjectID := range optionsReq.SubjectIDs {
+		if subjectID <= 0 {
+			errs.Add(fmt.Sprintf("subject_ids[%d]", i), "invalid", "Invalid subject ID")
+		} else if slices.Contains(optionsReq.SubjectIDs[:i], subjectID) {
+			errs.Add(fmt.Sprintf("subject_ids[%d]", i), "invalid", "Subject IDs must be unique")
+		}
+	}
+
+	if optionsReq.Limit < 0 || optionsReq.Limit > maxScheduleOptions {
+		errs.Add("limit", "invalid", fmt.Sprintf("Limit must be between 1 and %d", maxScheduleOptions))
+	}
+
+	if value := optionsReq.Preferences.NoClassesBefore; value != "" {
+		if _, err := solver.ParseClock(value); err != nil {
+			errs.Add("preferences.no_classes_before", "invalid", "No classes before must be in HH:MM format")
+		}
+	}
+
+	for _, day := range optionsReq.Preferences.FreeDays {
+		if _, ok := weekdays[day]; !ok {
+			errs.Add("preferences.free_days", "invalid", "Free days must be monday, tuesday, wednesday, thursday, friday, or saturday")
+
+			break
+		}
+	}
+
+	return errs
+}
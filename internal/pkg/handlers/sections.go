@@ -1,35 +1,105 @@
 package handlers
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/lib/pq"
 
 	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/solver"
 	"code.local/internal/pkg/utils"
 )
 
-// GetSections handles HTTP GET requests to retrieve all course sections.
-// Returns a list of all sections with their associated days, ordered by section ID,
-// aggregating day information from the section_days table.
+// sectionList describes the filters and sort orders accepted by GetSections.
+// Meeting filters match a section when any of its meetings is held in the classroom or on the day,
+// and when all of its meetings start after or before the given time.
+var sectionList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "section_code": "text", "start_time": "time", "duration_minutes": "int",
+		"max_enrollment": "int", "current_enrollment": "int", "created_at": "timestamptz",
+	},
+	defaultSort: "id",
+	filters: []filter{
+		{param: "term_id", cond: "term_id = %s::int", parse: intParam},
+		{param: "subject_id", cond: "subject_id = %s::int", parse: intParam},
+		{param: "teacher_id", cond: "teacher_id = %s::int", parse: intParam},
+		{param: "parent_section_id", cond: "parent_section_id = %s::int", parse: intParam},
+		{param: "component_type", cond: "component_type = %s", parse: componentTypeParam},
+		{
+			param: "classroom_id", parse: intParam,
+			cond: "EXISTS (SELECT 1 FROM section_meetings m WHERE m.section_id = page.id AND m.classroom_id = %s::int)",
+		},
+		{
+			param: "day", parse: dayParam,
+			cond: "EXISTS (SELECT 1 FROM section_meetings m WHERE m.section_id = page.id AND m.day = %s::day_of_week)",
+		},
+		{
+			param: "starts_after", parse: timeParam,
+			cond: "NOT EXISTS (SELECT 1 FROM section_meetings m WHERE m.section_id = page.id AND m.start_time < %s::time)",
+		},
+		{
+			param: "starts_before", parse: timeParam,
+			cond: "NOT EXISTS (SELECT 1 FROM section_meetings m WHERE m.section_id = page.id AND m.start_time >= %s::time)",
+		},
+		{param: "has_seats", cond: "(current_enrollment < max_enrollment) = %s::bool", parse: boolParam},
+	},
+}
+
+// meetingsColumn aggregates the meetings of the section aliased as s into a JSON array,
+// ordered by their first day and start time.
+const meetingsColumn = `(
+		SELECT COALESCE(JSON_AGG(JSON_BUILD_OBJECT(
+			'start_time', m.start_time::text, 'end_time', m.end_time::text, 'days', m.days,
+			'classroom_id', m.classroom_id, 'duration_minutes', m.duration_minutes
+		) ORDER BY m.days[1], m.start_time), '[]')
+		FROM section_meeting_view m
+		WHERE m.section_id = s.id
+	)`
+
+// sectionColumns selects the section columns scanned by scanSection from sections aliased as s.
+const sectionColumns = `
+	s.id, s.term_id, s.subject_id, s.teacher_id, s.section_code, s.parent_section_id, s.component_type,
+	s.max_enrollment, s.current_enrollment, s.max_waitlist, s.created_at, s.updated_at,
+	` + meetingsColumn + ` AS meetings`
+
+// GetSections handles HTTP GET requests to retrieve a page of course sections.
+// Returns sections with their meetings, aggregated from the section_meetings table.
+// Supports the term_id, subject_id, teacher_id, parent_section_id, component_type, classroom_id, day,
+// starts_after, starts_before and has_seats filters, sorting and cursor pagination; results are ordered by section ID by default.
+// Sorting by start_time or duration_minutes uses the first meeting of the week.
 func (h *Handlers) GetSections(w http.ResponseWriter, r *http.Request) {
-	query := `
-		SELECT 
-			s.id, s.subject_id, s.teacher_id, s.classroom_id, s.section_code,
-			s.start_time, s.duration_minutes, s.max_enrollment, s.current_enrollment,
-			s.created_at, s.updated_at,
-			ARRAY_AGG(sd.day) as days
-		FROM sections s
-		LEFT JOIN section_days sd ON s.id = sd.section_id
-		GROUP BY s.id
-		ORDER BY s.id
-	`
+	list, msg := parseList(r, sectionList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
 
-	rows, err := h.db.Query(r.Context(), query)
+		return
+	}
+
+	query := list.query(`
+		SELECT` + sectionColumns + `,
+			COALESCE(first_meeting.start_time, '00:00') AS start_time,
+			COALESCE(first_meeting.duration_minutes, 0) AS duration_minutes
+		FROM sections s
+		LEFT JOIN LATERAL (
+			SELECT m.start_time, m.duration_minutes
+			FROM section_meeting_view m
+			WHERE m.section_id = s.id
+			ORDER BY m.days[1], m.start_time
+			LIMIT 1
+		) first_meeting ON TRUE
+	`)
+
+	rows, err := h.db.Query(r.Context(), query, list.args...)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch sections")
 
@@ -40,33 +110,26 @@ func (h *Handlers) GetSections(w http.ResponseWriter, r *http.Request) {
 	var sections []schema.Section
 
 	for rows.Next() {
-		var (
-			section schema.Section
-			days    pq.StringArray
-		)
+		var key []string
 
-		err := rows.Scan(
-			&section.ID, &section.SubjectID, &section.TeacherID, &section.ClassroomID,
-			&section.SectionCode, &section.StartTime, &section.DurationMinutes,
-			&section.MaxEnrollment, &section.CurrentEnrollment,
-			&section.CreatedAt, &section.UpdatedAt, &days,
-		)
+		// The sort columns repeat the first meeting and are skipped
+		section, err := scanSection(rows, nil, nil, &key)
 		if err != nil {
 			utils.SendError(w, http.StatusInternalServerError, "Failed to scan section")
 
 			return
 		}
 
-		section.Days = []string(days)
+		list.add(section.ID, key)
 		sections = append(sections, section)
 	}
 
-	utils.SendJSON(w, http.StatusOK, sections)
+	utils.SendJSON(w, http.StatusOK, sections[:list.paginate(w, r)])
 }
 
 // CreateSection handles HTTP POST requests to create a new course section.
-// Validates the section data including day values and duration constraints,
-// creates the section and its associated days within a transaction,
+// Validates the section data including the days and durations of its meetings,
+// creates the section and its meetings within a transaction,
 // and returns the created section with its ID and metadata.
 func (h *Handlers) CreateSection(w http.ResponseWriter, r *http.Request) {
 	var sectionReq schema.CreateSectionRequest
@@ -77,31 +140,238 @@ func (h *Handlers) CreateSection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if sectionReq.SubjectID <= 0 || sectionReq.TeacherID <= 0 || sectionReq.ClassroomID <= 0 ||
-		sectionReq.SectionCode == "" || sectionReq.StartTime == "" ||
-		sectionReq.DurationMinutes <= 0 || sectionReq.MaxEnrollment <= 0 ||
-		len(sectionReq.Days) == 0 {
-		utils.SendError(w, http.StatusBadRequest, "All fields are required")
+	policy, err := h.fetchPolicy(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch scheduling policy")
+
+		return
+	}
+
+	if errs := validateSection(sectionReq, policy); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	section, err := insertSection(r.Context(), tx, sectionReq)
+	if err != nil {
+		h.sendSectionError(w, r, err, sectionReq, nil, "create")
 
 		return
 	}
 
-	if sectionReq.DurationMinutes != 50 && sectionReq.DurationMinutes != 80 {
-		utils.SendError(w, http.StatusBadRequest, "Duration minutes must be either 50 or 80")
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
 
 		return
 	}
 
-	validDays := map[string]bool{
-		"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true,
+	utils.SendJSON(w, http.StatusCreated, section)
+}
+
+// GetSectionByID handles HTTP GET requests to retrieve a specific section by ID.
+// Accepts a section ID path parameter and returns the matching section with its meetings or a not found error.
+func (h *Handlers) GetSectionByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	section, err := h.fetchSection(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Section not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch section")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, section)
+}
+
+// GetSectionRoster handles HTTP GET requests to retrieve the students enrolled in a section.
+// Returns the enrolled students ordered by last and first name, or a not found error for unknown sections.
+func (h *Handlers) GetSectionRoster(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	var exists bool
+
+	err = h.db.QueryRow(r.Context(), `SELECT EXISTS(SELECT 1 FROM sections WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch section")
+
+		return
+	}
+
+	if !exists {
+		utils.SendError(w, http.StatusNotFound, "Section not found")
+
+		return
+	}
+
+	query := `
+		SELECT st.id, st.student_id, st.first_name, st.last_name, st.email, st.created_at, st.updated_at
+		FROM enrollments e
+		JOIN students st ON st.id = e.student_id
+		WHERE e.section_id = $1
+		ORDER BY st.last_name, st.first_name, st.id
+	`
+
+	rows, err := h.db.Query(r.Context(), query, id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch roster")
+
+		return
+	}
+	defer rows.Close()
+
+	students := []schema.Student{}
+
+	for rows.Next() {
+		var student schema.Student
+
+		err := rows.Scan(
+			&student.ID, &student.StudentID, &student.FirstName, &student.LastName,
+			&student.Email, &student.CreatedAt, &student.UpdatedAt,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan student")
+
+			return
+		}
+
+		students = append(students, student)
+	}
+
+	utils.SendJSON(w, http.StatusOK, students)
+}
+
+// UpdateSection handles HTTP PUT requests to replace a course section.
+// All fields are required and validated as with CreateSection; the section meetings are replaced.
+func (h *Handlers) UpdateSection(w http.ResponseWriter, r *http.Request) {
+	h.updateSection(w, r, false)
+}
+
+// PatchSection handles HTTP PATCH requests to partially update a course section.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchSection(w http.ResponseWriter, r *http.Request) {
+	h.updateSection(w, r, true)
+}
+
+// DeleteSection handles HTTP DELETE requests to remove a course section.
+// Refuses with a conflict while students are still enrolled or a lecture still has linked components
+// unless ?cascade=true is given, in which case the components and all enrollments are deleted as well.
+func (h *Handlers) DeleteSection(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Section",
+		query:  `DELETE FROM sections WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE section_id IN (SELECT id FROM sections WHERE id = $1 OR parent_section_id = $1)`,
+			`DELETE FROM sections WHERE parent_section_id = $1`,
+		},
+		dependents: "enrollments or linked components",
+	})
+}
+
+// updateSection applies a full (PUT) or partial (PATCH) update to a course section.
+// For partial updates the request body is decoded on top of the stored section.
+// The section row and its meetings are replaced within a single transaction.
+func (h *Handlers) updateSection(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
 	}
 
-	for _, day := range sectionReq.Days {
-		if !validDays[day] {
-			utils.SendError(w, http.StatusBadRequest, "Days must be monday, tuesday, wednesday, thursday, or friday")
+	var (
+		sectionReq    schema.CreateSectionRequest
+		singleMeeting bool
+	)
+
+	if partial {
+		current, err := h.fetchSection(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Section not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch section")
 
 			return
 		}
+
+		sectionReq = schema.CreateSectionRequest{
+			SectionCode:     current.SectionCode,
+			ComponentType:   current.ComponentType,
+			StartTime:       current.StartTime,
+			Days:            current.Days,
+			TermID:          &current.TermID,
+			ParentSectionID: current.ParentSectionID,
+			SubjectID:       current.SubjectID,
+			TeacherID:       current.TeacherID,
+			ClassroomID:     current.ClassroomID,
+			DurationMinutes: current.DurationMinutes,
+			MaxEnrollment:   current.MaxEnrollment,
+			MaxWaitlist:     current.MaxWaitlist,
+		}
+
+		// Single-meeting sections are patched through the shorthand fields, others through their meetings
+		singleMeeting = len(current.Meetings) == 1
+		if !singleMeeting {
+			sectionReq.Meetings = current.Meetings
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&sectionReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if singleMeeting && len(sectionReq.Meetings) > 0 {
+		// Meetings in the body replace the single meeting of the stored section
+		sectionReq.StartTime, sectionReq.Days, sectionReq.ClassroomID, sectionReq.DurationMinutes = "", nil, 0, 0
+	}
+
+	policy, err := h.fetchPolicy(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch scheduling policy")
+
+		return
+	}
+
+	if errs := validateSection(sectionReq, policy); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
 	}
 
 	tx, err := h.db.Begin(r.Context())
@@ -112,67 +382,495 @@ func (h *Handlers) CreateSection(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(r.Context())
 
+	// Meetings are removed before the section row changes so the new times are only checked against the new meetings
+	if _, err := tx.Exec(r.Context(), `DELETE FROM section_meetings WHERE section_id = $1`, id); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to remove section meetings: %v", err))
+
+		return
+	}
+
 	var section schema.Section
 
 	sectionQuery := `
-		INSERT INTO sections (subject_id, teacher_id, classroom_id, section_code, start_time, duration_minutes, max_enrollment)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, subject_id, teacher_id, classroom_id, section_code, start_time::text, duration_minutes, max_enrollment, current_enrollment, created_at, updated_at
+		UPDATE sections
+		SET term_id = $2, subject_id = $3, teacher_id = $4, section_code = $5, max_enrollment = $6, max_waitlist = $7,
+			parent_section_id = $8, component_type = $9
+		WHERE id = $1
+		RETURNING id, term_id, subject_id, teacher_id, section_code, parent_section_id, component_type,
+			max_enrollment, current_enrollment, max_waitlist, created_at, updated_at
 	`
 
 	err = tx.QueryRow(
 		r.Context(),
 		sectionQuery,
+		id,
+		sectionReq.TermID,
 		sectionReq.SubjectID,
 		sectionReq.TeacherID,
-		sectionReq.ClassroomID,
 		sectionReq.SectionCode,
-		sectionReq.StartTime,
-		sectionReq.DurationMinutes,
 		sectionReq.MaxEnrollment,
+		sectionReq.MaxWaitlist,
+		sectionReq.ParentSectionID,
+		cmp.Or(sectionReq.ComponentType, "lecture"),
 	).Scan(
-		&section.ID, &section.SubjectID, &section.TeacherID, &section.ClassroomID,
-		&section.SectionCode, &section.StartTime, &section.DurationMinutes,
-		&section.MaxEnrollment, &section.CurrentEnrollment, &section.CreatedAt, &section.UpdatedAt,
+		&section.ID, &section.TermID, &section.SubjectID, &section.TeacherID, &section.SectionCode,
+		&section.ParentSectionID, &section.ComponentType, &section.MaxEnrollment, &section.CurrentEnrollment,
+		&section.MaxWaitlist, &section.CreatedAt, &section.UpdatedAt,
 	)
 	if err != nil {
-		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Section not found")
 
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case "23505": // Unique violation
-				utils.SendError(w, http.StatusConflict, "A section with this subject and section code already exists")
-			case "23514": // Check constraint violation
-				utils.SendError(w, http.StatusBadRequest, "Section details violate constraints. Check time limits and duration.")
-			default:
-				utils.SendError(w, http.StatusInternalServerError, "Database error: "+pgErr.Message)
-			}
-		} else {
-			utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create section: %v", err))
+			return
 		}
 
+		h.sendSectionError(w, r, err, sectionReq, &id, "update")
+
 		return
 	}
 
-	for _, day := range sectionReq.Days {
-		_, err := tx.Exec(r.Context(), `
-			INSERT INTO section_days (section_id, day)
-			VALUES ($1, $2)
-		`, section.ID, day)
+	meetings, err := insertMeetings(r.Context(), tx, section.ID, sectionMeetings(sectionReq))
+	if err != nil {
+		h.sendSectionError(w, r, err, sectionReq, &id, "add meetings to")
+
+		return
+	}
+
+	// Seats added by raising the max enrollment are offered to the waitlist first
+	if _, err := promoteFromWaitlist(r.Context(), tx, id); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to promote waitlisted students: %v", err))
+
+		return
+	}
+
+	err = tx.QueryRow(r.Context(), `SELECT current_enrollment FROM sections WHERE id = $1`, id).
+		Scan(&section.CurrentEnrollment)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch section enrollment")
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	setMeetings(&section, meetings)
+
+	utils.SendJSON(w, http.StatusOK, section)
+}
+
+// insertSection creates a section and its meetings within the given transaction.
+func insertSection(ctx context.Context, tx pgx.Tx, sectionReq schema.CreateSectionRequest) (schema.Section, error) {
+	var section schema.Section
+
+	sectionQuery := `
+		INSERT INTO sections (
+			term_id, subject_id, teacher_id, section_code, max_enrollment, max_waitlist, parent_section_id, component_type
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, term_id, subject_id, teacher_id, section_code, parent_section_id, component_type,
+			max_enrollment, current_enrollment, max_waitlist, created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		sectionQuery,
+		sectionReq.TermID,
+		sectionReq.SubjectID,
+		sectionReq.TeacherID,
+		sectionReq.SectionCode,
+		sectionReq.MaxEnrollment,
+		sectionReq.MaxWaitlist,
+		sectionReq.ParentSectionID,
+		cmp.Or(sectionReq.ComponentType, "lecture"),
+	).Scan(
+		&section.ID, &section.TermID, &section.SubjectID, &section.TeacherID, &section.SectionCode,
+		&section.ParentSectionID, &section.ComponentType, &section.MaxEnrollment, &section.CurrentEnrollment,
+		&section.MaxWaitlist, &section.CreatedAt, &section.UpdatedAt,
+	)
+	if err != nil {
+		return section, err
+	}
+
+	meetings, err := insertMeetings(ctx, tx, section.ID, sectionMeetings(sectionReq))
+	if err != nil {
+		return section, err
+	}
+
+	setMeetings(&section, meetings)
+
+	return section, nil
+}
+
+// insertMeetings adds the meetings of a section within the given transaction, one section_meetings row
+// per meeting day, and returns the stored meetings. Term and teacher are copied from the section row.
+func insertMeetings(ctx context.Context, tx pgx.Tx, sectionID int, meetings []schema.Meeting) ([]schema.Meeting, error) {
+	for _, meeting := range meetings {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO section_meetings (section_id, term_id, teacher_id, classroom_id, day, start_time, duration_minutes)
+			SELECT s.id, s.term_id, s.teacher_id, $2, day, $3, $4
+			FROM sections s
+			CROSS JOIN UNNEST($5::text[]::day_of_week[]) AS day
+			WHERE s.id = $1
+		`, sectionID, meeting.ClassroomID, meeting.StartTime, meeting.DurationMinutes, meeting.Days)
 		if err != nil {
-			utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to add section day: %v", err))
+			return nil, err
+		}
+	}
+
+	var stored []schema.Meeting
+
+	err := tx.QueryRow(ctx, `SELECT `+meetingsColumn+` FROM sections s WHERE s.id = $1`, sectionID).Scan(&stored)
+
+	return stored, err
+}
+
+// fetchSection loads a single section with its meetings by ID.
+func (h *Handlers) fetchSection(ctx context.Context, id int) (schema.Section, error) {
+	query := `SELECT` + sectionColumns + ` FROM sections s WHERE s.id = $1`
+
+	return scanSection(h.db.QueryRow(ctx, query, id))
+}
+
+// scanSection scans a row selected with sectionColumns into a section; extra receives the columns that follow.
+func scanSection(row pgx.Row, extra ...any) (schema.Section, error) {
+	var (
+		section  schema.Section
+		meetings []schema.Meeting
+	)
+
+	dest := []any{
+		&section.ID, &section.TermID, &section.SubjectID, &section.TeacherID, &section.SectionCode,
+		&section.ParentSectionID, &section.ComponentType, &section.MaxEnrollment, &section.CurrentEnrollment,
+		&section.MaxWaitlist, &section.CreatedAt, &section.UpdatedAt, &meetings,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+
+	setMeetings(&section, meetings)
+
+	return section, err
+}
+
+// setMeetings sets the meetings of a section and, for single-meeting sections, the meeting shorthand fields.
+func setMeetings(section *schema.Section, meetings []schema.Meeting) {
+	section.Meetings = meetings
+	section.StartTime, section.Days, section.ClassroomID, section.DurationMinutes = "", nil, 0, 0
+
+	if len(meetings) == 1 {
+		section.StartTime = meetings[0].StartTime
+		section.Days = meetings[0].Days
+		section.ClassroomID = meetings[0].ClassroomID
+		section.DurationMinutes = meetings[0].DurationMinutes
+	}
+}
+
+// sectionMeetings returns the meetings of a section request: its meetings list, or the single meeting
+// given by the start_time, duration_minutes, days and classroom_id fields.
+func sectionMeetings(sectionReq schema.CreateSectionRequest) []schema.Meeting {
+	if len(sectionReq.Meetings) > 0 {
+		return sectionReq.Meetings
+	}
+
+	return []schema.Meeting{{
+		StartTime:       sectionReq.StartTime,
+		Days:            sectionReq.Days,
+		ClassroomID:     sectionReq.ClassroomID,
+		DurationMinutes: sectionReq.DurationMinutes,
+	}}
+}
+
+// componentTypes lists the types of linked sections; labs and recitations are components of a lecture.
+var componentTypes = []string{"lecture", "lab", "recitation"}
+
+// validateSection checks the fields of a section create or replace request,
+// including its component type and whether its meetings keep to the scheduling policy.
+// Returns the invalid fields, or nil when the request is valid.
+func validateSection(sectionReq schema.CreateSectionRequest, policy schedulingPolicy) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(sectionReq.TermID == nil, "term_id", "Term ID is required")
+	errs.Required(sectionReq.SubjectID <= 0, "subject_id", "Subject ID is required")
+	errs.Required(sectionReq.TeacherID <= 0, "teacher_id", "Teacher ID is required")
+	errs.Required(sectionReq.SectionCode == "", "section_code", "Section code is required")
+	errs.Required(sectionReq.MaxEnrollment <= 0, "max_enrollment", "Max enrollment is required")
+
+	if sectionReq.MaxWaitlist < 0 {
+		errs.Add("max_waitlist", "invalid", "Max waitlist cannot be negative")
+	}
+
+	switch componentType := cmp.Or(sectionReq.ComponentType, "lecture"); {
+	case !slices.Contains(componentTypes, componentType):
+		errs.Add("component_type", "invalid", "Component type must be lecture, lab or recitation")
+	case componentType == "lecture" && sectionReq.ParentSectionID != nil:
+		errs.Add("parent_section_id", "invalid", "Lectures cannot have a parent section")
+	case componentType != "lecture":
+		errs.Required(sectionReq.ParentSectionID == nil, "parent_section_id", "Parent section ID is required for labs and recitations")
+	}
+
+	if len(sectionReq.Meetings) == 0 {
+		errs = append(errs, validateMeeting(sectionMeetings(sectionReq)[0], policy)...)
+
+		return errs
+	}
+
+	if sectionReq.StartTime != "" || len(sectionReq.Days) > 0 || sectionReq.ClassroomID != 0 || sectionReq.DurationMinutes != 0 {
+		errs.Add("meetings", "invalid", "Meetings cannot be combined with start_time, duration_minutes, days or classroom_id")
+	}
+
+	slots := make([]solver.Slot, len(sectionReq.Meetings))
+
+	for i, meeting := range sectionReq.Meetings {
+		path := fmt.Sprintf("meetings[%d]", i)
+
+		meetingErrs := validateMeeting(meeting, policy)
+		errs.Nest(path, meetingErrs)
+
+		if len(meetingErrs) > 0 {
+			continue
+		}
+
+		start, err := solver.ParseClock(meeting.StartTime)
+		if err != nil {
+			continue
+		}
+
+		slots[i] = solver.Slot{Days: meeting.Days, Start: start, End: start + meeting.DurationMinutes}
+
+		if slices.ContainsFunc(slots[:i], slots[i].Overlaps) {
+			errs.Add(path, "invalid", "Meetings of a section cannot overlap")
+		}
+	}
+
+	return errs
+}
+
+// validateMeeting checks the start time, duration, days and classroom of a section meeting
+// against the days, durations and daily window of the scheduling policy.
+// Field names are relative to the meeting.
+func validateMeeting(meeting schema.Meeting, policy schedulingPolicy) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(meeting.ClassroomID <= 0, "classroom_id", "Classroom ID is required")
+	errs.Required(meeting.StartTime == "", "start_time", "Start time is required")
+	errs.Required(meeting.DurationMinutes <= 0, "duration_minutes", "Duration minutes is required")
+	errs.Required(len(meeting.Days) == 0, "days", "Days are required")
+
+	if meeting.DurationMinutes > 0 && !slices.Contains(policy.Durations, meeting.DurationMinutes) {
+		errs.Add("duration_minutes", "invalid", "Duration minutes must be one of "+policy.durationList())
+	}
+
+	if meeting.StartTime != "" {
+		start, err := solver.ParseClock(meeting.StartTime)
+
+		switch {
+		case err != nil:
+			errs.Add("start_time", "invalid", "Start time must be a time of day, e.g. 09:00")
+		case start < policy.dayStart || start+meeting.DurationMinutes > policy.dayEnd:
+			errs.Add("start_time", "invalid", "Meetings must be held within "+policy.window())
+		}
+	}
+
+	for i, day := range meeting.Days {
+		if !slices.Contains(policy.Days, day) {
+			errs.Add("days", "invalid", "Days must be one of "+strings.Join(policy.Days, ", "))
+
+			break
+		}
+
+		if slices.Contains(meeting.Days[:i], day) {
+			errs.Add("days", "invalid", "Days must be unique")
+
+			break
+		}
+	}
+
+	return errs
+}
+
+// sendSectionError maps a database error raised while writing a section to an HTTP error response.
+// Teacher and classroom double-booking is reported as 409 Conflict listing the sections that occupy the requested time,
+// and moving a section onto a time an enrolled student already attends as 409 Conflict;
+// sectionID identifies the section being updated and is nil for new sections.
+func (h *Handlers) sendSectionError(
+	w http.ResponseWriter, r *http.Request, err error,
+	sectionReq schema.CreateSectionRequest, sectionID *int, action string,
+) {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to %s section: %v", action, err))
+
+		return
+	}
+
+	switch pgErr.Code {
+	case "23505": // Unique violation
+		utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A section with this subject and section code already exists in this term")
+	case "23503": // Foreign key violation
+		utils.SendErrorCode(w, http.StatusBadRequest, "reference_not_found", "Referenced term, subject, teacher, or classroom does not exist")
+	case "23514": // Check constraint violation
+		if pgErr.ConstraintName == "sections_max_enrollment_capacity" {
+			var detail struct {
+				MaxEnrollment int `json:"max_enrollment"`
+				Capacity      int `json:"capacity"`
+			}
+
+			msg := "Max enrollment exceeds classroom capacity"
+			if err := json.Unmarshal([]byte(pgErr.Detail), &detail); err == nil {
+				msg = fmt.Sprintf("Max enrollment %d exceeds classroom capacity %d", detail.MaxEnrollment, detail.Capacity)
+			}
+
+			utils.SendErrorCode(w, http.StatusBadRequest, "classroom_capacity_exceeded", msg)
+
+			return
+		}
+
+		if pgErr.ConstraintName == "sections_parent_section_link" {
+			utils.SendErrorCode(w, http.StatusBadRequest, "invalid_parent_section",
+				"Labs and recitations must belong to a lecture of the same subject and term, "+
+					"and lectures with components cannot change subject or term")
+
+			return
+		}
+
+		if pgErr.ConstraintName == "section_meetings_scheduling_policy" {
+			utils.SendErrorCode(w, http.StatusBadRequest, "scheduling_policy_violation",
+				"Section meetings must keep to the days, durations and daily window of the scheduling policy")
+
+			return
+		}
+
+		utils.SendErrorCode(w, http.StatusBadRequest, "constraint_violation",
+			"Section details violate constraints. Check time limits, duration, and enrollment limits.")
+	case "23P01": // Exclusion violation
+		switch pgErr.ConstraintName {
+		case "sections_teacher_schedule_conflict":
+			teacherID := func(schema.Meeting) int { return sectionReq.TeacherID }
+			h.sendScheduleConflict(w, r, "find_teacher_conflicts", teacherID, sectionReq, sectionID,
+				"teacher_schedule_conflict", "Teacher is already teaching")
+
+			return
+		case "sections_classroom_schedule_conflict":
+			classroomID := func(meeting schema.Meeting) int { return meeting.ClassroomID }
+			h.sendScheduleConflict(w, r, "find_classroom_conflicts", classroomID, sectionReq, sectionID,
+				"classroom_schedule_conflict", "Classroom is already occupied by")
+
+			return
+		case "student_meetings_schedule_conflict":
+			utils.SendErrorCode(w, http.StatusConflict, "student_schedule_conflict",
+				"An enrolled student already attends another section at this time")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusConflict, "Database error: "+pgErr.Message)
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "Database error: "+pgErr.Message)
+	}
+}
+
+// sendScheduleConflict responds with 409 Conflict naming the sections returned by the given
+// conflict lookup function (e.g. find_teacher_conflicts) for the requested meetings;
+// resourceID returns the ID of the teacher or classroom a meeting is checked for.
+// code is the problem code of the response, e.g. "teacher_schedule_conflict".
+func (h *Handlers) sendScheduleConflict(
+	w http.ResponseWriter, r *http.Request, lookup string, resourceID func(schema.Meeting) int,
+	sectionReq schema.CreateSectionRequest, sectionID *int, code, prefix string,
+) {
+	var conflictingIDs []int
+
+	for _, meeting := range sectionMeetings(sectionReq) {
+		query := fmt.Sprintf(`SELECT %s($1, $2, $3::text[]::day_of_week[], $4::text::time, $5, $6)`, lookup)
+
+		rows, err := h.db.Query(
+			r.Context(),
+			query,
+			resourceID(meeting),
+			sectionReq.TermID,
+			meeting.Days,
+			meeting.StartTime,
+			meeting.DurationMinutes,
+			sectionID,
+		)
+		if err != nil {
+			utils.SendErrorCode(w, http.StatusConflict, code, prefix+" another section at this time")
+
+			return
+		}
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			utils.SendErrorCode(w, http.StatusConflict, code, prefix+" another section at this time")
 
 			return
 		}
+
+		conflictingIDs = append(conflictingIDs, ids...)
 	}
 
-	if err := tx.Commit(r.Context()); err != nil {
-		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+	conflicts, err := h.findSectionConflicts(r.Context(), `SELECT UNNEST($1::int[])`, conflictingIDs)
+	if err != nil {
+		utils.SendErrorCode(w, http.StatusConflict, code, prefix+" another section at this time")
 
 		return
 	}
 
-	section.Days = sectionReq.Days
+	message := prefix + " another section at this time"
 
-	utils.SendJSON(w, http.StatusCreated, section)
+	if len(conflicts) > 0 {
+		message = fmt.Sprintf("%s %s at this time", prefix, strings.Join(conflictNames(conflicts), ", "))
+	}
+
+	utils.SendProblem(w, ConflictResponse{
+		Problem:   utils.NewProblem(http.StatusConflict, code, message),
+		Conflicts: conflicts,
+	})
+}
+
+// findSectionConflicts lists every meeting of the sections whose IDs are returned by the idsQuery subquery.
+func (h *Handlers) findSectionConflicts(ctx context.Context, idsQuery string, args ...any) ([]schema.SectionConflict, error) {
+	query := `
+		SELECT s.id, sub.code, s.section_code, m.start_time::text, m.end_time::text, m.days
+		FROM sections s
+		JOIN subjects sub ON s.subject_id = sub.id
+		JOIN section_meeting_view m ON s.id = m.section_id
+		WHERE s.id IN (` + idsQuery + `)
+		ORDER BY s.id, m.days[1], m.start_time
+	`
+
+	rows, err := h.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (schema.SectionConflict, error) {
+		var (
+			conflict schema.SectionConflict
+			days     pq.StringArray
+		)
+
+		err := row.Scan(
+			&conflict.SectionID, &conflict.SubjectCode, &conflict.SectionCode,
+			&conflict.StartTime, &conflict.EndTime, &days,
+		)
+		conflict.Days = []string(days)
+
+		return conflict, err
+	})
+}
+
+// conflictNames returns the distinct subject and section codes of the conflicts, e.g. "CS101-001".
+func conflictNames(conflicts []schema.SectionConflict) []string {
+	names := make([]string, len(conflicts))
+
+	for i, conflict := range conflicts {
+		names[i] = fmt.Sprintf("%s-%s", conflict.SubjectCode, conflict.SectionCode)
+	}
+
+	// Meetings of the same section are adjacent
+	return slices.Compact(names)
 }
@@ -0,0 +1,17 @@
+package handlers
+
+// SQLSTATEs raised by the enrollment and waitlist trigger functions
+// (see migrations/0005_scheduling_rules.up.sql, migrations/0012_linked_sections.up.sql
+// and migrations/0016_linked_section_waitlists.up.sql).
+// Handlers match on these codes rather than on the wording of the error message.
+const (
+	sqlStateRegistrationClosed  = "SC001"
+	sqlStateRequirementsNotMet  = "SC002"
+	sqlStateScheduleConflict    = "SC003"
+	sqlStateSectionFull         = "SC004"
+	sqlStateAlreadyEnrolled     = "SC005"
+	sqlStateSectionHasOpenSeats = "SC006"
+	sqlStateWaitlistFull        = "SC007"
+	sqlStateInvalidCombination  = "SC008"
+	sqlStateLinkedWaitlist      = "SC009"
+)
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,16 +19,37 @@ import (
 	"code.local/internal/pkg/utils"
 )
 
-// GetStudents handles HTTP GET requests to retrieve all student records.
-// Returns a list of all students from the database ordered by last name, then first name.
+// studentList describes the filters and sort orders accepted by GetStudents.
+var studentList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "student_id": "text", "first_name": "text", "last_name": "text",
+		"email": "text", "created_at": "timestamptz",
+	},
+	defaultSort: "last_name,first_name",
+	filters: []filter{
+		{param: "student_id", cond: "student_id = %s::text", parse: textParam},
+		{param: "email", cond: "lower(email) = lower(%s::text)", parse: textParam},
+		{param: "name", cond: "(first_name || ' ' || last_name) ILIKE '%%' || %s::text || '%%'", parse: textParam},
+	},
+}
+
+// GetStudents handles HTTP GET requests to retrieve a page of student records.
+// Supports the student_id, email and name filters, sorting and cursor pagination;
+// results are ordered by last name, then first name unless a sort parameter is given.
 func (h *Handlers) GetStudents(w http.ResponseWriter, r *http.Request) {
-	query := `
+	list, msg := parseList(r, studentList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
 		SELECT id, student_id, first_name, last_name, email, created_at, updated_at
 		FROM students
-		ORDER BY last_name, first_name
-	`
+	`)
 
-	rows, err := h.db.Query(r.Context(), query)
+	rows, err := h.db.Query(r.Context(), query, list.args...)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch students")
 
@@ -38,11 +60,14 @@ func (h *Handlers) GetStudents(w http.ResponseWriter, r *http.Request) {
 	var students []schema.Student
 
 	for rows.Next() {
-		var student schema.Student
+		var (
+			student schema.Student
+			key     []string
+		)
 
 		err := rows.Scan(
 			&student.ID, &student.StudentID, &student.FirstName,
-			&student.LastName, &student.Email, &student.CreatedAt, &student.UpdatedAt,
+			&student.LastName, &student.Email, &student.CreatedAt, &student.UpdatedAt, &key,
 		)
 		if err != nil {
 			utils.SendError(w, http.StatusInternalServerError, "Failed to scan student")
@@ -50,10 +75,11 @@ func (h *Handlers) GetStudents(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		list.add(student.ID, key)
 		students = append(students, student)
 	}
 
-	utils.SendJSON(w, http.StatusOK, students)
+	utils.SendJSON(w, http.StatusOK, students[:list.paginate(w, r)])
 }
 
 // GetStudentByID handles HTTP GET requests to retrieve a specific student by ID.
@@ -68,18 +94,7 @@ func (h *Handlers) GetStudentByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var student schema.Student
-
-	query := `
-		SELECT id, student_id, first_name, last_name, email, created_at, updated_at
-		FROM students
-		WHERE id = $1
-	`
-
-	err = h.db.QueryRow(r.Context(), query, id).Scan(
-		&student.ID, &student.StudentID, &student.FirstName,
-		&student.LastName, &student.Email, &student.CreatedAt, &student.UpdatedAt,
-	)
+	student, err := h.fetchStudent(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			utils.SendError(w, http.StatusNotFound, "Student not found")
@@ -96,7 +111,8 @@ func (h *Handlers) GetStudentByID(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetStudentSchedule handles HTTP GET requests to retrieve a student's course schedule.
-// Accepts a student ID path parameter and returns all courses the student is enrolled in.
+// Accepts a student ID path parameter and returns the meetings of all courses the student is enrolled in,
+// optionally limited to a single term via the term_id query parameter.
 func (h *Handlers) GetStudentSchedule(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 
@@ -107,17 +123,24 @@ func (h *Handlers) GetStudentSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	termID, err := optionalIntQuery(r, "term_id")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
 	query := `
 		SELECT
-			section_id, subject_code, subject_name, section_code,
+			section_id, term_id, subject_code, subject_name, section_code,
 			teacher_first_name, teacher_last_name, building, room_number,
 			start_time::text, end_time::text, duration_minutes, days
 		FROM student_schedule_view
-		WHERE student_id = $1
-		ORDER BY subject_code, section_code
+		WHERE student_id = $1 AND ($2::int IS NULL OR term_id = $2)
+		ORDER BY subject_code, section_code, section_id, days[1], start_time
 	`
 
-	rows, err := h.db.Query(r.Context(), query, id)
+	rows, err := h.db.Query(r.Context(), query, id, termID)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch schedule")
 
@@ -134,7 +157,7 @@ func (h *Handlers) GetStudentSchedule(w http.ResponseWriter, r *http.Request) {
 		)
 
 		err := rows.Scan(
-			&item.SectionID, &item.SubjectCode, &item.SubjectName, &item.SectionCode,
+			&item.SectionID, &item.TermID, &item.SubjectCode, &item.SubjectName, &item.SectionCode,
 			&item.TeacherFirstName, &item.TeacherLastName, &item.Building, &item.RoomNumber,
 			&item.StartTime, &item.EndTime, &item.DurationMinutes, &days,
 		)
@@ -163,8 +186,8 @@ func (h *Handlers) CreateStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if studentReq.StudentID == "" || studentReq.FirstName == "" || studentReq.LastName == "" || studentReq.Email == "" {
-		utils.SendError(w, http.StatusBadRequest, "All fields are required")
+	if errs := validateStudent(studentReq); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
 
 		return
 	}
@@ -192,13 +215,7 @@ func (h *Handlers) CreateStudent(w http.ResponseWriter, r *http.Request) {
 		var pgErr *pgconn.PgError
 
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			if pgErr.ConstraintName == "students_student_id_key" {
-				utils.SendError(w, http.StatusConflict, "A student with this student ID already exists")
-			} else if pgErr.ConstraintName == "students_email_key" {
-				utils.SendError(w, http.StatusConflict, "A student with this email already exists")
-			} else {
-				utils.SendError(w, http.StatusConflict, "A duplicate entry exists")
-			}
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", studentDuplicateMessage(pgErr))
 
 			return
 		}
@@ -211,9 +228,129 @@ func (h *Handlers) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSON(w, http.StatusCreated, student)
 }
 
+// UpdateStudent handles HTTP PUT requests to replace a student record.
+// All fields are required, as with CreateStudent.
+func (h *Handlers) UpdateStudent(w http.ResponseWriter, r *http.Request) {
+	h.updateStudent(w, r, false)
+}
+
+// PatchStudent handles HTTP PATCH requests to partially update a student record.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchStudent(w http.ResponseWriter, r *http.Request) {
+	h.updateStudent(w, r, true)
+}
+
+// DeleteStudent handles HTTP DELETE requests to remove a student record.
+// Refuses with a conflict while the student is still enrolled in sections unless ?cascade=true is given,
+// in which case the student's enrollments are deleted as well.
+func (h *Handlers) DeleteStudent(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Student",
+		query:  `DELETE FROM students WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE student_id = $1`,
+		},
+		dependents: "enrollments",
+	})
+}
+
+// updateStudent applies a full (PUT) or partial (PATCH) update to a student record.
+// For partial updates the request body is decoded on top of the stored record.
+func (h *Handlers) updateStudent(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	var studentReq schema.CreateStudentRequest
+
+	if partial {
+		student, err := h.fetchStudent(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Student not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch student")
+
+			return
+		}
+
+		studentReq = schema.CreateStudentRequest{
+			StudentID: student.StudentID,
+			FirstName: student.FirstName,
+			LastName:  student.LastName,
+			Email:     student.Email,
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&studentReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateStudent(studentReq); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	var student schema.Student
+
+	query := `
+		UPDATE students
+		SET student_id = $2, first_name = $3, last_name = $4, email = $5
+		WHERE id = $1
+		RETURNING id, student_id, first_name, last_name, email, created_at, updated_at
+	`
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		id,
+		studentReq.StudentID,
+		studentReq.FirstName,
+		studentReq.LastName,
+		studentReq.Email,
+	).Scan(
+		&student.ID, &student.StudentID, &student.FirstName,
+		&student.LastName, &student.Email, &student.CreatedAt, &student.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Student not found")
+
+			return
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", studentDuplicateMessage(pgErr))
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update student: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, student)
+}
+
 // DropSection handles HTTP DELETE requests to remove a student from a section.
 // Accepts student ID and section ID path parameters, removes the enrollment if it exists,
-// and returns a success message or a not found error.
+// and offers the freed seat to the waitlist within the same transaction.
+// Dropping a lecture also drops the student's linked components; a component alone cannot be dropped.
+// Returns a success message with any promoted students or a not found error.
 func (h *Handlers) DropSection(w http.ResponseWriter, r *http.Request) {
 	studentIDStr := r.PathValue("student_id")
 	sectionIDStr := r.PathValue("section_id")
@@ -232,12 +369,40 @@ func (h *Handlers) DropSection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	rows, err := tx.Query(r.Context(), `
+		SELECT e.section_id
+		FROM enrollments e
+		JOIN sections s ON s.id = e.section_id
+		WHERE e.student_id = $1 AND s.parent_section_id = $2
+		ORDER BY e.section_id
+	`, studentID, sectionID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch linked components")
+
+		return
+	}
+
+	components, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch linked components")
+
+		return
+	}
+
 	query := `
 		DELETE FROM enrollments 
 		WHERE student_id = $1 AND section_id = $2
 	`
 
-	result, err := h.db.Exec(r.Context(), query, studentID, sectionID)
+	result, err := tx.Exec(r.Context(), query, studentID, sectionID)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to drop section")
 
@@ -250,12 +415,43 @@ func (h *Handlers) DropSection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Section dropped successfully"})
+	// A lab or recitation cannot be dropped on its own while the student keeps its lecture
+	if err := checkSectionCombinations(r.Context(), tx); err != nil {
+		if problem := enrollmentFailure(err); problem.Code == "invalid_section_combination" {
+			sendInvalidCombination(w, err, problem)
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to drop section: %v", err))
+
+		return
+	}
+
+	promoted, err := promoteFromWaitlist(r.Context(), tx, sectionID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to promote waitlisted students: %v", err))
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, DropResponse{
+		Message:             "Section dropped successfully",
+		DroppedComponentIDs: components,
+		PromotedStudentIDs:  promoted,
+	})
 }
 
 // DownloadStudentSchedule handles HTTP GET requests to generate a PDF of a student's schedule.
-// Accepts a student ID path parameter, retrieves student and schedule data,
-// creates a formatted PDF document, and returns it as a downloadable file.
+// Accepts a student ID path parameter and an optional term_id query parameter,
+// retrieves student and schedule data, creates a formatted PDF document,
+// and returns it as a downloadable file.
 func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 
@@ -266,6 +462,13 @@ func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	termID, err := optionalIntQuery(r, "term_id")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
 	// Get student info
 	var student schema.Student
 
@@ -279,18 +482,36 @@ func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Get term name when the schedule is limited to a single term
+	var termName string
+
+	if termID != nil {
+		err = h.db.QueryRow(r.Context(), `SELECT name FROM terms WHERE id = $1`, *termID).Scan(&termName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Term not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch term info")
+
+			return
+		}
+	}
+
 	// Get schedule items
 	query := `
 		SELECT
-			section_id, subject_code, subject_name, section_code,
+			section_id, term_id, subject_code, subject_name, section_code,
 			teacher_first_name, teacher_last_name, building, room_number,
 			start_time::text, end_time::text, duration_minutes, days
 		FROM student_schedule_view
-		WHERE student_id = $1
+		WHERE student_id = $1 AND ($2::int IS NULL OR term_id = $2)
 		ORDER BY days[1], start_time
 	`
 
-	rows, err := h.db.Query(r.Context(), query, id)
+	rows, err := h.db.Query(r.Context(), query, id, termID)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch schedule")
 
@@ -307,7 +528,7 @@ func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Reques
 		)
 
 		err = rows.Scan(
-			&item.SectionID, &item.SubjectCode, &item.SubjectName, &item.SectionCode,
+			&item.SectionID, &item.TermID, &item.SubjectCode, &item.SubjectName, &item.SectionCode,
 			&item.TeacherFirstName, &item.TeacherLastName, &item.Building, &item.RoomNumber,
 			&item.StartTime, &item.EndTime, &item.DurationMinutes, &days,
 		)
@@ -330,6 +551,9 @@ func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Reques
 	pdf.SetFont("Helvetica", "B", 16)
 	pdf.SetTextColor(0, 0, 0) // Black title text
 	title := fmt.Sprintf("Schedule for %s %s (%s)", student.FirstName, student.LastName, student.StudentID)
+	if termName != "" {
+		title += " - " + termName
+	}
 	pdf.CellFormat(0, 10, title, "", 1, "C", false, 0, "")
 	pdf.Ln(5)
 
@@ -408,3 +632,46 @@ func (h *Handlers) DownloadStudentSchedule(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 	w.Write(buf.Bytes())
 }
+
+// fetchStudent loads a single student record by ID.
+func (h *Handlers) fetchStudent(ctx context.Context, id int) (schema.Student, error) {
+	var student schema.Student
+
+	query := `
+		SELECT id, student_id, first_name, last_name, email, created_at, updated_at
+		FROM students
+		WHERE id = $1
+	`
+
+	err := h.db.QueryRow(ctx, query, id).Scan(
+		&student.ID, &student.StudentID, &student.FirstName,
+		&student.LastName, &student.Email, &student.CreatedAt, &student.UpdatedAt,
+	)
+
+	return student, err
+}
+
+// validateStudent checks the fields required for creating or replacing a student.
+// Returns the invalid fields, or nil when the student is valid.
+func validateStudent(studentReq schema.CreateStudentRequest) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(studentReq.StudentID == "", "student_id", "Student ID is required")
+	errs.Required(studentReq.FirstName == "", "first_name", "First name is required")
+	errs.Required(studentReq.LastName == "", "last_name", "Last name is required")
+	errs.Required(studentReq.Email == "", "email", "Email is required")
+
+	return errs
+}
+
+// studentDuplicateMessage describes which unique constraint on students was violated.
+func studentDuplicateMessage(pgErr *pgconn.PgError) string {
+	switch pgErr.ConstraintName {
+	case "students_student_id_key":
+		return "A student with this student ID already exists"
+	case "students_email_key":
+		return "A student with this email already exists"
+	default:
+		return "A duplicate entry exists"
+	}
+}
@@ -1,24 +1,48 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
 
 	"code.local/internal/pkg/schema"
 	"code.local/internal/pkg/utils"
 )
 
-// GetSubjects handles HTTP GET requests to retrieve all academic subjects.
-// Returns a list of all subject records from the database ordered by subject code.
+// subjectList describes the filters and sort orders accepted by GetSubjects.
+var subjectList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "code": "text", "name": "text", "created_at": "timestamptz",
+	},
+	defaultSort: "code",
+	filters: []filter{
+		{param: "code", cond: "code ILIKE %s::text || '%%'", parse: textParam},
+		{param: "name", cond: "name ILIKE '%%' || %s::text || '%%'", parse: textParam},
+	},
+}
+
+// GetSubjects handles HTTP GET requests to retrieve a page of academic subjects.
+// Supports the code prefix and name filters, sorting and cursor pagination;
+// results are ordered by subject code unless a sort parameter is given.
 func (h *Handlers) GetSubjects(w http.ResponseWriter, r *http.Request) {
-	query := `
+	list, msg := parseList(r, subjectList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
 		SELECT id, code, name, description, created_at, updated_at
 		FROM subjects
-		ORDER BY code
-	`
+	`)
 
-	rows, err := h.db.Query(r.Context(), query)
+	rows, err := h.db.Query(r.Context(), query, list.args...)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch subjects")
 
@@ -29,11 +53,14 @@ func (h *Handlers) GetSubjects(w http.ResponseWriter, r *http.Request) {
 	var subjects []schema.Subject
 
 	for rows.Next() {
-		var subject schema.Subject
+		var (
+			subject schema.Subject
+			key     []string
+		)
 
 		err := rows.Scan(
 			&subject.ID, &subject.Code, &subject.Name,
-			&subject.Description, &subject.CreatedAt, &subject.UpdatedAt,
+			&subject.Description, &subject.CreatedAt, &subject.UpdatedAt, &key,
 		)
 		if err != nil {
 			utils.SendError(w, http.StatusInternalServerError, "Failed to scan subject")
@@ -41,10 +68,11 @@ func (h *Handlers) GetSubjects(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		list.add(subject.ID, key)
 		subjects = append(subjects, subject)
 	}
 
-	utils.SendJSON(w, http.StatusOK, subjects)
+	utils.SendJSON(w, http.StatusOK, subjects[:list.paginate(w, r)])
 }
 
 // CreateSubject handles HTTP POST requests to create a new academic subject.
@@ -59,8 +87,8 @@ func (h *Handlers) CreateSubject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if subject.Code == "" || subject.Name == "" {
-		utils.SendError(w, http.StatusBadRequest, "Code and name are required")
+	if errs := validateSubject(subject); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
 
 		return
 	}
@@ -86,3 +114,161 @@ func (h *Handlers) CreateSubject(w http.ResponseWriter, r *http.Request) {
 
 	utils.SendJSON(w, http.StatusCreated, subject)
 }
+
+// GetSubjectByID handles HTTP GET requests to retrieve a specific subject by ID.
+// Accepts a subject ID path parameter and returns the matching subject record or a not found error.
+func (h *Handlers) GetSubjectByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid subject ID")
+
+		return
+	}
+
+	subject, err := h.fetchSubject(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Subject not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch subject")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, subject)
+}
+
+// UpdateSubject handles HTTP PUT requests to replace a subject record.
+// Code and name are required, as with CreateSubject.
+func (h *Handlers) UpdateSubject(w http.ResponseWriter, r *http.Request) {
+	h.updateSubject(w, r, false)
+}
+
+// PatchSubject handles HTTP PATCH requests to partially update a subject record.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchSubject(w http.ResponseWriter, r *http.Request) {
+	h.updateSubject(w, r, true)
+}
+
+// DeleteSubject handles HTTP DELETE requests to remove a subject record.
+// Refuses with a conflict while sections still reference the subject unless ?cascade=true is given,
+// in which case the subject's sections and their enrollments are deleted as well.
+func (h *Handlers) DeleteSubject(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Subject",
+		query:  `DELETE FROM subjects WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE section_id IN (SELECT id FROM sections WHERE subject_id = $1)`,
+			`DELETE FROM sections WHERE subject_id = $1`,
+		},
+		dependents: "sections",
+	})
+}
+
+// updateSubject applies a full (PUT) or partial (PATCH) update to a subject record.
+// For partial updates the request body is decoded on top of the stored record.
+func (h *Handlers) updateSubject(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid subject ID")
+
+		return
+	}
+
+	var subject schema.Subject
+
+	if partial {
+		subject, err = h.fetchSubject(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Subject not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch subject")
+
+			return
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateSubject(subject); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	query := `
+		UPDATE subjects
+		SET code = $2, name = $3, description = $4
+		WHERE id = $1
+		RETURNING id, code, name, description, created_at, updated_at
+	`
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		id,
+		subject.Code,
+		subject.Name,
+		subject.Description,
+	).Scan(
+		&subject.ID, &subject.Code, &subject.Name,
+		&subject.Description, &subject.CreatedAt, &subject.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Subject not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update subject: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, subject)
+}
+
+// fetchSubject loads a single subject record by ID.
+func (h *Handlers) fetchSubject(ctx context.Context, id int) (schema.Subject, error) {
+	var subject schema.Subject
+
+	query := `
+		SELECT id, code, name, description, created_at, updated_at
+		FROM subjects
+		WHERE id = $1
+	`
+
+	err := h.db.QueryRow(ctx, query, id).Scan(
+		&subject.ID, &subject.Code, &subject.Name,
+		&subject.Description, &subject.CreatedAt, &subject.UpdatedAt,
+	)
+
+	return subject, err
+}
+
+// validateSubject checks the fields required for creating or replacing a subject.
+// Returns the invalid fields, or nil when the subject is valid.
+func validateSubject(subject schema.Subject) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(subject.Code == "", "code", "Code is required")
+	errs.Required(subject.Name == "", "name", "Name is required")
+
+	return errs
+}
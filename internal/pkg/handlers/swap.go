@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// SwapSection handles HTTP POST requests to move a student from one section to another in a single transaction.
+// The student is dropped from the old section before enrolling in the new one, so the schedule conflict check
+// ignores the section being dropped. If the new section is full, conflicts with another enrollment,
+// is closed for registration or its requirements are not met, nothing is changed and the reason is returned.
+// A lab or recitation may be swapped for another component of the same lecture. Dropping a lecture drops its
+// components, and a lecture with components cannot be swapped into, as it must be enrolled together with them.
+// On success the freed seat is offered to the old section's waitlist and the new enrollment is returned.
+func (h *Handlers) SwapSection(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	var swapReq SwapRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&swapReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	var errs utils.FieldErrors
+
+	errs.Required(swapReq.DropSectionID <= 0, "drop_section_id", "Drop section ID is required")
+	errs.Required(swapReq.EnrollSectionID <= 0, "enroll_section_id", "Enroll section ID is required")
+
+	if len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	if swapReq.DropSectionID == swapReq.EnrollSectionID {
+		utils.SendError(w, http.StatusBadRequest, "Cannot swap a section with itself")
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	result, err := tx.Exec(r.Context(), `
+		DELETE FROM enrollments
+		WHERE student_id = $1 AND section_id = $2
+	`, studentID, swapReq.DropSectionID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to drop section")
+
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		utils.SendError(w, http.StatusNotFound, "Enrollment not found")
+
+		return
+	}
+
+	enrollment, err := insertEnrollment(r.Context(), tx, studentID, swapReq.EnrollSectionID)
+	if err == nil {
+		err = checkSectionCombinations(r.Context(), tx)
+	}
+
+	if err != nil {
+		// The drop is undone before the reason is looked up, so lookups see the unchanged enrollments
+		tx.Rollback(r.Context())
+		h.sendEnrollmentFailure(w, r, err, studentID, swapReq.EnrollSectionID, swapReq.DropSectionID)
+
+		return
+	}
+
+	promoted, err := promoteFromWaitlist(r.Context(), tx, swapReq.DropSectionID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to promote waitlisted students: %v", err))
+
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, SwapResponse{
+		Enrollment:         enrollment,
+		PromotedStudentIDs: promoted,
+	})
+}
+
+// sendEnrollmentConflict responds with 409 Conflict naming the student's enrolled sections
+// that overlap the requested section; excludeSectionID is left out of the comparison (0 excludes none).
+func (h *Handlers) sendEnrollmentConflict(w http.ResponseWriter, r *http.Request, studentID, sectionID, excludeSectionID int) {
+	conflicts, err := h.findEnrollmentConflicts(r.Context(), studentID, sectionID, excludeSectionID)
+	if err != nil || len(conflicts) == 0 {
+		utils.SendErrorCode(w, http.StatusConflict, "schedule_conflict", "Schedule conflict detected")
+
+		return
+	}
+
+	utils.SendProblem(w, ConflictResponse{
+		Problem: utils.NewProblem(http.StatusConflict, "schedule_conflict",
+			"Schedule conflict detected with "+strings.Join(conflictNames(conflicts), ", ")),
+		Conflicts: conflicts,
+	})
+}
+
+// findEnrollmentConflicts lists the meetings of the student's enrolled sections that overlap the given section,
+// as found by find_schedule_conflicts: same term, a shared day and intersecting times.
+func (h *Handlers) findEnrollmentConflicts(
+	ctx context.Context, studentID, sectionID, excludeSectionID int,
+) ([]schema.SectionConflict, error) {
+	return h.findSectionConflicts(ctx, `SELECT find_schedule_conflicts($1, $2, $3)`, studentID, sectionID, excludeSectionID)
+}
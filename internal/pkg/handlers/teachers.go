@@ -1,27 +1,49 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
 	"code.local/internal/pkg/schema"
 	"code.local/internal/pkg/utils"
 )
 
-// GetTeachers handles HTTP GET requests to retrieve all teacher records.
-// Returns a list of all teacher data from the database ordered by last name, then first name.
+// teacherList describes the filters and sort orders accepted by GetTeachers.
+var teacherList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "first_name": "text", "last_name": "text", "email": "text", "created_at": "timestamptz",
+	},
+	defaultSort: "last_name,first_name",
+	filters: []filter{
+		{param: "email", cond: "lower(email) = lower(%s::text)", parse: textParam},
+		{param: "name", cond: "(first_name || ' ' || last_name) ILIKE '%%' || %s::text || '%%'", parse: textParam},
+	},
+}
+
+// GetTeachers handles HTTP GET requests to retrieve a page of teacher records.
+// Supports the email and name filters, sorting and cursor pagination;
+// results are ordered by last name, then first name unless a sort parameter is given.
 func (h *Handlers) GetTeachers(w http.ResponseWriter, r *http.Request) {
-	query := `
+	list, msg := parseList(r, teacherList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
 		SELECT id, first_name, last_name, email, created_at, updated_at
 		FROM teachers
-		ORDER BY last_name, first_name
-	`
+	`)
 
-	rows, err := h.db.Query(r.Context(), query)
+	rows, err := h.db.Query(r.Context(), query, list.args...)
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch teachers")
 
@@ -32,11 +54,14 @@ func (h *Handlers) GetTeachers(w http.ResponseWriter, r *http.Request) {
 	var teachers []schema.Teacher
 
 	for rows.Next() {
-		var teacher schema.Teacher
+		var (
+			teacher schema.Teacher
+			key     []string
+		)
 
 		err := rows.Scan(
 			&teacher.ID, &teacher.FirstName, &teacher.LastName,
-			&teacher.Email, &teacher.CreatedAt, &teacher.UpdatedAt,
+			&teacher.Email, &teacher.CreatedAt, &teacher.UpdatedAt, &key,
 		)
 		if err != nil {
 			utils.SendError(w, http.StatusInternalServerError, "Failed to scan teacher")
@@ -44,10 +69,11 @@ func (h *Handlers) GetTeachers(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		list.add(teacher.ID, key)
 		teachers = append(teachers, teacher)
 	}
 
-	utils.SendJSON(w, http.StatusOK, teachers)
+	utils.SendJSON(w, http.StatusOK, teachers[:list.paginate(w, r)])
 }
 
 // CreateTeacher handles HTTP POST requests to create a new teacher record.
@@ -62,8 +88,8 @@ func (h *Handlers) CreateTeacher(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if teacher.FirstName == "" || teacher.LastName == "" || teacher.Email == "" {
-		utils.SendError(w, http.StatusBadRequest, "First name, last name, and email are required")
+	if errs := validateTeacher(teacher); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
 
 		return
 	}
@@ -85,7 +111,7 @@ func (h *Handlers) CreateTeacher(w http.ResponseWriter, r *http.Request) {
 		var pgErr *pgconn.PgError
 
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			utils.SendError(w, http.StatusConflict, "A teacher with this email already exists")
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A teacher with this email already exists")
 
 			return
 		}
@@ -97,3 +123,174 @@ func (h *Handlers) CreateTeacher(w http.ResponseWriter, r *http.Request) {
 
 	utils.SendJSON(w, http.StatusCreated, teacher)
 }
+
+// GetTeacherByID handles HTTP GET requests to retrieve a specific teacher by ID.
+// Accepts a teacher ID path parameter and returns the matching teacher record or a not found error.
+func (h *Handlers) GetTeacherByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid teacher ID")
+
+		return
+	}
+
+	teacher, err := h.fetchTeacher(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Teacher not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch teacher")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, teacher)
+}
+
+// UpdateTeacher handles HTTP PUT requests to replace a teacher record.
+// All fields are required, as with CreateTeacher.
+func (h *Handlers) UpdateTeacher(w http.ResponseWriter, r *http.Request) {
+	h.updateTeacher(w, r, false)
+}
+
+// PatchTeacher handles HTTP PATCH requests to partially update a teacher record.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchTeacher(w http.ResponseWriter, r *http.Request) {
+	h.updateTeacher(w, r, true)
+}
+
+// DeleteTeacher handles HTTP DELETE requests to remove a teacher record.
+// Refuses with a conflict while sections still reference the teacher unless ?cascade=true is given,
+// in which case the teacher's sections, the components linked to them and their enrollments are deleted as well.
+func (h *Handlers) DeleteTeacher(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Teacher",
+		query:  `DELETE FROM teachers WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE section_id IN (
+				SELECT id FROM sections
+				WHERE teacher_id = $1 OR parent_section_id IN (SELECT id FROM sections WHERE teacher_id = $1)
+			)`,
+			`DELETE FROM sections WHERE parent_section_id IN (SELECT id FROM sections WHERE teacher_id = $1)`,
+			`DELETE FROM sections WHERE teacher_id = $1`,
+		},
+		dependents: "sections",
+	})
+}
+
+// updateTeacher applies a full (PUT) or partial (PATCH) update to a teacher record.
+// For partial updates the request body is decoded on top of the stored record.
+func (h *Handlers) updateTeacher(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid teacher ID")
+
+		return
+	}
+
+	var teacher schema.Teacher
+
+	if partial {
+		teacher, err = h.fetchTeacher(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Teacher not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch teacher")
+
+			return
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&teacher); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateTeacher(teacher); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	query := `
+		UPDATE teachers
+		SET first_name = $2, last_name = $3, email = $4
+		WHERE id = $1
+		RETURNING id, first_name, last_name, email, created_at, updated_at
+	`
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		id,
+		teacher.FirstName,
+		teacher.LastName,
+		teacher.Email,
+	).Scan(
+		&teacher.ID, &teacher.FirstName, &teacher.LastName,
+		&teacher.Email, &teacher.CreatedAt, &teacher.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Teacher not found")
+
+			return
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A teacher with this email already exists")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update teacher: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, teacher)
+}
+
+// fetchTeacher loads a single teacher record by ID.
+func (h *Handlers) fetchTeacher(ctx context.Context, id int) (schema.Teacher, error) {
+	var teacher schema.Teacher
+
+	query := `
+		SELECT id, first_name, last_name, email, created_at, updated_at
+		FROM teachers
+		WHERE id = $1
+	`
+
+	err := h.db.QueryRow(ctx, query, id).Scan(
+		&teacher.ID, &teacher.FirstName, &teacher.LastName,
+		&teacher.Email, &teacher.CreatedAt, &teacher.UpdatedAt,
+	)
+
+	return teacher, err
+}
+
+// validateTeacher checks the fields required for creating or replacing a teacher.
+// Returns the invalid fields, or nil when the teacher is valid.
+func validateTeacher(teacher schema.Teacher) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(teacher.FirstName == "", "first_name", "First name is required")
+	errs.Required(teacher.LastName == "", "last_name", "Last name is required")
+	errs.Required(teacher.Email == "", "email", "Email is required")
+
+	return errs
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// termList describes the filters and sort orders accepted by GetTerms.
+var termList = listSpec{
+	sorts: map[string]string{
+		"id": "int", "code": "text", "name": "text", "start_date": "date", "end_date": "date",
+		"created_at": "timestamptz",
+	},
+	defaultSort: "start_date,code",
+	filters: []filter{
+		{param: "starts_after", cond: "start_date >= %s::date", parse: dateParam},
+		{param: "ends_before", cond: "end_date <= %s::date", parse: dateParam},
+		{
+			param: "registration_open",
+			cond:  "(CURRENT_TIMESTAMP >= registration_opens_at AND CURRENT_TIMESTAMP < registration_closes_at) = %s::bool",
+			parse: boolParam,
+		},
+	},
+}
+
+// GetTerms handles HTTP GET requests to retrieve a page of academic terms.
+// Supports the starts_after, ends_before and registration_open filters, sorting and cursor pagination;
+// results are ordered by start date unless a sort parameter is given.
+func (h *Handlers) GetTerms(w http.ResponseWriter, r *http.Request) {
+	list, msg := parseList(r, termList)
+	if msg != "" {
+		utils.SendError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	query := list.query(`
+		SELECT
+			id, code, name, start_date, end_date,
+			registration_opens_at, registration_closes_at, created_at, updated_at
+		FROM terms
+	`)
+
+	rows, err := h.db.Query(r.Context(), query, list.args...)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch terms")
+
+		return
+	}
+	defer rows.Close()
+
+	var terms []schema.Term
+
+	for rows.Next() {
+		var (
+			term               schema.Term
+			startDate, endDate time.Time
+			key                []string
+		)
+
+		err := rows.Scan(
+			&term.ID, &term.Code, &term.Name, &startDate, &endDate,
+			&term.RegistrationOpensAt, &term.RegistrationClosesAt, &term.CreatedAt, &term.UpdatedAt, &key,
+		)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan term")
+
+			return
+		}
+
+		term.StartDate = startDate.Format(time.DateOnly)
+		term.EndDate = endDate.Format(time.DateOnly)
+
+		list.add(term.ID, key)
+		terms = append(terms, term)
+	}
+
+	utils.SendJSON(w, http.StatusOK, terms[:list.paginate(w, r)])
+}
+
+// CreateTerm handles HTTP POST requests to create a new academic term.
+// Validates the code, name, date range and registration window,
+// creates the new term record, and returns it with assigned ID and timestamps.
+func (h *Handlers) CreateTerm(w http.ResponseWriter, r *http.Request) {
+	var term schema.Term
+
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateTerm(term); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	query := `
+		INSERT INTO terms (code, name, start_date, end_date, registration_opens_at, registration_closes_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := h.db.QueryRow(
+		r.Context(),
+		query,
+		term.Code,
+		term.Name,
+		term.StartDate,
+		term.EndDate,
+		term.RegistrationOpensAt,
+		term.RegistrationClosesAt,
+	).Scan(&term.ID, &term.CreatedAt, &term.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A term with this code already exists")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create term: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, term)
+}
+
+// GetTermByID handles HTTP GET requests to retrieve a specific term by ID.
+// Accepts a term ID path parameter and returns the matching term record or a not found error.
+func (h *Handlers) GetTermByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
+	term, err := h.fetchTerm(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Term not found")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch term")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, term)
+}
+
+// UpdateTerm handles HTTP PUT requests to replace a term record.
+// All fields are required and validated as with CreateTerm.
+func (h *Handlers) UpdateTerm(w http.ResponseWriter, r *http.Request) {
+	h.updateTerm(w, r, false)
+}
+
+// PatchTerm handles HTTP PATCH requests to partially update a term record.
+// Only the fields present in the request body are changed.
+func (h *Handlers) PatchTerm(w http.ResponseWriter, r *http.Request) {
+	h.updateTerm(w, r, true)
+}
+
+// DeleteTerm handles HTTP DELETE requests to remove a term record.
+// Refuses with a conflict while sections are still scheduled in the term unless ?cascade=true is given,
+// in which case the term's sections and their enrollments are deleted as well.
+func (h *Handlers) DeleteTerm(w http.ResponseWriter, r *http.Request) {
+	h.deleteRecord(w, r, deletion{
+		entity: "Term",
+		query:  `DELETE FROM terms WHERE id = $1`,
+		cascade: []string{
+			`DELETE FROM enrollments WHERE section_id IN (SELECT id FROM sections WHERE term_id = $1)`,
+			`DELETE FROM sections WHERE term_id = $1`,
+		},
+		dependents: "sections",
+	})
+}
+
+// updateTerm applies a full (PUT) or partial (PATCH) update to a term record.
+// For partial updates the request body is decoded on top of the stored record.
+func (h *Handlers) updateTerm(w http.ResponseWriter, r *http.Request, partial bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return
+	}
+
+	var term schema.Term
+
+	if partial {
+		term, err = h.fetchTerm(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.SendError(w, http.StatusNotFound, "Term not found")
+
+				return
+			}
+
+			utils.SendError(w, http.StatusInternalServerError, "Failed to fetch term")
+
+			return
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if errs := validateTerm(term); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	query := `
+		UPDATE terms
+		SET code = $2, name = $3, start_date = $4, end_date = $5,
+			registration_opens_at = $6, registration_closes_at = $7
+		WHERE id = $1
+		RETURNING
+			id, code, name, start_date::text, end_date::text,
+			registration_opens_at, registration_closes_at, created_at, updated_at
+	`
+
+	err = h.db.QueryRow(
+		r.Context(),
+		query,
+		id,
+		term.Code,
+		term.Name,
+		term.StartDate,
+		term.EndDate,
+		term.RegistrationOpensAt,
+		term.RegistrationClosesAt,
+	).Scan(
+		&term.ID, &term.Code, &term.Name, &term.StartDate, &term.EndDate,
+		&term.RegistrationOpensAt, &term.RegistrationClosesAt, &term.CreatedAt, &term.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Term not found")
+
+			return
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			utils.SendErrorCode(w, http.StatusConflict, "duplicate_record", "A term with this code already exists")
+
+			return
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update term: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, term)
+}
+
+// fetchTerm loads a single term record by ID.
+func (h *Handlers) fetchTerm(ctx context.Context, id int) (schema.Term, error) {
+	var term schema.Term
+
+	query := `
+		SELECT
+			id, code, name, start_date::text, end_date::text,
+			registration_opens_at, registration_closes_at, created_at, updated_at
+		FROM terms
+		WHERE id = $1
+	`
+
+	err := h.db.QueryRow(ctx, query, id).Scan(
+		&term.ID, &term.Code, &term.Name, &term.StartDate, &term.EndDate,
+		&term.RegistrationOpensAt, &term.RegistrationClosesAt, &term.CreatedAt, &term.UpdatedAt,
+	)
+
+	return term, err
+}
+
+// validateTerm checks the fields required for creating or replacing a term,
+// including the date range and the registration window.
+// Returns the invalid fields, or nil when the term is valid.
+func validateTerm(term schema.Term) utils.FieldErrors {
+	var errs utils.FieldErrors
+
+	errs.Required(term.Code == "", "code", "Code is required")
+	errs.Required(term.Name == "", "name", "Name is required")
+	errs.Required(term.StartDate == "", "start_date", "Start date is required")
+	errs.Required(term.EndDate == "", "end_date", "End date is required")
+	errs.Required(term.RegistrationOpensAt.IsZero(), "registration_opens_at", "Registration opening time is required")
+	errs.Required(term.RegistrationClosesAt.IsZero(), "registration_closes_at", "Registration closing time is required")
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	startDate, err := time.Parse(time.DateOnly, term.StartDate)
+	if err != nil {
+		errs.Add("start_date", "invalid", "Start date must be in YYYY-MM-DD format")
+	}
+
+	endDate, err := time.Parse(time.DateOnly, term.EndDate)
+	if err != nil {
+		errs.Add("end_date", "invalid", "End date must be in YYYY-MM-DD format")
+	}
+
+	if len(errs) == 0 && !endDate.After(startDate) {
+		errs.Add("end_date", "invalid", "End date must be after start date")
+	}
+
+	if !term.RegistrationClosesAt.After(term.RegistrationOpensAt) {
+		errs.Add("registration_closes_at", "invalid", "Registration must close after it opens")
+	}
+
+	return errs
+}
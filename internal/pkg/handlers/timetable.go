@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/solver"
+	"code.local/internal/pkg/utils"
+)
+
+// SolveTimetable handles HTTP POST requests to propose a timetable for a term.
+// Accepts section demands (subject, teacher, expected size, meeting pattern and duration of the scheduling policy)
+// and assigns each a start time and a classroom within the policy's daily window, respecting classroom capacity,
+// teacher availability and the sections already scheduled in the term.
+// Nothing is stored; the returned plan lists the proposed sections, which can be reviewed and passed
+// to CommitTimetable as they are, and the demands that could not be placed with the reason why.
+func (h *Handlers) SolveTimetable(w http.ResponseWriter, r *http.Request) {
+	termID, ok := h.timetableTerm(w, r)
+	if !ok {
+		return
+	}
+
+	var solveReq schema.SolveTimetableRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&solveReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	policy, err := h.fetchPolicy(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch scheduling policy")
+
+		return
+	}
+
+	if errs := validateDemands(solveReq, policy); len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	problem, err := h.loadTimetableProblem(r.Context(), termID, policy)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to load timetable constraints: %v", err))
+
+		return
+	}
+
+	problem.Step = solveReq.StepMinutes
+
+	for _, demand := range solveReq.Demands {
+		problem.Demands = append(problem.Demands, solver.Demand(demand))
+	}
+
+	solution := solver.Solve(problem)
+
+	plan := schema.TimetablePlan{
+		TermID:   termID,
+		Sections: []schema.CreateSectionRequest{},
+		Unplaced: []schema.UnplacedDemand{},
+	}
+
+	for _, placement := range solution.Placements {
+		plan.Sections = append(plan.Sections, schema.CreateSectionRequest{
+			SectionCode:     placement.Demand.SectionCode,
+			StartTime:       solver.FormatClock(placement.Slot.Start),
+			Days:            placement.Slot.Days,
+			TermID:          &termID,
+			SubjectID:       placement.Demand.SubjectID,
+			TeacherID:       placement.Demand.TeacherID,
+			ClassroomID:     placement.ClassroomID,
+			DurationMinutes: placement.Demand.DurationMinutes,
+			MaxEnrollment:   placement.Demand.ExpectedSize,
+		})
+	}
+
+	for _, unplaced := range solution.Unplaced {
+		plan.Unplaced = append(plan.Unplaced, schema.UnplacedDemand{
+			Reason: unplaced.Reason,
+			Demand: schema.SectionDemand(unplaced.Demand),
+		})
+	}
+
+	utils.SendJSON(w, http.StatusOK, plan)
+}
+
+// CommitTimetable handles HTTP POST requests to create the sections of a reviewed timetable plan.
+// Every section is validated as with CreateSection and scoped to the term in the path;
+// the sections are created in a single transaction, so either all of them are stored or none.
+// Returns the created sections.
+func (h *Handlers) CommitTimetable(w http.ResponseWriter, r *http.Request) {
+	termID, ok := h.timetableTerm(w, r)
+	if !ok {
+		return
+	}
+
+	var commitReq schema.CommitTimetableRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&commitReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	policy, err := h.fetchPolicy(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch scheduling policy")
+
+		return
+	}
+
+	var errs utils.FieldErrors
+
+	errs.Required(len(commitReq.Sections) == 0, "sections", "At least one section is required")
+
+	for i := range commitReq.Sections {
+		commitReq.Sections[i].TermID = &termID
+
+		errs.Nest(fmt.Sprintf("sections[%d]", i), validateSection(commitReq.Sections[i], policy))
+	}
+
+	if len(errs) > 0 {
+		utils.SendValidationError(w, errs)
+
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to begin transaction")
+
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	sections := make([]schema.Section, 0, len(commitReq.Sections))
+
+	for _, sectionReq := range commitReq.Sections {
+		section, err := insertSection(r.Context(), tx, sectionReq)
+		if err != nil {
+			h.sendSectionError(w, r, err, sectionReq, nil, "create")
+
+			return
+		}
+
+		sections = append(sections, section)
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to commit transaction")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, sections)
+}
+
+// timetableTerm parses the term ID path parameter and checks that the term exists.
+// Sends an error response and returns false when it does not.
+func (h *Handlers) timetableTerm(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid term ID")
+
+		return 0, false
+	}
+
+	if _, err := h.fetchTerm(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, http.StatusNotFound, "Term not found")
+
+			return 0, false
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch term")
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// loadTimetableProblem loads the classrooms, the sections already scheduled in the term
+// and the teachers' availability windows into a solver problem bounded by the scheduling policy.
+func (h *Handlers) loadTimetableProblem(ctx context.Context, termID int, policy schedulingPolicy) (solver.Problem, error) {
+	problem := solver.Problem{
+		Availability: make(map[int][]solver.Slot),
+		Patterns:     policy.patterns(),
+		DayStart:     policy.dayStart,
+		DayEnd:       policy.dayEnd,
+	}
+
+	rows, err := h.db.Query(ctx, `SELECT id, capacity FROM classrooms ORDER BY id`)
+	if err != nil {
+		return problem, err
+	}
+
+	problem.Rooms, err = pgx.CollectRows(rows, pgx.RowToStructByPos[solver.Room])
+	if err != nil {
+		return problem, err
+	}
+
+	rows, err = h.db.Query(ctx, `
+		SELECT s.teacher_id, m.classroom_id, m.start_time::text, m.duration_minutes, m.days::text[]
+		FROM sections s
+		JOIN section_meeting_view m ON s.id = m.section_id
+		WHERE s.term_id = $1
+	`, termID)
+	if err != nil {
+		return problem, err
+	}
+
+	problem.Existing, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (solver.Meeting, error) {
+		var (
+			meeting         solver.Meeting
+			startTime       string
+			durationMinutes int
+		)
+
+		if err := row.Scan(&meeting.TeacherID, &meeting.ClassroomID, &startTime, &durationMinutes, &meeting.Slot.Days); err != nil {
+			return meeting, err
+		}
+
+		start, err := solver.ParseClock(startTime)
+		meeting.Slot.Start, meeting.Slot.End = start, start+durationMinutes
+
+		return meeting, err
+	})
+	if err != nil {
+		return problem, err
+	}
+
+	rows, err = h.db.Query(ctx, `SELECT teacher_id, day::text, start_time::text, end_time::text FROM teacher_availability`)
+	if err != nil {
+		return problem, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			teacherID          int
+			day                string
+			startTime, endTime string
+		)
+
+		if err := rows.Scan(&teacherID, &day, &startTime, &endTime); err != nil {
+			return problem, err
+		}
+
+		start, err := solver.ParseClock(startTime)
+		if err != nil {
+			return problem, err
+		}
+
+		end, err := solver.ParseClock(endTime)
+		if err != nil {
+			return problem, err
+		}
+
+		problem.Availability[teacherID] = append(problem.Availability[teacherID], solver.Slot{
+			Days:  []string{day},
+			Start: start,
+			End:   end,
+		})
+	}
+
+	return problem, rows.Err()
+}
+
+// validateDemands checks the section demands of a timetable request against the meeting patterns
+// and durations of the scheduling policy.
+// Returns the invalid fields, or nil when the request is valid.
+func validateDemands(solveReq schema.SolveTimetableRequest, policy schedulingPolicy) utils.FieldErrors {
+	patterns := policy.patterns()
+
+	var errs utils.FieldErrors
+
+	errs.Required(len(solveReq.Demands) == 0, "demands", "At least one section demand is required")
+
+	if solveReq.StepMinutes < 0 || solveReq.StepMinutes > 60 {
+		errs.Add("step_minutes", "invalid", "Step minutes must be between 1 and 60")
+	}
+
+	for i, demand := range solveReq.Demands {
+		var demandErrs utils.FieldErrors
+
+		demandErrs.Required(demand.SubjectID <= 0, "subject_id", "Subject ID is required")
+		demandErrs.Required(demand.TeacherID <= 0, "teacher_id", "Teacher ID is required")
+		demandErrs.Required(demand.SectionCode == "", "section_code", "Section code is required")
+		demandErrs.Required(demand.Pattern == "", "pattern", "Pattern is required")
+		demandErrs.Required(demand.DurationMinutes <= 0, "duration_minutes", "Duration minutes is required")
+		demandErrs.Required(demand.ExpectedSize <= 0, "expected_size", "Expected size is required")
+
+		if _, ok := patterns[demand.Pattern]; demand.Pattern != "" && !ok {
+			demandErrs.Add("pattern", "invalid", "Pattern must be one of "+policy.patternList())
+		}
+
+		if demand.DurationMinutes > 0 && !slices.Contains(policy.Durations, demand.DurationMinutes) {
+			demandErrs.Add("duration_minutes", "invalid", "Duration minutes must be one of "+policy.durationList())
+		}
+
+		errs.Nest(fmt.Sprintf("demands[%d]", i), demandErrs)
+	}
+
+	return errs
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
 )
 
 // Handlers encapsulates the database connection pool for API request handlers.
@@ -17,7 +20,128 @@ func New(db *pgxpool.Pool) *Handlers {
 }
 
 // EnrollmentRequest represents the data needed to create a new enrollment.
+// A lecture with linked components is enrolled together with one component of each type,
+// listed in ComponentSectionIDs.
 type EnrollmentRequest struct {
+	ComponentSectionIDs []int `json:"component_section_ids,omitempty"`
+	StudentID           int   `json:"student_id"`
+	SectionID           int   `json:"section_id"`
+}
+
+// EnrollmentFailure describes why one section of a lecture and component combination could not be enrolled.
+// Status and Code are the HTTP status and problem code EnrollStudent would have returned for the section alone.
+type EnrollmentFailure struct {
+	Code      string `json:"code"`
+	Error     string `json:"error"`
+	SectionID int    `json:"section_id"`
+	Status    int    `json:"status"`
+}
+
+// CombinationFailureResponse is returned when sections of a lecture and component combination cannot be enrolled.
+// The problem carries the status and code of the first failure; Failures lists every failing section.
+type CombinationFailureResponse struct {
+	utils.Problem
+	Failures []EnrollmentFailure `json:"failures"`
+}
+
+// InvalidCombinationResponse is returned with 422 Unprocessable Entity when a student's enrollments in a lecture
+// and its components would not form a valid combination, e.g. a lecture without a lab or with two recitations.
+// A component taken without its lecture lists "lecture" as missing.
+type InvalidCombinationResponse struct {
+	utils.Problem
+	MissingComponentTypes   []string `json:"missing_component_types"`
+	DuplicateComponentTypes []string `json:"duplicate_component_types"`
+	LectureSectionID        int      `json:"lecture_section_id"`
+}
+
+// WaitlistRequest represents the data needed to join a section waitlist.
+type WaitlistRequest struct {
 	StudentID int `json:"student_id"`
-	SectionID int `json:"section_id"`
+}
+
+// BatchEnrollmentRequest represents a list of enrollments to create together.
+type BatchEnrollmentRequest struct {
+	Enrollments []EnrollmentRequest `json:"enrollments"`
+}
+
+// BatchEnrollmentResponse reports the outcome of a batch enrollment.
+// Results follow the order of the request; Enrolled counts the enrollments that were stored.
+// When an atomic batch fails the response is a problem with the results as extension member.
+type BatchEnrollmentResponse struct {
+	*utils.Problem
+	Mode     string                  `json:"mode"`
+	Results  []BatchEnrollmentResult `json:"results"`
+	Enrolled int                     `json:"enrolled"`
+}
+
+// BatchEnrollmentResult is the outcome of a single batch item.
+// Status and Code are the HTTP status and problem code EnrollStudent would have returned for the item.
+type BatchEnrollmentResult struct {
+	Code       string             `json:"code,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Enrollment *schema.Enrollment `json:"enrollment,omitempty"`
+	Index      int                `json:"index"`
+	Status     int                `json:"status"`
+}
+
+// SwapRequest represents the data needed to move a student from one section to another.
+type SwapRequest struct {
+	DropSectionID   int `json:"drop_section_id"`
+	EnrollSectionID int `json:"enroll_section_id"`
+}
+
+// SwapResponse is returned when a student swaps sections.
+// PromotedStudentIDs lists waitlisted students who were enrolled into the seat freed in the dropped section.
+type SwapResponse struct {
+	Enrollment         schema.Enrollment `json:"enrollment"`
+	PromotedStudentIDs []int             `json:"promoted_student_ids"`
+}
+
+// DropResponse is returned when a student drops a section.
+// DroppedComponentIDs lists the linked components dropped together with a lecture;
+// PromotedStudentIDs lists waitlisted students who were enrolled into the freed seat.
+type DropResponse struct {
+	Message             string `json:"message"`
+	DroppedComponentIDs []int  `json:"dropped_component_ids,omitempty"`
+	PromotedStudentIDs  []int  `json:"promoted_student_ids"`
+}
+
+// ConflictResponse is returned with 409 Conflict when a section would double-book a shared resource.
+type ConflictResponse struct {
+	utils.Problem
+	Conflicts []schema.SectionConflict `json:"conflicts"`
+}
+
+// CapacityConflictResponse is returned with 409 Conflict when a classroom would become too small
+// for the sections it hosts.
+type CapacityConflictResponse struct {
+	utils.Problem
+	Sections []schema.Section `json:"sections"`
+}
+
+// RequirementsResponse is returned with 422 Unprocessable Entity when a student does not meet
+// the prerequisites or corequisites of a section's subject.
+type RequirementsResponse struct {
+	utils.Problem
+	Unmet []schema.RequirementGroup `json:"unmet_requirements"`
+}
+
+// ImportResponse reports the outcome of a bulk import.
+// When Errors is non-empty nothing was imported and the response is a problem with the report as extension members.
+type ImportResponse struct {
+	*utils.Problem
+	Entity    string        `json:"entity"`
+	Errors    []ImportError `json:"errors"`
+	TotalRows int           `json:"total_rows"`
+	Imported  int           `json:"imported"`
+	DryRun    bool          `json:"dry_run"`
+}
+
+// ImportError describes why a single row of an import file was rejected.
+// Line is the 1-based line of the row in the uploaded file; Field names the offending column, if known.
+type ImportError struct {
+	Field  string `json:"field,omitempty"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Line   int    `json:"line"`
 }
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"code.local/internal/pkg/schema"
+	"code.local/internal/pkg/utils"
+)
+
+// JoinWaitlist handles HTTP POST requests to add a student to a full section's waitlist.
+// Eligibility (section full, waitlist not full, not already enrolled, not a linked section) is enforced by database triggers.
+// Returns the created waitlist entry with the student's position.
+func (h *Handlers) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	sectionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	var waitlistReq WaitlistRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&waitlistReq); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if waitlistReq.StudentID <= 0 {
+		utils.SendValidationError(w, utils.FieldErrors{{Field: "student_id", Code: "required", Detail: "Student ID is required"}})
+
+		return
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO waitlist_entries (student_id, section_id)
+			VALUES ($1, $2)
+			RETURNING id, student_id, section_id, created_at
+		)
+		SELECT
+			i.id, i.student_id, i.section_id, i.created_at,
+			(SELECT COUNT(*) FROM waitlist_entries w WHERE w.section_id = i.section_id) + 1
+		FROM inserted i
+	`
+
+	var entry schema.WaitlistEntry
+
+	err = h.db.QueryRow(r.Context(), query, waitlistReq.StudentID, sectionID).Scan(
+		&entry.ID, &entry.StudentID, &entry.SectionID, &entry.CreatedAt, &entry.Position,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // Unique violation
+				utils.SendErrorCode(w, http.StatusConflict, "already_waitlisted", "Student is already on the waitlist")
+
+				return
+			case "23503": // Foreign key violation
+				utils.SendErrorCode(w, http.StatusNotFound, "reference_not_found", "Student or section not found")
+
+				return
+			case sqlStateAlreadyEnrolled:
+				utils.SendErrorCode(w, http.StatusConflict, "already_enrolled", "Student is already enrolled in this section")
+
+				return
+			case sqlStateSectionHasOpenSeats:
+				utils.SendErrorCode(w, http.StatusConflict, "section_has_open_seats", "Section has open seats; enroll directly")
+
+				return
+			case sqlStateWaitlistFull:
+				utils.SendErrorCode(w, http.StatusConflict, "waitlist_full", "Waitlist is full")
+
+				return
+			case sqlStateLinkedWaitlist:
+				utils.SendErrorCode(w, http.StatusConflict, "linked_section_waitlist",
+					"Linked sections have no waitlist; enroll in the lecture and its components together")
+
+				return
+			}
+		}
+
+		utils.SendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to join waitlist: %v", err))
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, entry)
+}
+
+// GetSectionWaitlist handles HTTP GET requests to retrieve a section's waitlist.
+// Returns all waitlist entries of the section ordered by position.
+func (h *Handlers) GetSectionWaitlist(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	sectionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	query := `
+		SELECT id, student_id, section_id, created_at, position
+		FROM waitlist_position_view
+		WHERE section_id = $1
+		ORDER BY position
+	`
+
+	h.sendWaitlist(w, r, query, sectionID)
+}
+
+// GetStudentWaitlist handles HTTP GET requests to retrieve a student's waitlist positions.
+// Returns one entry per section the student is waiting for.
+func (h *Handlers) GetStudentWaitlist(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	query := `
+		SELECT id, student_id, section_id, created_at, position
+		FROM waitlist_position_view
+		WHERE student_id = $1
+		ORDER BY created_at, id
+	`
+
+	h.sendWaitlist(w, r, query, studentID)
+}
+
+// LeaveWaitlist handles HTTP DELETE requests to remove a student from a section's waitlist.
+// Accepts section ID and student ID path parameters and returns a success message or a not found error.
+func (h *Handlers) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	sectionIDStr := r.PathValue("id")
+	studentIDStr := r.PathValue("student_id")
+
+	sectionID, err := strconv.Atoi(sectionIDStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid section ID")
+
+		return
+	}
+
+	studentID, err := strconv.Atoi(studentIDStr)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "Invalid student ID")
+
+		return
+	}
+
+	query := `
+		DELETE FROM waitlist_entries
+		WHERE section_id = $1 AND student_id = $2
+	`
+
+	result, err := h.db.Exec(r.Context(), query, sectionID, studentID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to leave waitlist")
+
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		utils.SendError(w, http.StatusNotFound, "Waitlist entry not found")
+
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Left waitlist successfully"})
+}
+
+// sendWaitlist runs a waitlist_position_view query with a single ID argument and sends the entries.
+func (h *Handlers) sendWaitlist(w http.ResponseWriter, r *http.Request, query string, id int) {
+	rows, err := h.db.Query(r.Context(), query, id)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "Failed to fetch waitlist")
+
+		return
+	}
+	defer rows.Close()
+
+	var entries []schema.WaitlistEntry
+
+	for rows.Next() {
+		var entry schema.WaitlistEntry
+
+		err := rows.Scan(&entry.ID, &entry.StudentID, &entry.SectionID, &entry.CreatedAt, &entry.Position)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "Failed to scan waitlist entry")
+
+			return
+		}
+
+		entries = append(entries, entry)
+	}
+
+	utils.SendJSON(w, http.StatusOK, entries)
+}
+
+// promoteFromWaitlist enrolls waitlisted students into the free seats of a section within tx.
+// Returns the IDs of the promoted students in waitlist order.
+func promoteFromWaitlist(ctx context.Context, tx pgx.Tx, sectionID int) ([]int, error) {
+	rows, err := tx.Query(ctx, `SELECT promote_from_waitlist($1)`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
@@ -0,0 +1,194 @@
+// Package ical renders weekly recurring events as an RFC 5545 iCalendar stream.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// dateTimeFormat is the floating (zone-less) DATE-TIME form; times are wall-clock campus times.
+	dateTimeFormat = "20060102T150405"
+	// utcFormat is the UTC DATE-TIME form used for timestamps.
+	utcFormat = "20060102T150405Z"
+	// maxLineOctets is the line length limit after which content lines are folded.
+	maxLineOctets = 75
+)
+
+// byDay maps weekdays to their RRULE BYDAY codes.
+var byDay = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// Event is a weekly recurring event such as a class meeting.
+type Event struct {
+	// Start is the earliest date and wall-clock time of the event; the first occurrence
+	// is moved forward to the first of Days on or after it.
+	Start time.Time
+	// Until is the last date on which the event may occur.
+	Until time.Time
+	// Stamp is the time the event was last modified.
+	Stamp time.Time
+	// UID identifies the event across exports so re-imports update it instead of duplicating it.
+	UID            string
+	Summary        string
+	Description    string
+	Location       string
+	OrganizerName  string
+	OrganizerEmail string
+	Days           []time.Weekday
+	Duration       time.Duration
+}
+
+// Calendar is a named collection of events.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// WriteTo writes the calendar as an iCalendar stream with CRLF line endings and folded lines.
+// Events without days or whose first occurrence falls after Until are skipped.
+func (c Calendar) WriteTo(w io.Writer) (int64, error) {
+	cw := &writer{w: bufio.NewWriter(w)}
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//University Course Scheduling//Schedule Export//EN")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+
+	if c.Name != "" {
+		cw.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		first, ok := FirstOccurrence(event.Start, event.Days)
+		if !ok || first.After(endOfDay(event.Until)) {
+			continue
+		}
+
+		days := slices.Clone(event.Days)
+		slices.Sort(days)
+
+		codes := make([]string, 0, len(days))
+		for _, day := range slices.Compact(days) {
+			codes = append(codes, byDay[day])
+		}
+
+		cw.line("BEGIN:VEVENT")
+		cw.line("UID:" + escapeText(event.UID))
+		cw.line("DTSTAMP:" + event.Stamp.UTC().Format(utcFormat))
+		cw.line("LAST-MODIFIED:" + event.Stamp.UTC().Format(utcFormat))
+		cw.line("DTSTART:" + first.Format(dateTimeFormat))
+		cw.line("DTEND:" + first.Add(event.Duration).Format(dateTimeFormat))
+		cw.line(fmt.Sprintf("RRULE:FREQ=WEEKLY;BYDAY=%s;UNTIL=%s",
+			strings.Join(codes, ","), endOfDay(event.Until).Format(dateTimeFormat)))
+		cw.line("SUMMARY:" + escapeText(event.Summary))
+
+		if event.Description != "" {
+			cw.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+
+		if event.Location != "" {
+			cw.line("LOCATION:" + escapeText(event.Location))
+		}
+
+		if event.OrganizerEmail != "" {
+			organizer := "ORGANIZER"
+			if event.OrganizerName != "" {
+				organizer += `;CN="` + strings.ReplaceAll(event.OrganizerName, `"`, "'") + `"`
+			}
+
+			cw.line(organizer + ":mailto:" + event.OrganizerEmail)
+		}
+
+		cw.line("END:VEVENT")
+	}
+
+	cw.line("END:VCALENDAR")
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// FirstOccurrence returns the first moment on or after start that falls on one of days,
+// keeping the wall-clock time of start. Returns false when days is empty.
+func FirstOccurrence(start time.Time, days []time.Weekday) (time.Time, bool) {
+	if len(days) == 0 {
+		return time.Time{}, false
+	}
+
+	for offset := range 7 {
+		candidate := start.AddDate(0, 0, offset)
+		if slices.Contains(days, candidate.Weekday()) {
+			return candidate, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// endOfDay returns the last second of the day of t.
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// escapeText escapes a TEXT property value as required by RFC 5545 section 3.3.11.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writer emits content lines, folding them at 75 octets without splitting UTF-8 sequences.
+type writer struct {
+	w   *bufio.Writer
+	err error
+	n   int64
+}
+
+// line writes a single content line followed by CRLF.
+func (cw *writer) line(s string) {
+	for len(s) > maxLineOctets {
+		cut := maxLineOctets
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		cw.write(s[:cut] + "\r\n")
+
+		// Continuation lines start with a space, which counts towards their length
+		s = " " + s[cut:]
+	}
+
+	cw.write(s + "\r\n")
+}
+
+// write writes s unless an earlier write failed.
+func (cw *writer) write(s string) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := cw.w.WriteString(s)
+	cw.n += int64(n)
+	cw.err = err
+}
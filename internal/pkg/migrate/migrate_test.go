@@ -0,0 +1,403 @@
+package migrate_test
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"code.local/internal/pkg/config"
+	"code.local/internal/pkg/migrate"
+	"code.local/migrations"
+)
+
+// versions returns the versions of the migrations in order.
+func versions(migrations []migrate.Migration) []int {
+	result := make([]int, 0, len(migrations))
+
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+
+	return result
+}
+
+// script returns a file holding the given SQL.
+func script(sql string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(sql)}
+}
+
+func TestNew(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_ten.up.sql":    script("SELECT 10"),
+		"0002_two.up.sql":    script("SELECT 2"),
+		"0002_two.down.sql":  script("SELECT -2"),
+		"1_one.up.sql":       script("SELECT 1"),
+		"0001_one.down.sql":  script("SELECT -1"),
+		"migrations.go":      script("package migrations"),
+		"0003_three.sql":     script("SELECT 3"),
+		"0004_four.up.sql.b": script("SELECT 4"),
+	}
+
+	m, err := migrate.New(nil, fsys)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	expected := []migrate.Migration{
+		{Name: "one", Up: "SELECT 1", Down: "SELECT -1", Version: 1},
+		{Name: "two", Up: "SELECT 2", Down: "SELECT -2", Version: 2},
+		{Name: "ten", Up: "SELECT 10", Version: 10},
+	}
+
+	if got := m.Migrations(); !slices.Equal(got, expected) {
+		t.Errorf("Expected migrations %+v, got %+v", expected, got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		fsys     fstest.MapFS
+		name     string
+		expected string
+	}{
+		{
+			name:     "ZeroVersion",
+			fsys:     fstest.MapFS{"0000_zero.up.sql": script("SELECT 0")},
+			expected: "invalid migration version",
+		},
+		{
+			name:     "VersionOutOfRange",
+			fsys:     fstest.MapFS{"99999999999999999999_huge.up.sql": script("SELECT 1")},
+			expected: "invalid migration version",
+		},
+		{
+			name: "ConflictingNames",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql":   script("SELECT 1"),
+				"0001_uno.down.sql": script("SELECT -1"),
+			},
+			expected: "conflicting names",
+		},
+		{
+			name:     "MissingUp",
+			fsys:     fstest.MapFS{"0001_one.down.sql": script("SELECT -1")},
+			expected: "has no up script",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := migrate.New(nil, test.fsys)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := migrate.New(nil, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	all := m.Migrations()
+	if len(all) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	// Versions run without gaps, so that every step of Up and Down is a single known schema version
+	for i, migration := range all {
+		if migration.Version != i+1 {
+			t.Errorf("Expected migration %d_%s to have version %d", migration.Version, migration.Name, i+1)
+		}
+
+		if migration.Down == "" {
+			t.Errorf("Expected migration %d_%s to have a down script", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	embedded, err := migrate.New(nil, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	sparse, err := migrate.New(nil, fstest.MapFS{
+		"0001_one.up.sql":   script("SELECT 1"),
+		"0003_three.up.sql": script("SELECT 3"),
+		"0005_five.up.sql":  script("SELECT 5"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	tests := []struct {
+		migrator *migrate.Migrator
+		name     string
+		expected []int
+		legacy   bool
+	}{
+		{name: "FreshDatabase", migrator: embedded, legacy: false, expected: []int{}},
+		// The sql-bootstrap init scripts created the schema, functions, triggers and views
+		{name: "LegacySchema", migrator: embedded, legacy: true, expected: []int{1, 2, 3, 4}},
+		{name: "LegacySchemaSparseVersions", migrator: sparse, legacy: true, expected: []int{1, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := versions(test.migrator.Baseline(test.legacy)); !slices.Equal(got, test.expected) {
+				t.Errorf("Expected baseline versions %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	// The baselined migrations must be exactly what the init scripts created
+	t.Run("MatchesBootstrapScripts", func(t *testing.T) {
+		baseline, scripts := embedded.Baseline(true), bootstrapScripts(t)
+		if len(baseline) != len(scripts) {
+			t.Fatalf("Expected %d baselined migrations, one per sql-bootstrap script, got %d", len(scripts), len(baseline))
+		}
+
+		for i, migration := range baseline {
+			if migration.Up != scripts[i] {
+				t.Errorf("Expected migration %d_%s to equal the sql-bootstrap script", migration.Version, migration.Name)
+			}
+		}
+	})
+
+	t.Run("UpgradeBootstrapDatabase", func(t *testing.T) {
+		db := scratchDB(t)
+		ctx := context.Background()
+
+		for _, script := range bootstrapScripts(t) {
+			if _, err := db.Exec(ctx, script); err != nil {
+				t.Fatalf("Failed to run sql-bootstrap script: %v", err)
+			}
+		}
+
+		// Data created before the upgrade, enrolled in a section meeting on two days
+		_, err := db.Exec(ctx, `
+			WITH subject AS (
+				INSERT INTO subjects (code, name) VALUES ('OLD101', 'Bootstrap') RETURNING id
+			), teacher AS (
+				INSERT INTO teachers (first_name, last_name, email) VALUES ('Bootstrap', 'Teacher', 'bootstrap@university.edu') RETURNING id
+			), classroom AS (
+				INSERT INTO classrooms (building, room_number, capacity) VALUES ('Old Hall', '101', 30) RETURNING id
+			), section AS (
+				INSERT INTO sections (subject_id, teacher_id, classroom_id, section_code, start_time, max_enrollment)
+				SELECT subject.id, teacher.id, classroom.id, '001', '09:00', 30
+				FROM subject, teacher, classroom
+				RETURNING id
+			), days AS (
+				INSERT INTO section_days (section_id, day)
+				SELECT section.id, day FROM section, unnest(ARRAY['monday', 'wednesday']::day_of_week[]) AS day
+			), student AS (
+				INSERT INTO students (student_id, first_name, last_name, email)
+				VALUES ('OLD0001', 'Bootstrap', 'Student', 'bootstrap.student@university.edu')
+				RETURNING id
+			)
+			INSERT INTO enrollments (student_id, section_id)
+			SELECT student.id, section.id FROM student, section
+		`)
+		if err != nil {
+			t.Fatalf("Failed to insert bootstrap data: %v", err)
+		}
+
+		applied, err := embedded.Up(ctx, 0)
+		if err != nil {
+			t.Fatalf("Failed to migrate bootstrap database: %v", err)
+		}
+
+		all := embedded.Migrations()
+		if expected := versions(all[len(embedded.Baseline(true)):]); !slices.Equal(versions(applied), expected) {
+			t.Errorf("Expected migrations %v to be applied, got %v", expected, versions(applied))
+		}
+
+		var termCode string
+		var enrollments, meetings, studentMeetings int
+
+		err = db.QueryRow(ctx, `
+			SELECT t.code,
+				(SELECT COUNT(*) FROM enrollments WHERE section_id = s.id),
+				(SELECT COUNT(*) FROM section_meetings WHERE section_id = s.id),
+				(SELECT COUNT(*) FROM student_meetings WHERE section_id = s.id)
+			FROM sections s
+			JOIN terms t ON t.id = s.term_id
+			WHERE s.section_code = '001'
+		`).Scan(&termCode, &enrollments, &meetings, &studentMeetings)
+		if err != nil {
+			t.Fatalf("Failed to fetch the migrated section: %v", err)
+		}
+
+		if termCode != "LEGACY" || enrollments != 1 || meetings != 2 || studentMeetings != 2 {
+			t.Errorf("Expected the section in the LEGACY term with 1 enrollment, 2 meetings and 2 student meetings, "+
+				"got term %q, %d enrollments, %d meetings and %d student meetings", termCode, enrollments, meetings, studentMeetings)
+		}
+	})
+}
+
+// bootstrapScripts returns the former sql-bootstrap init scripts in the order they ran.
+func bootstrapScripts(t *testing.T) []string {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join("testdata", "sql-bootstrap", "*.sql"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("Failed to find sql-bootstrap scripts: %v", err)
+	}
+
+	scripts := make([]string, 0, len(names))
+
+	for _, name := range names {
+		script, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+
+		scripts = append(scripts, string(script))
+	}
+
+	return scripts
+}
+
+// databaseURL returns the URL of the named database on the server configured by the DB_* variables,
+// defaulting to the docker-compose setup.
+func databaseURL(name string) string {
+	return (&url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(cmp.Or(os.Getenv(config.EnvDBUser), "user"), cmp.Or(os.Getenv(config.EnvDBPassword), "password")),
+		Host:   net.JoinHostPort(cmp.Or(os.Getenv(config.EnvDBHost), "localhost"), cmp.Or(os.Getenv(config.EnvDBPort), "5432")),
+		Path:   name,
+	}).String()
+}
+
+// scratchDB creates an empty database for the test and returns a pool connected to it.
+// The database is dropped when the test ends; the test is skipped when no server is reachable.
+func scratchDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	admin, err := pgx.Connect(ctx, databaseURL(cmp.Or(os.Getenv(config.EnvDBName), "db")))
+	if err != nil {
+		t.Skipf("Database server not available: %v", err)
+	}
+	defer admin.Close(context.Background())
+
+	name := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("Failed to create database %s: %v", name, err)
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL(name))
+	if err != nil {
+		t.Fatalf("Failed to connect to database %s: %v", name, err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+
+		conn, err := pgx.Connect(context.Background(), databaseURL(cmp.Or(os.Getenv(config.EnvDBName), "db")))
+		if err != nil {
+			t.Errorf("Failed to reconnect to drop database %s: %v", name, err)
+
+			return
+		}
+		defer conn.Close(context.Background())
+
+		if _, err := conn.Exec(context.Background(), "DROP DATABASE "+name+" WITH (FORCE)"); err != nil {
+			t.Errorf("Failed to drop database %s: %v", name, err)
+		}
+	})
+
+	return pool
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	db := scratchDB(t)
+	ctx := context.Background()
+
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	applied, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	// A section without meetings has no time or classroom for the single meeting schema to keep
+	_, err = db.Exec(ctx, `
+		WITH term AS (
+			INSERT INTO terms (code, name, start_date, end_date, registration_opens_at, registration_closes_at)
+			VALUES ('MIG', 'Migration Term', '2030-09-01', '2030-12-15', '2030-08-01', '2030-09-15')
+			RETURNING id
+		), subject AS (
+			INSERT INTO subjects (code, name) VALUES ('MIG101', 'Migrations') RETURNING id
+		), teacher AS (
+			INSERT INTO teachers (first_name, last_name, email) VALUES ('Migration', 'Teacher', 'migration@university.edu') RETURNING id
+		)
+		INSERT INTO sections (term_id, subject_id, teacher_id, section_code, max_enrollment)
+		SELECT term.id, subject.id, teacher.id, '001', 10
+		FROM term, subject, teacher
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert a section without meetings: %v", err)
+	}
+
+	// Reverting stops at the multi-meeting migration instead of deleting the section
+	reverted, err := m.Down(ctx, len(applied))
+	if err == nil || !strings.Contains(err.Error(), "multi_meeting_sections") {
+		t.Fatalf("Expected reverting the multi-meeting migration to fail, got %v", err)
+	}
+
+	var sections int
+
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM sections`).Scan(&sections); err != nil || sections != 1 {
+		t.Fatalf("Expected the section without meetings to be kept, got %d sections (%v)", sections, err)
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM sections`); err != nil {
+		t.Fatalf("Failed to delete the section without meetings: %v", err)
+	}
+
+	rest, err := m.Down(ctx, len(applied)-len(reverted))
+	if err != nil {
+		t.Fatalf("Failed to revert migrations: %v", err)
+	}
+
+	if len(reverted)+len(rest) != len(applied) {
+		t.Errorf("Expected %d migrations to be reverted, got %d", len(applied), len(reverted)+len(rest))
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Failed to fetch status: %v", err)
+	}
+
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("Expected migration %d_%s to be reverted", status.Version, status.Name)
+		}
+	}
+
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatalf("Failed to reapply migrations: %v", err)
+	}
+}
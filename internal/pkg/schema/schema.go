@@ -1,6 +1,9 @@
 package schema
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Student represents a university student record with identification and contact information.
 type Student struct {
@@ -14,31 +17,85 @@ type Student struct {
 }
 
 // Section represents a course section with scheduling and capacity information.
+// Meetings lists every weekly meeting of the section, e.g. its lecture and lab; StartTime, DurationMinutes,
+// ClassroomID and Days repeat the meeting of single-meeting sections and are omitted for sections with several.
+// ComponentType is lecture, lab or recitation; labs and recitations name the lecture they belong to in ParentSectionID.
 type Section struct {
 	CreatedAt         time.Time `json:"created_at,omitzero"`
 	UpdatedAt         time.Time `json:"updated_at,omitzero"`
 	SectionCode       string    `json:"section_code"`
-	StartTime         string    `json:"start_time"`
-	Days              []string  `json:"days"`
+	ComponentType     string    `json:"component_type"`
+	StartTime         string    `json:"start_time,omitempty"`
+	Days              []string  `json:"days,omitempty"`
+	Meetings          []Meeting `json:"meetings"`
+	ParentSectionID   *int      `json:"parent_section_id"`
 	ID                int       `json:"id"`
+	TermID            int       `json:"term_id"`
 	SubjectID         int       `json:"subject_id"`
 	TeacherID         int       `json:"teacher_id"`
-	ClassroomID       int       `json:"classroom_id"`
-	DurationMinutes   int       `json:"duration_minutes"`
+	ClassroomID       int       `json:"classroom_id,omitempty"`
+	DurationMinutes   int       `json:"duration_minutes,omitempty"`
 	MaxEnrollment     int       `json:"max_enrollment"`
 	CurrentEnrollment int       `json:"current_enrollment"`
+	MaxWaitlist       int       `json:"max_waitlist"`
+}
+
+// Meeting is a weekly meeting of a section: the days it meets on, its start time, duration and classroom.
+// EndTime is derived from the start time and duration and ignored in requests.
+type Meeting struct {
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time,omitempty"`
+	Days            []string `json:"days"`
+	ClassroomID     int      `json:"classroom_id"`
+	DurationMinutes int      `json:"duration_minutes"`
+}
+
+// SectionConflict describes a meeting of an existing section that already occupies a requested time slot.
+// Every meeting of a conflicting section is listed, so a section may appear more than once.
+type SectionConflict struct {
+	SubjectCode string   `json:"subject_code"`
+	SectionCode string   `json:"section_code"`
+	StartTime   string   `json:"start_time"`
+	EndTime     string   `json:"end_time"`
+	Days        []string `json:"days"`
+	SectionID   int      `json:"section_id"`
+}
+
+// Booking represents a weekly meeting of a section that occupies a classroom on a given day.
+type Booking struct {
+	Day              string `json:"day"`
+	StartTime        string `json:"start_time"`
+	EndTime          string `json:"end_time"`
+	SubjectCode      string `json:"subject_code"`
+	SectionCode      string `json:"section_code"`
+	TeacherFirstName string `json:"teacher_first_name"`
+	TeacherLastName  string `json:"teacher_last_name"`
+	TermID           int    `json:"term_id"`
+	SectionID        int    `json:"section_id"`
 }
 
 // Enrollment represents a student's registration in a specific course section.
+// Components lists the enrollments in the lab and recitation sections taken together with a lecture.
 type Enrollment struct {
-	EnrollmentDate time.Time `json:"enrollment_date,omitzero"`
-	CreatedAt      time.Time `json:"created_at,omitzero"`
-	ID             int       `json:"id"`
-	StudentID      int       `json:"student_id"`
-	SectionID      int       `json:"section_id"`
+	EnrollmentDate time.Time    `json:"enrollment_date,omitzero"`
+	CreatedAt      time.Time    `json:"created_at,omitzero"`
+	Components     []Enrollment `json:"components,omitempty"`
+	ID             int          `json:"id"`
+	StudentID      int          `json:"student_id"`
+	SectionID      int          `json:"section_id"`
+}
+
+// WaitlistEntry represents a student's place in the waitlist of a full section.
+type WaitlistEntry struct {
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	ID        int       `json:"id"`
+	StudentID int       `json:"student_id"`
+	SectionID int       `json:"section_id"`
+	Position  int       `json:"position"`
 }
 
-// ScheduleItem represents a course in a student's schedule with all relevant details.
+// ScheduleItem represents a meeting of a course in a student's schedule with all relevant details.
+// Sections with several meetings, e.g. a lecture and a lab, have one item per meeting.
 type ScheduleItem struct {
 	SubjectCode      string   `json:"subject_code"`
 	SubjectName      string   `json:"subject_name"`
@@ -50,6 +107,7 @@ type ScheduleItem struct {
 	StartTime        string   `json:"start_time"`
 	EndTime          string   `json:"end_time"`
 	Days             []string `json:"days"`
+	TermID           int      `json:"term_id"`
 	SectionID        int      `json:"section_id"`
 	DurationMinutes  int      `json:"duration_minutes"`
 }
@@ -74,6 +132,34 @@ type Subject struct {
 	ID          int       `json:"id"`
 }
 
+// RequirementGroup is a set of alternative subjects that satisfy one requirement of a subject.
+// Taking any one of the subjects meets the group; corequisites may also be taken in the same term.
+type RequirementGroup struct {
+	Kind     string    `json:"kind"`
+	Subjects []Subject `json:"subjects"`
+	Group    int       `json:"group"`
+}
+
+// SubjectRequirements lists the prerequisite and corequisite groups of a subject.
+// Every group must be met before a student can enroll in a section of the subject.
+type SubjectRequirements struct {
+	Prerequisites []RequirementGroup `json:"prerequisites"`
+	Corequisites  []RequirementGroup `json:"corequisites"`
+	SubjectID     int                `json:"subject_id"`
+}
+
+// CompletedCourse represents a subject a student has already completed.
+type CompletedCourse struct {
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	SubjectCode string    `json:"subject_code"`
+	SubjectName string    `json:"subject_name"`
+	Grade       *string   `json:"grade"`
+	TermID      *int      `json:"term_id"`
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	SubjectID   int       `json:"subject_id"`
+}
+
 // Classroom represents a physical location where classes are held.
 type Classroom struct {
 	CreatedAt  time.Time `json:"created_at,omitzero"`
@@ -84,16 +170,37 @@ type Classroom struct {
 	Capacity   int       `json:"capacity"`
 }
 
+// Term represents an academic term (semester) that sections and enrollments are scoped to.
+type Term struct {
+	RegistrationOpensAt  time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time `json:"registration_closes_at"`
+	CreatedAt            time.Time `json:"created_at,omitzero"`
+	UpdatedAt            time.Time `json:"updated_at,omitzero"`
+	Code                 string    `json:"code"`
+	Name                 string    `json:"name"`
+	StartDate            string    `json:"start_date"`
+	EndDate              string    `json:"end_date"`
+	ID                   int       `json:"id"`
+}
+
 // CreateSectionRequest contains all data needed to create a new course section.
+// A section with several meetings lists them in Meetings; a single-meeting section may instead
+// give its StartTime, DurationMinutes, ClassroomID and Days directly.
+// ComponentType defaults to lecture; labs and recitations require the ParentSectionID of their lecture.
 type CreateSectionRequest struct {
-	SectionCode     string   `json:"section_code"`
-	StartTime       string   `json:"start_time"`
-	Days            []string `json:"days"`
-	SubjectID       int      `json:"subject_id"`
-	TeacherID       int      `json:"teacher_id"`
-	ClassroomID     int      `json:"classroom_id"`
-	DurationMinutes int      `json:"duration_minutes"`
-	MaxEnrollment   int      `json:"max_enrollment"`
+	SectionCode     string    `json:"section_code"`
+	ComponentType   string    `json:"component_type,omitempty"`
+	StartTime       string    `json:"start_time,omitempty"`
+	Days            []string  `json:"days,omitempty"`
+	Meetings        []Meeting `json:"meetings,omitempty"`
+	TermID          *int      `json:"term_id"`
+	ParentSectionID *int      `json:"parent_section_id"`
+	SubjectID       int       `json:"subject_id"`
+	TeacherID       int       `json:"teacher_id"`
+	ClassroomID     int       `json:"classroom_id,omitempty"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	MaxEnrollment   int       `json:"max_enrollment"`
+	MaxWaitlist     int       `json:"max_waitlist"`
 }
 
 // CreateStudentRequest contains all data needed to create a new student record.
@@ -103,3 +210,199 @@ type CreateStudentRequest struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 }
+
+// SetRequirementsRequest replaces all requirements of a subject.
+// Each inner slice is a group of alternative subject IDs; all groups must be met.
+type SetRequirementsRequest struct {
+	Prerequisites [][]int `json:"prerequisites"`
+	Corequisites  [][]int `json:"corequisites"`
+}
+
+// CompletedCourseRequest contains all data needed to record a completed course for a student.
+type CompletedCourseRequest struct {
+	Grade     *string `json:"grade"`
+	TermID    *int    `json:"term_id"`
+	SubjectID int     `json:"subject_id"`
+}
+
+// AvailabilityWindow is a weekly time window in which a teacher can teach.
+type AvailabilityWindow struct {
+	Day       string `json:"day"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// SectionDemand is a section that still needs a start time and a classroom.
+// Pattern is one of the scheduling policy's meeting patterns (see GET /api/policy);
+// the expected size becomes the section's max enrollment.
+type SectionDemand struct {
+	SectionCode     string `json:"section_code"`
+	Pattern         string `json:"pattern"`
+	SubjectID       int    `json:"subject_id"`
+	TeacherID       int    `json:"teacher_id"`
+	ExpectedSize    int    `json:"expected_size"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// UnplacedDemand is a section demand the timetable solver could not place, with the reason why.
+type UnplacedDemand struct {
+	Reason string        `json:"reason"`
+	Demand SectionDemand `json:"demand"`
+}
+
+// SolveTimetableRequest contains the section demands to place into a term's timetable.
+type SolveTimetableRequest struct {
+	Demands     []SectionDemand `json:"demands"`
+	StepMinutes int             `json:"step_minutes"`
+}
+
+// TimetablePlan is a proposed timetable for a term.
+// Its sections can be reviewed, edited and then committed as they are.
+type TimetablePlan struct {
+	Sections []CreateSectionRequest `json:"sections"`
+	Unplaced []UnplacedDemand       `json:"unplaced"`
+	TermID   int                    `json:"term_id"`
+}
+
+// CommitTimetableRequest contains the sections of a reviewed timetable plan to create.
+type CommitTimetableRequest struct {
+	Sections []CreateSectionRequest `json:"sections"`
+}
+
+// SchedulePreferences are soft wishes used to rank schedule options.
+type SchedulePreferences struct {
+	// NoClassesBefore is the earliest preferred start time, e.g. "09:00".
+	NoClassesBefore string   `json:"no_classes_before"`
+	FreeDays        []string `json:"free_days"`
+	CompactDays     bool     `json:"compact_days"`
+}
+
+// ScheduleOptionsRequest contains the subjects a student wants to take and how to rank the options.
+type ScheduleOptionsRequest struct {
+	SubjectIDs  []int               `json:"subject_ids"`
+	Preferences SchedulePreferences `json:"preferences"`
+	TermID      *int                `json:"term_id"`
+	Limit       int                 `json:"limit"`
+}
+
+// ScheduleOptionSection is an open section picked by a schedule option.
+// StartTime, EndTime and Days repeat the meeting of single-meeting sections and are omitted for sections with several.
+// Labs and recitations name the lecture they are picked with in ParentSectionID.
+type ScheduleOptionSection struct {
+	SubjectCode     string    `json:"subject_code"`
+	SectionCode     string    `json:"section_code"`
+	ComponentType   string    `json:"component_type"`
+	StartTime       string    `json:"start_time,omitempty"`
+	EndTime         string    `json:"end_time,omitempty"`
+	Days            []string  `json:"days,omitempty"`
+	Meetings        []Meeting `json:"meetings"`
+	ParentSectionID *int      `json:"parent_section_id"`
+	SectionID       int       `json:"section_id"`
+	SubjectID       int       `json:"subject_id"`
+	SeatsAvailable  int       `json:"seats_available"`
+}
+
+// ScheduleOption is a conflict-free combination of one open section per desired subject;
+// a lecture with linked components is followed by one open component of each type. Options with a lower score rank higher.
+type ScheduleOption struct {
+	Sections         []ScheduleOptionSection `json:"sections"`
+	UnmetPreferences []string                `json:"unmet_preferences"`
+	Rank             int                     `json:"rank"`
+	Score            int                     `json:"score"`
+}
+
+// UnavailableSubject is a desired subject no schedule option can include, with the reason why.
+type UnavailableSubject struct {
+	Reason    string `json:"reason"`
+	SubjectID int    `json:"subject_id"`
+}
+
+// ScheduleOptionsResponse lists the ranked schedule options for a student.
+type ScheduleOptionsResponse struct {
+	Options     []ScheduleOption     `json:"options"`
+	Unavailable []UnavailableSubject `json:"unavailable_subjects"`
+}
+
+// EligibilityReason is a single reason why a student cannot enroll in a section.
+// Code is stable: section_not_found, registration_closed, requirements_not_met,
+// already_enrolled, schedule_conflict, section_full, missing_components or duplicate_components.
+type EligibilityReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Eligibility reports whether a student could enroll in a section and, if not, every reason why.
+// The component types name what enrolling the section on its own would leave missing or duplicated,
+// as in the invalid_section_combination problem; "lecture" when a component is taken without its lecture.
+type Eligibility struct {
+	Reasons                 []EligibilityReason `json:"reasons"`
+	Conflicts               []SectionConflict   `json:"conflicts,omitempty"`
+	Unmet                   []RequirementGroup  `json:"unmet_requirements,omitempty"`
+	MissingComponentTypes   []string            `json:"missing_component_types,omitempty"`
+	DuplicateComponentTypes []string            `json:"duplicate_component_types,omitempty"`
+	SectionID               int                 `json:"section_id"`
+	Eligible                bool                `json:"eligible"`
+}
+
+// APIKey is an API key for service integrations. The secret itself is only returned once, on creation.
+type APIKey struct {
+	CreatedAt  time.Time  `json:"created_at,omitzero"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	StudentID  *int       `json:"student_id"`
+	TeacherID  *int       `json:"teacher_id"`
+	ID         int        `json:"id"`
+}
+
+// CreateAPIKeyRequest represents the data needed to create an API key; keys without ExpiresAt never expire.
+// Student and teacher keys must name the student or teacher they act for.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	StudentID *int       `json:"student_id"`
+	TeacherID *int       `json:"teacher_id"`
+}
+
+// CreatedAPIKey is returned when an API key is created and is the only response holding the secret key.
+type CreatedAPIKey struct {
+	Key string `json:"key"`
+	APIKey
+}
+
+// AuditEntry is a row change recorded in the append-only audit log.
+// Before is null for creates and After is null for deletes.
+type AuditEntry struct {
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	ActorRole  *string         `json:"actor_role"`
+	RequestID  *string         `json:"request_id"`
+	EntityID   *int            `json:"entity_id"`
+	ID         int             `json:"id"`
+}
+
+// SchedulingPolicy holds the institution's rules for section meetings: the days they may be held on,
+// their allowed durations and the daily window, e.g. "07:30:00" to "22:00:00", they must fit into.
+// Patterns lists the standard meeting patterns offered by the timetable solver.
+type SchedulingPolicy struct {
+	UpdatedAt time.Time        `json:"updated_at"`
+	DayStart  string           `json:"day_start"`
+	DayEnd    string           `json:"day_end"`
+	Days      []string         `json:"days"`
+	Patterns  []MeetingPattern `json:"patterns"`
+	Durations []int            `json:"durations"`
+}
+
+// MeetingPattern is a standard set of days a section meets on, e.g. MWF for Monday, Wednesday and Friday.
+type MeetingPattern struct {
+	Code string   `json:"code"`
+	Days []string `json:"days"`
+}
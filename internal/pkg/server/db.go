@@ -10,6 +10,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"code.local/internal/pkg/audit"
 	"code.local/internal/pkg/config"
 )
 
@@ -39,6 +40,9 @@ func InitDB() (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
+	// Attribute changes recorded by the audit triggers to the request using the connection
+	cfg.BeforeAcquire = audit.BeforeAcquire
+
 	// Create a new connection pool with the parsed configuration
 	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
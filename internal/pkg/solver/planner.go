@@ -0,0 +1,214 @@
+package solver
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// DefaultMaxCombinations bounds the number of conflict-free combinations considered by Combine.
+const DefaultMaxCombinations = 10000
+
+// Choice is a section that can be picked for a course, with the slots of all its meetings.
+// ComponentIDs lists the lab and recitation sections picked together with a lecture; their meetings are in Slots.
+type Choice struct {
+	Slots        []Slot
+	ComponentIDs []int
+	SectionID    int
+}
+
+// WithComponents lists the choices of a lecture taken together with one component of each group,
+// e.g. one of its labs and one of its recitations. Picks whose meetings overlap each other are left out,
+// and a group without components leaves no choice at all.
+func WithComponents(lecture Choice, groups [][]Choice) []Choice {
+	choices := []Choice{lecture}
+
+	for _, group := range groups {
+		var next []Choice
+
+		for _, choice := range choices {
+			for _, component := range group {
+				if component.Overlaps(choice.Slots) {
+					continue
+				}
+
+				next = append(next, Choice{
+					Slots:        slices.Concat(choice.Slots, component.Slots),
+					ComponentIDs: append(slices.Clone(choice.ComponentIDs), component.SectionID),
+					SectionID:    choice.SectionID,
+				})
+			}
+		}
+
+		choices = next
+	}
+
+	return choices
+}
+
+// Overlaps reports whether any meeting of the choice overlaps one of the slots.
+func (c Choice) Overlaps(slots []Slot) bool {
+	for _, slot := range c.Slots {
+		if slices.ContainsFunc(slots, slot.Overlaps) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Course lists the sections a student can pick from for one desired subject.
+type Course struct {
+	Choices   []Choice
+	SubjectID int
+}
+
+// Preferences are soft wishes used to rank combinations.
+type Preferences struct {
+	// FreeDays lists days that should have no classes.
+	FreeDays []string
+	// EarliestStart is the earliest preferred start in minutes after midnight; zero disables it.
+	EarliestStart int
+	// Compact prefers fewer days on campus and shorter gaps between classes.
+	Compact bool
+}
+
+// Combination picks one section per course; Choices follow the order of the courses.
+type Combination struct {
+	Choices []Choice
+	// Unmet describes the preferences the combination does not satisfy.
+	Unmet []string
+	// Penalty ranks combinations; lower is better.
+	Penalty int
+}
+
+// Combine lists the combinations of one section per course that overlap neither each other nor the busy slots,
+// ranked by how well they meet the preferences. At most limit combinations are returned.
+// Sections overlap as in check_schedule_conflict: any of their meetings share a day and their time ranges intersect.
+func Combine(courses []Course, busy []Slot, prefs Preferences, limit int) []Combination {
+	// Courses with the fewest choices are picked first so conflicts prune the search early
+	order := make([]int, len(courses))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(len(courses[a].Choices), len(courses[b].Choices))
+	})
+
+	var (
+		combinations []Combination
+		picked       = make([]Choice, len(courses))
+		taken        = slices.Clone(busy)
+		walk         func(k int)
+	)
+
+	walk = func(k int) {
+		if len(combinations) >= DefaultMaxCombinations {
+			return
+		}
+
+		if k == len(order) {
+			combination := Combination{Choices: slices.Clone(picked)}
+			combination.Penalty, combination.Unmet = prefs.score(combination.Choices, busy)
+			combinations = append(combinations, combination)
+
+			return
+		}
+
+		i := order[k]
+
+		for _, choice := range courses[i].Choices {
+			if choice.Overlaps(taken) {
+				continue
+			}
+
+			picked[i] = choice
+			taken = append(taken, choice.Slots...)
+
+			walk(k + 1)
+
+			taken = taken[:len(taken)-len(choice.Slots)]
+		}
+	}
+
+	walk(0)
+
+	slices.SortStableFunc(combinations, func(a, b Combination) int {
+		return cmp.Compare(a.Penalty, b.Penalty)
+	})
+
+	if len(combinations) > limit {
+		combinations = combinations[:limit]
+	}
+
+	return combinations
+}
+
+// score computes the penalty of a combination: every unmet preference weighs more
+// than any difference in compactness, which is measured as idle minutes plus an hour per day on campus.
+func (p Preferences) score(choices []Choice, busy []Slot) (int, []string) {
+	var (
+		unmet   []string
+		penalty int
+	)
+
+	for _, choice := range choices {
+		for _, slot := range choice.Slots {
+			if p.EarliestStart > 0 && slot.Start < p.EarliestStart {
+				penalty += 1000
+				unmet = appendUnique(unmet, fmt.Sprintf("Classes before %02d:%02d", p.EarliestStart/60, p.EarliestStart%60))
+			}
+
+			for _, day := range p.FreeDays {
+				if slices.Contains(slot.Days, day) {
+					penalty += 1000
+					unmet = appendUnique(unmet, "Classes on "+day)
+				}
+			}
+		}
+	}
+
+	if !p.Compact {
+		return penalty, unmet
+	}
+
+	daily := make(map[string][]Slot)
+
+	for _, slot := range busy {
+		for _, day := range slot.Days {
+			daily[day] = append(daily[day], slot)
+		}
+	}
+
+	for _, choice := range choices {
+		for _, slot := range choice.Slots {
+			for _, day := range slot.Days {
+				daily[day] = append(daily[day], slot)
+			}
+		}
+	}
+
+	for _, slots := range daily {
+		slices.SortFunc(slots, func(a, b Slot) int {
+			return cmp.Compare(a.Start, b.Start)
+		})
+
+		penalty += 60
+
+		for i := 1; i < len(slots); i++ {
+			penalty += max(0, slots[i].Start-slots[i-1].End)
+		}
+	}
+
+	return penalty, unmet
+}
+
+// appendUnique appends the value unless the slice already holds it.
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+
+	return append(values, value)
+}
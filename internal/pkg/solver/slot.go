@@ -0,0 +1,62 @@
+// Package solver places sections into the weekly timetable and combines sections into conflict-free schedules.
+package solver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Slot is a weekly meeting time: a set of days with a start and end in minutes after midnight.
+type Slot struct {
+	Days  []string
+	Start int
+	End   int
+}
+
+// Overlaps reports whether two slots share a day and their time ranges intersect.
+// Ranges are half-open, so back-to-back meetings do not overlap, matching the int4range meeting times in the database.
+func (s Slot) Overlaps(other Slot) bool {
+	if s.Start >= other.End || other.Start >= s.End {
+		return false
+	}
+
+	for _, day := range s.Days {
+		if slices.Contains(other.Days, day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Within reports whether the slot lies inside the window on every one of its days.
+func (s Slot) Within(window Slot) bool {
+	if s.Start < window.Start || s.End > window.End {
+		return false
+	}
+
+	for _, day := range s.Days {
+		if !slices.Contains(window.Days, day) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseClock converts an "HH:MM" or "HH:MM:SS" time of day into minutes after midnight.
+func ParseClock(value string) (int, error) {
+	for _, layout := range []string{time.TimeOnly, "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Hour()*60 + t.Minute(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid time of day %q", value)
+}
+
+// FormatClock converts minutes after midnight into an "HH:MM:SS" time of day.
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d:00", minutes/60, minutes%60)
+}
@@ -0,0 +1,332 @@
+package solver
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+const (
+	// DefaultDayStart is the earliest start of a meeting, 07:30.
+	DefaultDayStart = 7*60 + 30
+	// DefaultDayEnd is the latest end of a meeting, 22:00.
+	DefaultDayEnd = 22 * 60
+	// DefaultStep is the spacing of candidate start times in minutes.
+	DefaultStep = 30
+	// DefaultMaxNodes bounds the search so large terms still return a plan quickly.
+	DefaultMaxNodes = 200000
+)
+
+// DefaultPatterns maps the meeting patterns to their days.
+var DefaultPatterns = map[string][]string{
+	"MWF": {"monday", "wednesday", "friday"},
+	"TTh": {"tuesday", "thursday"},
+}
+
+// Demand is a section that still needs a start time and a classroom.
+type Demand struct {
+	SectionCode     string
+	Pattern         string
+	SubjectID       int
+	TeacherID       int
+	ExpectedSize    int
+	DurationMinutes int
+}
+
+// Room is a classroom the solver may place sections into.
+type Room struct {
+	ID       int
+	Capacity int
+}
+
+// Meeting is an already scheduled section that occupies a teacher and a classroom.
+type Meeting struct {
+	Slot        Slot
+	TeacherID   int
+	ClassroomID int
+}
+
+// Problem describes a timetabling run for a single term.
+type Problem struct {
+	// Availability lists the windows in which each teacher can teach;
+	// teachers without an entry are available at any time.
+	Availability map[int][]Slot
+	// Patterns maps meeting patterns to days; DefaultPatterns is used when nil.
+	Patterns map[string][]string
+	Demands  []Demand
+	Rooms    []Room
+	Existing []Meeting
+	// DayStart and DayEnd bound the scheduling window in minutes after midnight.
+	DayStart int
+	DayEnd   int
+	// Step is the spacing of candidate start times in minutes.
+	Step int
+	// MaxNodes bounds the number of search steps.
+	MaxNodes int
+}
+
+// Placement assigns a demand a weekly slot and a classroom.
+type Placement struct {
+	Demand      Demand
+	Slot        Slot
+	ClassroomID int
+}
+
+// Unplaced is a demand the solver could not place, with the reason why.
+type Unplaced struct {
+	Demand Demand
+	Reason string
+}
+
+// Plan is the solver's proposal for a term.
+type Plan struct {
+	Placements []Placement
+	Unplaced   []Unplaced
+}
+
+// candidate is a possible placement of a single demand.
+type candidate struct {
+	slot        Slot
+	classroomID int
+}
+
+// Solve places as many demands as possible without double-booking teachers or classrooms,
+// keeping every meeting within the scheduling window, its room's capacity and its teacher's availability.
+// The search places the most constrained demands first and backtracks until every demand is placed
+// or MaxNodes steps were taken, returning the best plan found.
+func Solve(p Problem) Plan {
+	p = p.withDefaults()
+
+	candidates := make([][]candidate, len(p.Demands))
+	reasons := make([]string, len(p.Demands))
+
+	for i, demand := range p.Demands {
+		candidates[i], reasons[i] = p.candidates(demand)
+	}
+
+	order := make([]int, len(p.Demands))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(
+			cmp.Compare(len(candidates[a]), len(candidates[b])),
+			cmp.Compare(p.Demands[b].ExpectedSize, p.Demands[a].ExpectedSize),
+		)
+	})
+
+	s := &search{
+		candidates: candidates,
+		order:      order,
+		busy:       make(map[resource][]Slot),
+		current:    make([]*candidate, len(p.Demands)),
+		best:       make([]*candidate, len(p.Demands)),
+		bestCount:  -1,
+		maxNodes:   p.MaxNodes,
+		demands:    p.Demands,
+	}
+
+	for _, meeting := range p.Existing {
+		s.occupy(resource{teacher: true, id: meeting.TeacherID}, meeting.Slot)
+		s.occupy(resource{id: meeting.ClassroomID}, meeting.Slot)
+	}
+
+	s.place(0, 0)
+
+	var plan Plan
+
+	for i, demand := range p.Demands {
+		if c := s.best[i]; c != nil {
+			plan.Placements = append(plan.Placements, Placement{Demand: demand, Slot: c.slot, ClassroomID: c.classroomID})
+
+			continue
+		}
+
+		reason := reasons[i]
+		if reason == "" {
+			reason = "Every suitable time slot conflicts with another section of the teacher or classroom"
+		}
+
+		plan.Unplaced = append(plan.Unplaced, Unplaced{Demand: demand, Reason: reason})
+	}
+
+	return plan
+}
+
+// withDefaults fills in the zero-valued settings of a problem.
+func (p Problem) withDefaults() Problem {
+	if p.Patterns == nil {
+		p.Patterns = DefaultPatterns
+	}
+
+	if p.DayStart == 0 && p.DayEnd == 0 {
+		p.DayStart, p.DayEnd = DefaultDayStart, DefaultDayEnd
+	}
+
+	if p.Step <= 0 {
+		p.Step = DefaultStep
+	}
+
+	if p.MaxNodes <= 0 {
+		p.MaxNodes = DefaultMaxNodes
+	}
+
+	return p
+}
+
+// candidates lists every placement of a demand that satisfies the static constraints,
+// ordered by start time and then by the smallest sufficient classroom.
+// When there is none, the reason describes the constraint that rules them all out.
+func (p Problem) candidates(demand Demand) ([]candidate, string) {
+	days, ok := p.Patterns[demand.Pattern]
+	if !ok {
+		return nil, fmt.Sprintf("Unknown meeting pattern %q", demand.Pattern)
+	}
+
+	rooms := make([]Room, 0, len(p.Rooms))
+
+	for _, room := range p.Rooms {
+		if room.Capacity >= demand.ExpectedSize {
+			rooms = append(rooms, room)
+		}
+	}
+
+	if len(rooms) == 0 {
+		return nil, fmt.Sprintf("No classroom can seat %d students", demand.ExpectedSize)
+	}
+
+	slices.SortStableFunc(rooms, func(a, b Room) int {
+		return cmp.Compare(a.Capacity, b.Capacity)
+	})
+
+	var (
+		result []candidate
+		fits   bool
+	)
+
+	for start := p.DayStart; start+demand.DurationMinutes <= p.DayEnd; start += p.Step {
+		fits = true
+
+		slot := Slot{Days: days, Start: start, End: start + demand.DurationMinutes}
+		if !p.available(demand.TeacherID, slot) {
+			continue
+		}
+
+		for _, room := range rooms {
+			result = append(result, candidate{slot: slot, classroomID: room.ID})
+		}
+	}
+
+	switch {
+	case !fits:
+		return nil, "Duration does not fit into the scheduling window"
+	case len(result) == 0:
+		return nil, "Teacher is not available at any suitable time"
+	}
+
+	return result, ""
+}
+
+// available reports whether a teacher's availability covers the slot on each of its days.
+func (p Problem) available(teacherID int, slot Slot) bool {
+	windows, ok := p.Availability[teacherID]
+	if !ok {
+		return true
+	}
+
+	for _, day := range slot.Days {
+		daySlot := Slot{Days: []string{day}, Start: slot.Start, End: slot.End}
+
+		if !slices.ContainsFunc(windows, daySlot.Within) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resource identifies a teacher or a classroom whose time is booked.
+type resource struct {
+	teacher bool
+	id      int
+}
+
+// search is the state of the backtracking search.
+type search struct {
+	candidates [][]candidate
+	order      []int
+	busy       map[resource][]Slot
+	current    []*candidate
+	best       []*candidate
+	demands    []Demand
+	bestCount  int
+	nodes      int
+	maxNodes   int
+}
+
+// place assigns the demand at position k of the search order and recurses.
+// A demand may be left unplaced; the bound prunes branches that cannot beat the best plan.
+func (s *search) place(k, placed int) {
+	if s.nodes >= s.maxNodes || s.bestCount == len(s.order) {
+		return
+	}
+
+	s.nodes++
+
+	if k == len(s.order) {
+		if placed > s.bestCount {
+			s.bestCount = placed
+			copy(s.best, s.current)
+		}
+
+		return
+	}
+
+	if placed+len(s.order)-k <= s.bestCount {
+		return
+	}
+
+	i := s.order[k]
+	teacher := resource{teacher: true, id: s.demands[i].TeacherID}
+
+	for j := range s.candidates[i] {
+		c := &s.candidates[i][j]
+		room := resource{id: c.classroomID}
+
+		if s.conflicts(teacher, c.slot) || s.conflicts(room, c.slot) {
+			continue
+		}
+
+		s.occupy(teacher, c.slot)
+		s.occupy(room, c.slot)
+		s.current[i] = c
+
+		s.place(k+1, placed+1)
+
+		s.current[i] = nil
+		s.release(teacher)
+		s.release(room)
+
+		if s.nodes >= s.maxNodes || s.bestCount == len(s.order) {
+			return
+		}
+	}
+
+	s.place(k+1, placed)
+}
+
+// conflicts reports whether the resource is already booked during the slot.
+func (s *search) conflicts(r resource, slot Slot) bool {
+	return slices.ContainsFunc(s.busy[r], slot.Overlaps)
+}
+
+// occupy books the resource for the slot.
+func (s *search) occupy(r resource, slot Slot) {
+	s.busy[r] = append(s.busy[r], slot)
+}
+
+// release removes the resource's most recent booking.
+func (s *search) release(r resource) {
+	s.busy[r] = s.busy[r][:len(s.busy[r])-1]
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details responses.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of a problem to form its type URI.
+const problemTypePrefix = "urn:course-scheduling:problem:"
+
+// problemTitles maps the stable problem codes to their titles.
+// Codes that are not listed fall back to the HTTP status text as title.
+var problemTitles = map[string]string{
+	"invalid_request":                   "Invalid request",
+	"unauthenticated":                   "Authentication required",
+	"forbidden":                         "Forbidden",
+	"validation_failed":                 "Validation failed",
+	"not_found":                         "Resource not found",
+	"conflict":                          "Conflict",
+	"internal_error":                    "Internal server error",
+	"duplicate_record":                  "Duplicate record",
+	"has_dependents":                    "Record has dependents",
+	"reference_not_found":               "Referenced record not found",
+	"constraint_violation":              "Constraint violation",
+	"schedule_conflict":                 "Schedule conflict",
+	"teacher_schedule_conflict":         "Teacher schedule conflict",
+	"classroom_schedule_conflict":       "Classroom schedule conflict",
+	"student_schedule_conflict":         "Student schedule conflict",
+	"section_full":                      "Section is full",
+	"registration_closed":               "Registration closed",
+	"requirements_not_met":              "Enrollment requirements not met",
+	"already_enrolled":                  "Already enrolled",
+	"already_waitlisted":                "Already on the waitlist",
+	"section_has_open_seats":            "Section has open seats",
+	"waitlist_full":                     "Waitlist is full",
+	"invalid_section_combination":       "Invalid section combination",
+	"linked_section_waitlist":           "Linked sections have no waitlist",
+	"invalid_parent_section":            "Invalid parent section",
+	"scheduling_policy_violation":       "Scheduling policy violation",
+	"classroom_capacity_exceeded":       "Classroom capacity exceeded",
+	"classroom_capacity_below_sections": "Classroom capacity below hosted sections",
+	"prerequisite_cycle":                "Prerequisite cycle",
+	"import_rejected":                   "Import rejected",
+	"batch_failed":                      "Batch failed",
+}
+
+// Problem is an RFC 7807 problem details object.
+// Code is a stable, machine-readable identifier clients can rely on (e.g. for localisation),
+// while Detail is a human-readable explanation whose wording may change.
+type Problem struct {
+	Type   string      `json:"type"`
+	Title  string      `json:"title"`
+	Detail string      `json:"detail,omitempty"`
+	Code   string      `json:"code"`
+	Errors FieldErrors `json:"errors,omitempty"`
+	Status int         `json:"status"`
+}
+
+// FieldError describes why a single request field is invalid.
+// Field is the JSON name of the field; Code is stable, e.g. "required" or "invalid".
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// FieldErrors collects the field-level errors of a request.
+type FieldErrors []FieldError
+
+// Add appends a field error.
+func (e *FieldErrors) Add(field, code, detail string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Detail: detail})
+}
+
+// Required appends a "required" error for the field when the condition is true.
+func (e *FieldErrors) Required(missing bool, field, detail string) {
+	if missing {
+		e.Add(field, "required", detail)
+	}
+}
+
+// Nest appends the errors of a nested object, prefixing their fields with its path, e.g. "sections[2]".
+func (e *FieldErrors) Nest(path string, errs FieldErrors) {
+	for _, err := range errs {
+		err.Field = path + "." + err.Field
+		*e = append(*e, err)
+	}
+}
+
+// Detail joins the details of all field errors into a single message.
+func (e FieldErrors) Detail() string {
+	details := make([]string, len(e))
+	for i, err := range e {
+		details[i] = err.Detail
+	}
+
+	return strings.Join(details, "; ")
+}
+
+// NewProblem creates a problem with the given status, stable code and detail message.
+func NewProblem(status int, code, detail string) Problem {
+	title, ok := problemTitles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+
+	return Problem{
+		Type:   problemTypePrefix + code,
+		Title:  title,
+		Detail: detail,
+		Code:   code,
+		Status: status,
+	}
+}
+
+// StatusCode returns the HTTP status of the problem.
+// Types embedding a Problem inherit it, which lets SendProblem send them with extension members.
+func (p Problem) StatusCode() int {
+	return p.Status
+}
+
+// SendProblem sends an RFC 7807 problem details response.
+// The body is a Problem or a struct embedding one, whose other fields become extension members.
+func SendProblem(w http.ResponseWriter, body interface{ StatusCode() int }) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(body.StatusCode())
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to encode problem response: %v", err)
+	}
+}
+
+// SendValidationError sends a 400 Bad Request problem listing the invalid fields.
+func SendValidationError(w http.ResponseWriter, errs FieldErrors) {
+	problem := NewProblem(http.StatusBadRequest, "validation_failed", errs.Detail())
+	problem.Errors = errs
+
+	SendProblem(w, problem)
+}
+
+// statusCode derives a generic problem code from an HTTP status, e.g. "not_found" for 404.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request"
+	case http.StatusInternalServerError:
+		return "internal_error"
+	}
+
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
@@ -6,14 +6,15 @@ import (
 	"net/http"
 )
 
-// SendError sends a JSON-formatted error response with the specified HTTP status code and message.
+// SendError sends an RFC 7807 problem details response with the specified HTTP status code and detail message.
+// The problem code is derived from the status; use SendErrorCode for errors that have a specific code.
 func SendError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	SendProblem(w, NewProblem(status, statusCode(status), message))
+}
 
-	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
-		log.Printf("Failed to encode error response: %v", err)
-	}
+// SendErrorCode sends an RFC 7807 problem details response with a stable problem code, e.g. "section_full".
+func SendErrorCode(w http.ResponseWriter, status int, code, message string) {
+	SendProblem(w, NewProblem(status, code, message))
 }
 
 // SendJSON sends a JSON-formatted response with the specified HTTP status code and data.
@@ -36,6 +37,7 @@ func FormatDays(days []string) []string {
 		"wednesday": "W",
 		"thursday":  "Th",
 		"friday":    "F",
+		"saturday":  "Sa",
 	}
 
 	for i, day := range days {
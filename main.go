@@ -8,8 +8,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
+	"code.local/internal/pkg/audit"
+	"code.local/internal/pkg/auth"
 	"code.local/internal/pkg/config"
 	"code.local/internal/pkg/cors"
 	"code.local/internal/pkg/handlers"
@@ -17,6 +20,15 @@ import (
 )
 
 func main() {
+	// Run a command line subcommand instead of the server, e.g. "migrate up"
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	// Set up HTTP server with timeouts
 	srv := &http.Server{
 		Addr:         cmp.Or(os.Getenv(config.EnvBindAddrPort), ":8080"),
@@ -31,6 +43,21 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Apply pending migrations when enabled
+	if autoMigrate, _ := strconv.ParseBool(os.Getenv(config.EnvDBAutoMigrate)); autoMigrate {
+		if err := migrateOnStartup(pool); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Set up authentication; JWTs are only accepted when a key is configured
+	jwtVerifier, err := auth.JWTVerifierFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	authenticator := auth.New(pool, jwtVerifier)
+
 	// Create handlers
 	hObj := handlers.New(pool)
 
@@ -40,35 +67,114 @@ func main() {
 	// Set up HTTP routes
 	mux := http.NewServeMux()
 
+	// Every route is authorized by a policy; admins are allowed by all of them
+	authorizer := auth.NewAuthorizer(auth.NewOwners(pool))
+	route := func(pattern string, policy auth.Policy, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, authorizer.Require(policy, handler))
+	}
+
+	// Registrars manage sections and enrollments, students act on their own records
+	// and teachers on their own sections
+	registrar := auth.Allow(auth.RoleRegistrar)
+	ownStudent := registrar.OrStudentSelf(auth.Path("id"))
+	sectionTeacher := registrar.OrSectionTeacher(auth.Path("id"))
+
+	// Body IDs are read from the handlers' own request types so policy and handler see the same student
+	enrollmentStudent := func(req handlers.EnrollmentRequest) int { return req.StudentID }
+	waitlistStudent := func(req handlers.WaitlistRequest) int { return req.StudentID }
+
 	// Student routes
-	mux.HandleFunc("GET /api/students", hObj.GetStudents)
-	mux.HandleFunc("GET /api/students/{id}", hObj.GetStudentByID)
-	mux.HandleFunc("GET /api/students/{id}/schedule", hObj.GetStudentSchedule)
-	mux.HandleFunc("POST /api/students", hObj.CreateStudent)
-	mux.HandleFunc("GET /api/students/{id}/schedule/pdf", hObj.DownloadStudentSchedule)
-	mux.HandleFunc("DELETE /api/students/{student_id}/sections/{section_id}", hObj.DropSection)
+	route("GET /api/students", registrar, hObj.GetStudents)
+	route("GET /api/students/{id}", ownStudent, hObj.GetStudentByID)
+	route("GET /api/students/{id}/schedule", ownStudent, hObj.GetStudentSchedule)
+	route("POST /api/students", registrar, hObj.CreateStudent)
+	route("PUT /api/students/{id}", registrar, hObj.UpdateStudent)
+	route("PATCH /api/students/{id}", registrar, hObj.PatchStudent)
+	route("DELETE /api/students/{id}", registrar, hObj.DeleteStudent)
+	route("GET /api/students/{id}/schedule/pdf", ownStudent, hObj.DownloadStudentSchedule)
+	route("GET /api/students/{id}/schedule.ics", ownStudent, hObj.DownloadStudentCalendar)
+	route("POST /api/students/{id}/schedule-options", ownStudent, hObj.BuildScheduleOptions)
+	route("DELETE /api/students/{student_id}/sections/{section_id}", registrar.OrStudentSelf(auth.Path("student_id")), hObj.DropSection)
+	route("POST /api/students/{id}/swap", ownStudent, hObj.SwapSection)
+	route("GET /api/students/{id}/eligibility", ownStudent, hObj.GetEnrollmentEligibility)
+	route("GET /api/students/{id}/waitlist", ownStudent, hObj.GetStudentWaitlist)
+	route("GET /api/students/{id}/completed-courses", ownStudent, hObj.GetCompletedCourses)
+	route("POST /api/students/{id}/completed-courses", registrar, hObj.AddCompletedCourse)
+	route("DELETE /api/students/{id}/completed-courses/{subject_id}", registrar, hObj.DeleteCompletedCourse)
 
 	// Teacher routes
-	mux.HandleFunc("GET /api/teachers", hObj.GetTeachers)
-	mux.HandleFunc("POST /api/teachers", hObj.CreateTeacher)
+	route("GET /api/teachers", auth.Authenticated, hObj.GetTeachers)
+	route("GET /api/teachers/{id}", auth.Authenticated, hObj.GetTeacherByID)
+	route("POST /api/teachers", auth.AdminOnly, hObj.CreateTeacher)
+	route("PUT /api/teachers/{id}", auth.AdminOnly, hObj.UpdateTeacher)
+	route("PATCH /api/teachers/{id}", auth.AdminOnly, hObj.PatchTeacher)
+	route("DELETE /api/teachers/{id}", auth.AdminOnly, hObj.DeleteTeacher)
+	route("GET /api/teachers/{id}/availability", auth.Authenticated, hObj.GetTeacherAvailability)
+	route("PUT /api/teachers/{id}/availability", registrar.OrTeacherSelf(auth.Path("id")), hObj.SetTeacherAvailability)
 
 	// Subject routes
-	mux.HandleFunc("GET /api/subjects", hObj.GetSubjects)
-	mux.HandleFunc("POST /api/subjects", hObj.CreateSubject)
+	route("GET /api/subjects", auth.Authenticated, hObj.GetSubjects)
+	route("GET /api/subjects/{id}", auth.Authenticated, hObj.GetSubjectByID)
+	route("POST /api/subjects", auth.AdminOnly, hObj.CreateSubject)
+	route("PUT /api/subjects/{id}", auth.AdminOnly, hObj.UpdateSubject)
+	route("PATCH /api/subjects/{id}", auth.AdminOnly, hObj.PatchSubject)
+	route("DELETE /api/subjects/{id}", auth.AdminOnly, hObj.DeleteSubject)
+	route("GET /api/subjects/{id}/requirements", auth.Authenticated, hObj.GetSubjectRequirements)
+	route("PUT /api/subjects/{id}/requirements", auth.AdminOnly, hObj.SetSubjectRequirements)
 
 	// Classroom routes
-	mux.HandleFunc("GET /api/classrooms", hObj.GetClassrooms)
-	mux.HandleFunc("POST /api/classrooms", hObj.CreateClassroom)
+	route("GET /api/classrooms", auth.Authenticated, hObj.GetClassrooms)
+	route("GET /api/classrooms/{id}", auth.Authenticated, hObj.GetClassroomByID)
+	route("GET /api/classrooms/{id}/occupancy", auth.Authenticated, hObj.GetClassroomOccupancy)
+	route("POST /api/classrooms", auth.AdminOnly, hObj.CreateClassroom)
+	route("PUT /api/classrooms/{id}", auth.AdminOnly, hObj.UpdateClassroom)
+	route("PATCH /api/classrooms/{id}", auth.AdminOnly, hObj.PatchClassroom)
+	route("DELETE /api/classrooms/{id}", auth.AdminOnly, hObj.DeleteClassroom)
+
+	// Term routes
+	route("GET /api/terms", auth.Authenticated, hObj.GetTerms)
+	route("GET /api/terms/{id}", auth.Authenticated, hObj.GetTermByID)
+	route("POST /api/terms", auth.AdminOnly, hObj.CreateTerm)
+	route("PUT /api/terms/{id}", auth.AdminOnly, hObj.UpdateTerm)
+	route("PATCH /api/terms/{id}", auth.AdminOnly, hObj.PatchTerm)
+	route("DELETE /api/terms/{id}", auth.AdminOnly, hObj.DeleteTerm)
+	route("POST /api/terms/{id}/timetable/solve", registrar, hObj.SolveTimetable)
+	route("POST /api/terms/{id}/timetable/commit", registrar, hObj.CommitTimetable)
+
+	// Scheduling policy routes
+	route("GET /api/policy", auth.Authenticated, hObj.GetPolicy)
 
 	// Section routes
-	mux.HandleFunc("GET /api/sections", hObj.GetSections)
-	mux.HandleFunc("POST /api/sections", hObj.CreateSection)
+	route("GET /api/sections", auth.Authenticated, hObj.GetSections)
+	route("GET /api/sections/{id}", auth.Authenticated, hObj.GetSectionByID)
+	route("GET /api/sections/{id}/roster", sectionTeacher, hObj.GetSectionRoster)
+	route("POST /api/sections", registrar, hObj.CreateSection)
+	route("PUT /api/sections/{id}", registrar, hObj.UpdateSection)
+	route("PATCH /api/sections/{id}", registrar, hObj.PatchSection)
+	route("DELETE /api/sections/{id}", registrar, hObj.DeleteSection)
+
+	// Waitlist routes
+	route("GET /api/sections/{id}/waitlist", sectionTeacher, hObj.GetSectionWaitlist)
+	route("POST /api/sections/{id}/waitlist", registrar.OrStudentSelf(auth.Body(waitlistStudent)), hObj.JoinWaitlist)
+	route("DELETE /api/sections/{id}/waitlist/{student_id}", registrar.OrStudentSelf(auth.Path("student_id")), hObj.LeaveWaitlist)
+
+	// Import routes
+	route("POST /api/import/{entity}", auth.AdminOnly, hObj.ImportRecords)
 
 	// Enrollment routes
-	mux.HandleFunc("POST /api/enrollments", hObj.EnrollStudent)
+	route("POST /api/enrollments", registrar.OrStudentSelf(auth.Body(enrollmentStudent)), hObj.EnrollStudent)
+	route("POST /api/enrollments/batch", registrar, hObj.EnrollStudentsBatch)
+
+	// Audit routes
+	route("GET /api/audit", registrar, hObj.GetAuditLog)
+
+	// Admin routes
+	route("GET /api/admin/api-keys", auth.AdminOnly, hObj.GetAPIKeys)
+	route("POST /api/admin/api-keys", auth.AdminOnly, hObj.CreateAPIKey)
+	route("DELETE /api/admin/api-keys/{id}", auth.AdminOnly, hObj.RevokeAPIKey)
 
-	// Apply CORS middleware
-	srv.Handler = cors.Register(mux)
+	// Apply CORS, request ID and authentication middleware; CORS answers preflight requests before authentication
+	srv.Handler = cors.Register(audit.Register(authenticator.Register(mux)))
 
 	// Set up signal handling for graceful shutdown
 	done := make(chan os.Signal, 1)
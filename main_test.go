@@ -7,8 +7,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"code.local/internal/pkg/auth"
+	"code.local/internal/pkg/config"
 	"code.local/internal/pkg/handlers"
 	"code.local/internal/pkg/schema"
 )
@@ -17,8 +23,62 @@ const (
 	apiURL = "http://localhost:8080/api"
 )
 
+// ErrorResponse is an RFC 7807 problem details response.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+	Errors []struct {
+		Field string `json:"field"`
+		Code  string `json:"code"`
+	} `json:"errors"`
+	Status int `json:"status"`
+}
+
+// jwtSecret is the server's HS256 secret, read from JWT_HS256_SECRET by TestMain.
+var jwtSecret []byte
+
+// baseTransport sends requests without credentials.
+var baseTransport = http.DefaultTransport
+
+// bearerTransport adds an Authorization header to every request that does not already carry one.
+type bearerTransport struct {
+	token string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (b *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	return baseTransport.RoundTrip(req)
+}
+
+// TestMain authenticates every test request as an admin with a JWT signed by the server's HS256 secret,
+// which must be given in JWT_HS256_SECRET as the server has no default.
+func TestMain(m *testing.M) {
+	jwtSecret = []byte(os.Getenv(config.EnvJWTSecret))
+	if len(jwtSecret) == 0 {
+		fmt.Fprintf(os.Stderr, "Set %s to the HS256 secret the server runs with\n", config.EnvJWTSecret)
+		os.Exit(1)
+	}
+
+	token, err := auth.SignHS256(auth.Claims{
+		Subject:   "integration-tests",
+		Role:      auth.RoleAdmin,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, jwtSecret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to sign test token: %v\n", err)
+		os.Exit(1)
+	}
+
+	http.DefaultTransport = &bearerTransport{token: token}
+
+	os.Exit(m.Run())
 }
 
 // Helper functions for API calls.
@@ -161,6 +221,10 @@ func getClassrooms(t *testing.T) []schema.Classroom {
 }
 
 func createSection(t *testing.T, req schema.CreateSectionRequest) (schema.Section, error) {
+	if req.TermID == nil {
+		req.TermID = defaultTerm(t)
+	}
+
 	resp, err := postJSON(t, apiURL+"/sections", req)
 	if err != nil {
 		t.Fatalf("Failed to create section: %v", err)
@@ -282,7 +346,7 @@ func enrollStudent(t *testing.T, studentID, sectionID int) (schema.Enrollment, e
 			return schema.Enrollment{}, fmt.Errorf("failed to decode error response: %w", err)
 		}
 
-		return schema.Enrollment{}, fmt.Errorf("error enrolling student: %s (status %d)", errResp.Error, resp.StatusCode)
+		return schema.Enrollment{}, fmt.Errorf("error enrolling student: %s (status %d)", errResp.Detail, resp.StatusCode)
 	}
 
 	var enrollment schema.Enrollment
@@ -352,6 +416,139 @@ func downloadSchedule(t *testing.T, studentID int) []byte {
 	return pdfData
 }
 
+func downloadCalendar(t *testing.T, studentID int) []byte {
+	resp, err := http.Get(fmt.Sprintf("%s/students/%d/schedule.ics", apiURL, studentID))
+	if err != nil {
+		t.Fatalf("Failed to download calendar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+
+	return data
+}
+
+var (
+	defaultTermOnce sync.Once
+	defaultTermID   int
+)
+
+// defaultTerm returns the ID of the open term that sections are created in unless a test picks its own.
+func defaultTerm(t *testing.T) *int {
+	defaultTermOnce.Do(func() {
+		now := time.Now()
+
+		defaultTermID = createTerm(t, schema.Term{
+			Code:                 "TEST-DEFAULT",
+			Name:                 "Default Test Term",
+			StartDate:            now.Format(time.DateOnly),
+			EndDate:              now.AddDate(0, 4, 0).Format(time.DateOnly),
+			RegistrationOpensAt:  now.Add(-time.Hour),
+			RegistrationClosesAt: now.AddDate(1, 0, 0),
+		}).ID
+	})
+
+	return &defaultTermID
+}
+
+func createTerm(t *testing.T, term schema.Term) schema.Term {
+	resp, err := postJSON(t, apiURL+"/terms", term)
+	if err != nil {
+		t.Fatalf("Failed to create term: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var createdTerm schema.Term
+
+	if err := json.NewDecoder(resp.Body).Decode(&createdTerm); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	return createdTerm
+}
+
+func getJSON(t *testing.T, url string, out any) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+}
+
+// Helper to make requests with an arbitrary method and optional JSON body.
+func doJSON(t *testing.T, method, url string, data any) *http.Response {
+	return doJSONAs(t, "", method, url, data)
+}
+
+// Helper to make requests with the given bearer credential instead of the admin token; "" keeps the admin token.
+func doJSONAs(t *testing.T, credential, method, url string, data any) *http.Response {
+	body := io.Reader(http.NoBody)
+
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("Failed to marshal JSON: %v", err)
+		}
+
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to %s %s: %v", method, url, err)
+	}
+
+	return resp
+}
+
+// Helper to create an API key as admin.
+func createAPIKey(t *testing.T, keyReq schema.CreateAPIKeyRequest) schema.CreatedAPIKey {
+	resp := doJSON(t, http.MethodPost, apiURL+"/admin/api-keys", keyReq)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var created schema.CreatedAPIKey
+
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode API key: %v", err)
+	}
+
+	return created
+}
+
 // Helper to make POST requests with JSON body.
 func postJSON(t *testing.T, url string, data any) (*http.Response, error) {
 	jsonData, err := json.Marshal(data)
@@ -762,6 +959,7 @@ func TestInvalidRequests(t *testing.T) {
 		}
 
 		sectionReq := schema.CreateSectionRequest{
+			TermID:          defaultTerm(t),
 			SubjectID:       subjects[0].ID,
 			TeacherID:       teachers[0].ID,
 			ClassroomID:     classrooms[0].ID,
@@ -801,6 +999,7 @@ func TestInvalidRequests(t *testing.T) {
 		}
 
 		sectionReq := schema.CreateSectionRequest{
+			TermID:          defaultTerm(t),
 			SubjectID:       subjects[0].ID,
 			TeacherID:       teachers[0].ID,
 			ClassroomID:     classrooms[0].ID,
@@ -826,6 +1025,35 @@ func TestInvalidRequests(t *testing.T) {
 		}
 	})
 
+	// Test invalid section creation - every section belongs to a term
+	t.Run("MissingSectionTerm", func(t *testing.T) {
+		teacher := createTeacher(t, "Termless", "Teacher", "termless.teacher@university.edu")
+		subject := createSubject(t, "NOTERM101", "Termless Studies", "")
+		room := createClassroom(t, "Termless Hall", "1", 30)
+
+		resp := doJSON(t, http.MethodPost, apiURL+"/sections", schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacher.ID,
+			ClassroomID:     room.ID,
+			SectionCode:     "001",
+			StartTime:       "08:00:00",
+			DurationMinutes: 50,
+			MaxEnrollment:   30,
+			Days:            []string{"monday"},
+		})
+		defer resp.Body.Close()
+
+		var problem ErrorResponse
+
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "term_id" {
+			t.Errorf("Expected status %d with a term_id error, got %d with %+v", http.StatusBadRequest, resp.StatusCode, problem.Errors)
+		}
+	})
+
 	// Test missing required fields
 	t.Run("MissingRequiredFields", func(t *testing.T) {
 		t.Log("Creating a student with missing required fields...")
@@ -1018,3 +1246,2295 @@ func TestNonExistentResources(t *testing.T) {
 		}
 	})
 }
+
+func TestTerms(t *testing.T) {
+	t.Log("===== TESTING ACADEMIC TERMS =====")
+
+	teachers := getTeachers(t)
+	subjects := getSubjects(t)
+	classrooms := getClassrooms(t)
+
+	if len(teachers) < 1 || len(subjects) < 1 || len(classrooms) < 1 {
+		t.Skip("Missing required entities for term test")
+	}
+
+	now := time.Now()
+
+	openTerm := createTerm(t, schema.Term{
+		Code:                 "TEST-OPEN",
+		Name:                 "Open Test Term",
+		StartDate:            now.Format(time.DateOnly),
+		EndDate:              now.AddDate(0, 4, 0).Format(time.DateOnly),
+		RegistrationOpensAt:  now.Add(-time.Hour),
+		RegistrationClosesAt: now.AddDate(0, 1, 0),
+	})
+	t.Logf("Created term with ID: %d\n", openTerm.ID)
+
+	closedTerm := createTerm(t, schema.Term{
+		Code:                 "TEST-CLOSED",
+		Name:                 "Closed Test Term",
+		StartDate:            now.AddDate(0, -6, 0).Format(time.DateOnly),
+		EndDate:              now.AddDate(0, -2, 0).Format(time.DateOnly),
+		RegistrationOpensAt:  now.AddDate(0, -7, 0),
+		RegistrationClosesAt: now.AddDate(0, -6, 0),
+	})
+	t.Logf("Created term with ID: %d\n", closedTerm.ID)
+
+	// The same section code and time slot may be reused in every term
+	sectionReq := schema.CreateSectionRequest{
+		TermID:          &openTerm.ID,
+		SubjectID:       subjects[0].ID,
+		TeacherID:       teachers[0].ID,
+		ClassroomID:     classrooms[0].ID,
+		SectionCode:     "001",
+		StartTime:       "08:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   30,
+		Days:            []string{"monday", "wednesday", "friday"},
+	}
+
+	openSection, err := createSection(t, sectionReq)
+	if err != nil {
+		t.Fatalf("Failed to create section in open term: %v", err)
+	}
+
+	sectionReq.TermID = &closedTerm.ID
+
+	closedSection, err := createSection(t, sectionReq)
+	if err != nil {
+		t.Fatalf("Failed to create section in closed term: %v", err)
+	}
+
+	var termSections []schema.Section
+
+	getJSON(t, fmt.Sprintf("%s/sections?term_id=%d", apiURL, openTerm.ID), &termSections)
+
+	if len(termSections) != 1 || termSections[0].ID != openSection.ID {
+		t.Errorf("Expected only section %d in term %d, got %+v", openSection.ID, openTerm.ID, termSections)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "term_test_001",
+		FirstName: "Term",
+		LastName:  "Test",
+		Email:     "term.test@university.edu",
+	})
+
+	if _, err := enrollStudent(t, student.ID, openSection.ID); err != nil {
+		t.Fatalf("Failed to enroll student in open term: %v", err)
+	}
+
+	if _, err := enrollStudent(t, student.ID, closedSection.ID); err == nil {
+		t.Errorf("Expected enrollment to fail because registration is closed")
+	}
+
+	var schedule []schema.ScheduleItem
+
+	getJSON(t, fmt.Sprintf("%s/students/%d/schedule?term_id=%d", apiURL, student.ID, closedTerm.ID), &schedule)
+
+	if len(schedule) != 0 {
+		t.Errorf("Expected empty schedule for closed term, got %d items", len(schedule))
+	}
+
+	getJSON(t, fmt.Sprintf("%s/students/%d/schedule?term_id=%d", apiURL, student.ID, openTerm.ID), &schedule)
+
+	if len(schedule) != 1 || schedule[0].SectionID != openSection.ID {
+		t.Errorf("Expected section %d in open term schedule, got %+v", openSection.ID, schedule)
+	}
+}
+
+func TestResourceLifecycle(t *testing.T) {
+	t.Log("===== TESTING UPDATE AND DELETE LIFECYCLE =====")
+
+	teacher := createTeacher(t, "Lifecycle", "Teacher", "lifecycle.teacher@university.edu")
+	subject := createSubject(t, "LIFE101", "Lifecycle Studies", "")
+	classroom := createClassroom(t, "Lifecycle Hall", "1", 20)
+	teacherURL := fmt.Sprintf("%s/teachers/%d", apiURL, teacher.ID)
+
+	t.Run("PatchAndPut", func(t *testing.T) {
+		resp := doJSON(t, http.MethodPatch, teacherURL, map[string]string{"email": "lifecycle.patched@university.edu"})
+		defer resp.Body.Close()
+
+		var patched schema.Teacher
+
+		if err := json.NewDecoder(resp.Body).Decode(&patched); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK || patched.Email != "lifecycle.patched@university.edu" || patched.FirstName != "Lifecycle" {
+			t.Errorf("Unexpected PATCH result (status %d): %+v", resp.StatusCode, patched)
+		}
+
+		resp = doJSON(t, http.MethodPut, teacherURL, schema.Teacher{FirstName: "Only"})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected PUT with missing fields to fail with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		}
+
+		var fetched schema.Teacher
+
+		getJSON(t, teacherURL, &fetched)
+
+		if fetched.Email != "lifecycle.patched@university.edu" {
+			t.Errorf("Expected patched email to be stored, got %q", fetched.Email)
+		}
+	})
+
+	t.Run("DeleteReferenced", func(t *testing.T) {
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacher.ID,
+			ClassroomID:     classroom.ID,
+			SectionCode:     "001",
+			StartTime:       "20:00:00",
+			DurationMinutes: 50,
+			MaxEnrollment:   10,
+			Days:            []string{"friday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		resp := doJSON(t, http.MethodDelete, teacherURL, nil)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected deleting a referenced teacher to fail with %d, got %d", http.StatusConflict, resp.StatusCode)
+		}
+
+		resp = doJSON(t, http.MethodDelete, teacherURL+"?cascade=true", nil)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected cascading delete to succeed, got %d", resp.StatusCode)
+		}
+
+		for _, url := range []string{teacherURL, fmt.Sprintf("%s/sections/%d", apiURL, section.ID)} {
+			resp := doJSON(t, http.MethodGet, url, nil)
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected %s to be gone, got %d", url, resp.StatusCode)
+			}
+		}
+	})
+
+	// Components taught by another teacher or held in another room go with their lecture
+	t.Run("DeleteLinkedComponents", func(t *testing.T) {
+		assistant := createTeacher(t, "Lifecycle", "Assistant", "lifecycle.assistant@university.edu")
+		lab := createClassroom(t, "Lifecycle Hall", "L1", 20)
+		student := createStudent(t, schema.CreateStudentRequest{
+			StudentID: "lifecycle_001",
+			FirstName: "Lifecycle",
+			LastName:  "Student",
+			Email:     "lifecycle.student@university.edu",
+		})
+
+		for i, owner := range []string{"teachers", "classrooms"} {
+			lecturer := createTeacher(t, "Lifecycle", fmt.Sprintf("Lecturer%d", i+1), fmt.Sprintf("lifecycle.lecturer%d@university.edu", i+1))
+			hall := createClassroom(t, "Lifecycle Hall", fmt.Sprintf("H%d", i+1), 20)
+
+			lecture, err := createSection(t, schema.CreateSectionRequest{
+				SubjectID:       subject.ID,
+				TeacherID:       lecturer.ID,
+				ClassroomID:     hall.ID,
+				SectionCode:     fmt.Sprintf("1%02d", i+1),
+				StartTime:       "08:00:00",
+				DurationMinutes: 50,
+				MaxEnrollment:   10,
+				Days:            []string{"monday"},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create lecture: %v", err)
+			}
+
+			component, err := createSection(t, schema.CreateSectionRequest{
+				SubjectID:       subject.ID,
+				TeacherID:       assistant.ID,
+				ClassroomID:     lab.ID,
+				SectionCode:     fmt.Sprintf("L%02d", i+1),
+				ComponentType:   "lab",
+				ParentSectionID: &lecture.ID,
+				StartTime:       "10:00:00",
+				DurationMinutes: 50,
+				MaxEnrollment:   10,
+				Days:            []string{"monday"},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create lab: %v", err)
+			}
+
+			resp := doJSON(t, http.MethodPost, apiURL+"/enrollments", handlers.EnrollmentRequest{
+				StudentID: student.ID, SectionID: lecture.ID, ComponentSectionIDs: []int{component.ID},
+			})
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("Expected the lecture and lab to be enrolled, got %d", resp.StatusCode)
+			}
+
+			ownerID := lecturer.ID
+			if owner == "classrooms" {
+				ownerID = hall.ID
+			}
+
+			resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/%s/%d?cascade=true", apiURL, owner, ownerID), nil)
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected cascading delete of %s %d to succeed, got %d", owner, ownerID, resp.StatusCode)
+			}
+
+			for _, id := range []int{lecture.ID, component.ID} {
+				resp := doJSON(t, http.MethodGet, fmt.Sprintf("%s/sections/%d", apiURL, id), nil)
+				resp.Body.Close()
+
+				if resp.StatusCode != http.StatusNotFound {
+					t.Errorf("Expected section %d to be gone with %s %d, got %d", id, owner, ownerID, resp.StatusCode)
+				}
+			}
+
+			if schedule := getStudentSchedule(t, student.ID); len(schedule) != 0 {
+				t.Errorf("Expected the enrollments to be gone with %s %d, got %d schedule items", owner, ownerID, len(schedule))
+			}
+		}
+	})
+}
+
+func TestTeacherDoubleBooking(t *testing.T) {
+	t.Log("===== TESTING TEACHER DOUBLE-BOOKING =====")
+
+	teacher := createTeacher(t, "Busy", "Teacher", "busy.teacher@university.edu")
+	subject := createSubject(t, "BUSY101", "Scheduling Basics", "")
+	roomA := createClassroom(t, "Booking Hall", "A", 30)
+	roomB := createClassroom(t, "Booking Hall", "B", 30)
+
+	first, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     roomA.ID,
+		SectionCode:     "001",
+		StartTime:       "11:00:00",
+		DurationMinutes: 80,
+		MaxEnrollment:   20,
+		Days:            []string{"tuesday", "thursday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create first section: %v", err)
+	}
+
+	resp, err := postJSON(t, apiURL+"/sections", schema.CreateSectionRequest{
+		TermID:          defaultTerm(t),
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     roomB.ID,
+		SectionCode:     "002",
+		StartTime:       "12:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"thursday"},
+	})
+	if err != nil {
+		t.Fatalf("Error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var conflict handlers.ConflictResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].SectionID != first.ID {
+		t.Errorf("Expected conflict with section %d, got %+v", first.ID, conflict)
+	}
+
+	t.Logf("Server rejected double-booking: %s", conflict.Detail)
+
+	// Same time on other days is fine
+	if _, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     roomB.ID,
+		SectionCode:     "003",
+		StartTime:       "12:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"monday", "wednesday"},
+	}); err != nil {
+		t.Errorf("Expected non-overlapping section to be created: %v", err)
+	}
+}
+
+func TestClassroomDoubleBooking(t *testing.T) {
+	t.Log("===== TESTING CLASSROOM DOUBLE-BOOKING =====")
+
+	teacherA := createTeacher(t, "Room", "Holder", "room.holder@university.edu")
+	teacherB := createTeacher(t, "Room", "Seeker", "room.seeker@university.edu")
+	subject := createSubject(t, "ROOM101", "Room Management", "")
+	room := createClassroom(t, "Occupancy Hall", "100", 30)
+
+	first, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacherA.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "13:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"monday", "wednesday", "friday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create first section: %v", err)
+	}
+
+	resp, err := postJSON(t, apiURL+"/sections", schema.CreateSectionRequest{
+		TermID:          defaultTerm(t),
+		SubjectID:       subject.ID,
+		TeacherID:       teacherB.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "002",
+		StartTime:       "13:30:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"friday"},
+	})
+	if err != nil {
+		t.Fatalf("Error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var conflict handlers.ConflictResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].SectionID != first.ID {
+		t.Errorf("Expected conflict with section %d, got %+v", first.ID, conflict)
+	}
+
+	var bookings []schema.Booking
+
+	getJSON(t, fmt.Sprintf("%s/classrooms/%d/occupancy", apiURL, room.ID), &bookings)
+
+	if len(bookings) != 3 {
+		t.Errorf("Expected 3 weekly bookings, got %d", len(bookings))
+	}
+}
+
+func TestClassroomCapacity(t *testing.T) {
+	t.Log("===== TESTING CLASSROOM CAPACITY =====")
+
+	teacher := createTeacher(t, "Capacity", "Teacher", "capacity.teacher@university.edu")
+	subject := createSubject(t, "CAP101", "Capacity Planning", "")
+	room := createClassroom(t, "Capacity Hall", "10", 25)
+
+	resp, err := postJSON(t, apiURL+"/sections", schema.CreateSectionRequest{
+		TermID:          defaultTerm(t),
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "15:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   30,
+		Days:            []string{"monday"},
+	})
+	if err != nil {
+		t.Fatalf("Error making request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected oversized section to fail with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "15:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   25,
+		Days:            []string{"monday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section that fits the classroom: %v", err)
+	}
+
+	resp = doJSON(t, http.MethodPatch, fmt.Sprintf("%s/classrooms/%d", apiURL, room.ID), map[string]int{"capacity": 20})
+	defer resp.Body.Close()
+
+	var conflict handlers.CapacityConflictResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected shrinking the classroom to fail with %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	if len(conflict.Sections) != 1 || conflict.Sections[0].ID != section.ID {
+		t.Errorf("Expected section %d to be reported, got %+v", section.ID, conflict.Sections)
+	}
+}
+
+func TestWaitlist(t *testing.T) {
+	t.Log("===== TESTING WAITLIST =====")
+
+	teacher := createTeacher(t, "Waitlist", "Teacher", "waitlist.teacher@university.edu")
+	subject := createSubject(t, "WAIT101", "Queueing Theory", "")
+	room := createClassroom(t, "Waitlist Hall", "1", 30)
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "16:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   1,
+		MaxWaitlist:     1,
+		Days:            []string{"tuesday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	var students []schema.Student
+
+	for i := range 3 {
+		students = append(students, createStudent(t, schema.CreateStudentRequest{
+			StudentID: fmt.Sprintf("waitlist_%03d", i),
+			FirstName: "Waitlist",
+			LastName:  fmt.Sprintf("Student%d", i),
+			Email:     fmt.Sprintf("waitlist.student%d@university.edu", i),
+		}))
+	}
+
+	waitlistURL := fmt.Sprintf("%s/sections/%d/waitlist", apiURL, section.ID)
+
+	resp := doJSON(t, http.MethodPost, waitlistURL, handlers.WaitlistRequest{StudentID: students[1].ID})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected joining the waitlist of an open section to fail with %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	if _, err := enrollStudent(t, students[0].ID, section.ID); err != nil {
+		t.Fatalf("Failed to enroll first student: %v", err)
+	}
+
+	resp = doJSON(t, http.MethodPost, waitlistURL, handlers.WaitlistRequest{StudentID: students[1].ID})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var entry schema.WaitlistEntry
+
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if entry.Position != 1 {
+		t.Errorf("Expected waitlist position 1, got %d", entry.Position)
+	}
+
+	resp = doJSON(t, http.MethodPost, waitlistURL, handlers.WaitlistRequest{StudentID: students[2].ID})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected joining a full waitlist to fail with %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, students[0].ID, section.ID), nil)
+	defer resp.Body.Close()
+
+	var drop handlers.DropResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&drop); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(drop.PromotedStudentIDs) != 1 || drop.PromotedStudentIDs[0] != students[1].ID {
+		t.Errorf("Expected student %d to be promoted, got %v", students[1].ID, drop.PromotedStudentIDs)
+	}
+
+	schedule := getStudentSchedule(t, students[1].ID)
+	if len(schedule) != 1 || schedule[0].SectionID != section.ID {
+		t.Errorf("Expected promoted student to be enrolled in section %d, got %+v", section.ID, schedule)
+	}
+
+	var waitlist []schema.WaitlistEntry
+
+	getJSON(t, waitlistURL, &waitlist)
+
+	if len(waitlist) != 0 {
+		t.Errorf("Expected empty waitlist after promotion, got %d entries", len(waitlist))
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	t.Log("===== TESTING PREREQUISITES =====")
+
+	teacher := createTeacher(t, "Prereq", "Teacher", "prereq.teacher@university.edu")
+	intro := createSubject(t, "PRQ101", "Mechanics", "")
+	calculus := createSubject(t, "PRQ110", "Calculus", "")
+	honors := createSubject(t, "PRQ111", "Honors Calculus", "")
+	advanced := createSubject(t, "PRQ201", "Electromagnetism", "")
+	room := createClassroom(t, "Prereq Hall", "1", 30)
+
+	resp := doJSON(t, http.MethodPut, fmt.Sprintf("%s/subjects/%d/requirements", apiURL, advanced.ID), schema.SetRequirementsRequest{
+		Prerequisites: [][]int{{intro.ID}, {calculus.ID, honors.ID}},
+	})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodPut, fmt.Sprintf("%s/subjects/%d/requirements", apiURL, intro.ID), schema.SetRequirementsRequest{
+		Prerequisites: [][]int{{advanced.ID}},
+	})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected prerequisite cycle to fail with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	var requirements schema.SubjectRequirements
+
+	getJSON(t, fmt.Sprintf("%s/subjects/%d/requirements", apiURL, advanced.ID), &requirements)
+
+	if len(requirements.Prerequisites) != 2 || len(requirements.Prerequisites[1].Subjects) != 2 {
+		t.Fatalf("Expected two prerequisite groups, got %+v", requirements.Prerequisites)
+	}
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       advanced.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "17:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   10,
+		Days:            []string{"wednesday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "prereq_001",
+		FirstName: "Prereq",
+		LastName:  "Student",
+		Email:     "prereq.student@university.edu",
+	})
+
+	resp = doJSON(t, http.MethodPost, fmt.Sprintf("%s/students/%d/completed-courses", apiURL, student.ID),
+		schema.CompletedCourseRequest{SubjectID: intro.ID})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodPost, apiURL+"/enrollments", handlers.EnrollmentRequest{StudentID: student.ID, SectionID: section.ID})
+	defer resp.Body.Close()
+
+	var unmet handlers.RequirementsResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&unmet); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	if len(unmet.Unmet) != 1 || len(unmet.Unmet[0].Subjects) != 2 {
+		t.Errorf("Expected the calculus group to be reported, got %+v", unmet.Unmet)
+	}
+
+	resp = doJSON(t, http.MethodPost, fmt.Sprintf("%s/students/%d/completed-courses", apiURL, student.ID),
+		schema.CompletedCourseRequest{SubjectID: honors.ID})
+	resp.Body.Close()
+
+	if _, err := enrollStudent(t, student.ID, section.ID); err != nil {
+		t.Errorf("Expected enrollment to succeed once requirements are met: %v", err)
+	}
+}
+
+func TestCalendarExport(t *testing.T) {
+	t.Log("===== TESTING ICALENDAR EXPORT =====")
+
+	now := time.Now()
+
+	term := createTerm(t, schema.Term{
+		Code:                 "TEST-ICS",
+		Name:                 "Calendar Test Term",
+		StartDate:            "2030-09-02",
+		EndDate:              "2030-12-13",
+		RegistrationOpensAt:  now.Add(-time.Hour),
+		RegistrationClosesAt: now.AddDate(0, 1, 0),
+	})
+
+	teacher := createTeacher(t, "Calendar", "Teacher", "calendar.teacher@university.edu")
+	subject := createSubject(t, "ICS101", "Calendar Studies", "")
+	room := createClassroom(t, "Calendar Hall", "7", 30)
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		TermID:          &term.ID,
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "10:00:00",
+		DurationMinutes: 80,
+		MaxEnrollment:   30,
+		Days:            []string{"tuesday", "thursday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "ics_001",
+		FirstName: "Calendar",
+		LastName:  "Student",
+		Email:     "calendar.student@university.edu",
+	})
+
+	if _, err := enrollStudent(t, student.ID, section.ID); err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("%s/students/%d/schedule.ics", apiURL, student.ID))
+	if err != nil {
+		t.Fatalf("Failed to download calendar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %s", contentType)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+
+	calendar := string(body)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		fmt.Sprintf("UID:section-%d-room-%d-1000-80min-student-%d@course-scheduling\r\n", section.ID, room.ID, student.ID),
+		"DTSTART:20300903T100000\r\n",
+		"DTEND:20300903T112000\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20301213T235959\r\n",
+		"LOCATION:Calendar Hall 7\r\n",
+		"ORGANIZER;CN=\"Calendar Teacher\":mailto:calendar.teacher@university.edu\r\n",
+	} {
+		if !strings.Contains(calendar, want) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", want, calendar)
+		}
+	}
+
+	// A meeting added before the existing one leaves the existing event's UID alone
+	resp = doJSON(t, http.MethodPatch, fmt.Sprintf("%s/sections/%d", apiURL, section.ID), map[string]any{
+		"meetings": []schema.Meeting{
+			{ClassroomID: room.ID, StartTime: "08:00:00", DurationMinutes: 50, Days: []string{"monday"}},
+			{ClassroomID: room.ID, StartTime: "10:00:00", DurationMinutes: 80, Days: []string{"tuesday", "thursday"}},
+		},
+	})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected adding a meeting to succeed, got %d", resp.StatusCode)
+	}
+
+	calendar = string(downloadCalendar(t, student.ID))
+
+	for _, want := range []string{
+		fmt.Sprintf("UID:section-%d-room-%d-0800-50min-student-%d@course-scheduling\r\n", section.ID, room.ID, student.ID),
+		fmt.Sprintf("UID:section-%d-room-%d-1000-80min-student-%d@course-scheduling\r\n", section.ID, room.ID, student.ID),
+	} {
+		if !strings.Contains(calendar, want) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", want, calendar)
+		}
+	}
+}
+
+func TestPagination(t *testing.T) {
+	t.Log("===== TESTING PAGINATION =====")
+
+	for _, code := range []string{"PAGE101", "PAGE102", "PAGE103"} {
+		createSubject(t, code, "Pagination "+code, "")
+	}
+
+	var codes []string
+
+	next := apiURL + "/subjects?code=PAGE&sort=-code&limit=2"
+
+	for pages := 0; next != ""; pages++ {
+		if pages > 2 {
+			t.Fatal("Expected pagination to stop after two pages")
+		}
+
+		resp, err := http.Get(next)
+		if err != nil {
+			t.Fatalf("Failed to get subjects: %v", err)
+		}
+
+		var subjects []schema.Subject
+
+		if err := json.NewDecoder(resp.Body).Decode(&subjects); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		resp.Body.Close()
+
+		for _, subject := range subjects {
+			codes = append(codes, subject.Code)
+		}
+
+		next = ""
+
+		if link := resp.Header.Get("Link"); link != "" {
+			target, _, _ := strings.Cut(strings.TrimPrefix(link, "<"), ">")
+			next = strings.TrimSuffix(apiURL, "/api") + target
+		}
+	}
+
+	if strings.Join(codes, ",") != "PAGE103,PAGE102,PAGE101" {
+		t.Errorf("Expected subjects in descending code order across pages, got %v", codes)
+	}
+
+	resp, err := http.Get(apiURL + "/subjects?sort=description")
+	if err != nil {
+		t.Fatalf("Failed to get subjects: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected sorting by a non-whitelisted column to fail with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestBulkImport(t *testing.T) {
+	t.Log("===== TESTING BULK IMPORT =====")
+
+	importTeachers := func(query, contentType, body string) (int, handlers.ImportResponse) {
+		resp, err := http.Post(apiURL+"/import/teachers"+query, contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to import teachers: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var report handlers.ImportResponse
+
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		return resp.StatusCode, report
+	}
+
+	status, report := importTeachers("", "text/csv", "first_name,last_name,email\n"+
+		"Ada,Import,ada.import@university.edu\n"+
+		",Import,missing.name@university.edu\n"+
+		"Ada,Again,ada.import@university.edu\n")
+
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, status)
+	}
+
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 4 {
+		t.Errorf("Expected errors on lines 3 and 4, got %+v", report.Errors)
+	}
+
+	valid := `{"first_name": "Ada", "last_name": "Import", "email": "ada.import@university.edu"}` + "\n" +
+		`{"first_name": "Alan", "last_name": "Import", "email": "alan.import@university.edu"}` + "\n"
+
+	status, report = importTeachers("?dry_run=true", "application/x-ndjson", valid)
+	if status != http.StatusOK || report.Imported != 2 || !report.DryRun {
+		t.Fatalf("Expected dry run to validate 2 rows, got status %d and %+v", status, report)
+	}
+
+	var teachers []schema.Teacher
+
+	getJSON(t, apiURL+"/teachers?email=ada.import@university.edu", &teachers)
+
+	if len(teachers) != 0 {
+		t.Fatalf("Expected dry run not to create teachers, found %d", len(teachers))
+	}
+
+	status, report = importTeachers("", "application/x-ndjson", valid)
+	if status != http.StatusCreated || report.Imported != 2 {
+		t.Fatalf("Expected 2 imported teachers, got status %d and %+v", status, report)
+	}
+
+	getJSON(t, apiURL+"/teachers?email=ada.import@university.edu", &teachers)
+
+	if len(teachers) != 1 {
+		t.Errorf("Expected imported teacher to be stored, found %d", len(teachers))
+	}
+}
+
+func TestTimetableSolver(t *testing.T) {
+	t.Log("===== TESTING TIMETABLE SOLVER =====")
+
+	now := time.Now()
+
+	term := createTerm(t, schema.Term{
+		Code:                 "TEST-SOLVE",
+		Name:                 "Solver Test Term",
+		StartDate:            "2031-01-13",
+		EndDate:              "2031-05-02",
+		RegistrationOpensAt:  now.Add(-time.Hour),
+		RegistrationClosesAt: now.AddDate(0, 1, 0),
+	})
+
+	teacher := createTeacher(t, "Solver", "Teacher", "solver.teacher@university.edu")
+	lecture := createSubject(t, "SLV101", "Solver Lecture", "")
+	seminar := createSubject(t, "SLV201", "Solver Seminar", "")
+	createClassroom(t, "Solver Hall", "1", 60)
+
+	availability := []schema.AvailabilityWindow{
+		{Day: "tuesday", StartTime: "13:00", EndTime: "17:00"},
+		{Day: "thursday", StartTime: "13:00", EndTime: "17:00"},
+	}
+
+	resp := doJSON(t, http.MethodPut, fmt.Sprintf("%s/teachers/%d/availability", apiURL, teacher.ID), availability)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d setting availability, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	demands := schema.SolveTimetableRequest{
+		Demands: []schema.SectionDemand{
+			{SectionCode: "001", Pattern: "TTh", SubjectID: lecture.ID, TeacherID: teacher.ID, ExpectedSize: 50, DurationMinutes: 80},
+			{SectionCode: "002", Pattern: "TTh", SubjectID: lecture.ID, TeacherID: teacher.ID, ExpectedSize: 50, DurationMinutes: 80},
+			{SectionCode: "001", Pattern: "MWF", SubjectID: seminar.ID, TeacherID: teacher.ID, ExpectedSize: 20, DurationMinutes: 50},
+			{SectionCode: "002", Pattern: "TTh", SubjectID: seminar.ID, TeacherID: teacher.ID, ExpectedSize: 100000, DurationMinutes: 50},
+		},
+	}
+
+	resp, err := postJSON(t, fmt.Sprintf("%s/terms/%d/timetable/solve", apiURL, term.ID), demands)
+	if err != nil {
+		t.Fatalf("Failed to solve timetable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var plan schema.TimetablePlan
+
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		t.Fatalf("Failed to decode plan: %v", err)
+	}
+
+	if len(plan.Sections) != 2 {
+		t.Fatalf("Expected 2 placed sections, got %d: %+v", len(plan.Sections), plan)
+	}
+
+	if len(plan.Unplaced) != 2 {
+		t.Fatalf("Expected 2 unplaced demands, got %d: %+v", len(plan.Unplaced), plan.Unplaced)
+	}
+
+	for _, section := range plan.Sections {
+		if section.StartTime < "13:00:00" || section.StartTime > "15:40:00" {
+			t.Errorf("Section %s starts at %s, outside the teacher's availability", section.SectionCode, section.StartTime)
+		}
+	}
+
+	if plan.Sections[0].StartTime == plan.Sections[1].StartTime {
+		t.Errorf("Expected the teacher's sections at different times, both start at %s", plan.Sections[0].StartTime)
+	}
+
+	commitURL := fmt.Sprintf("%s/terms/%d/timetable/commit", apiURL, term.ID)
+
+	resp, err = postJSON(t, commitURL, schema.CommitTimetableRequest{Sections: plan.Sections})
+	if err != nil {
+		t.Fatalf("Failed to commit timetable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var sections []schema.Section
+
+	getJSON(t, fmt.Sprintf("%s/sections?term_id=%d", apiURL, term.ID), &sections)
+
+	if len(sections) != 2 {
+		t.Errorf("Expected 2 sections in the term, got %d", len(sections))
+	}
+
+	// Committing the same plan again double-books the teacher, so nothing is created
+	resp, err = postJSON(t, commitURL, schema.CommitTimetableRequest{Sections: plan.Sections})
+	if err != nil {
+		t.Fatalf("Failed to commit timetable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	getJSON(t, fmt.Sprintf("%s/sections?term_id=%d", apiURL, term.ID), &sections)
+
+	if len(sections) != 2 {
+		t.Errorf("Expected 2 sections in the term after a failed commit, got %d", len(sections))
+	}
+}
+
+func TestScheduleOptions(t *testing.T) {
+	t.Log("===== TESTING SCHEDULE OPTIONS =====")
+
+	now := time.Now()
+
+	term := createTerm(t, schema.Term{
+		Code:                 "TEST-OPTIONS",
+		Name:                 "Schedule Options Test Term",
+		StartDate:            "2031-09-01",
+		EndDate:              "2031-12-12",
+		RegistrationOpensAt:  now.Add(-time.Hour),
+		RegistrationClosesAt: now.AddDate(0, 1, 0),
+	})
+
+	firstTeacher := createTeacher(t, "Options", "First", "options.first@university.edu")
+	secondTeacher := createTeacher(t, "Options", "Second", "options.second@university.edu")
+	algebra := createSubject(t, "OPT101", "Options Algebra", "")
+	biology := createSubject(t, "OPT102", "Options Biology", "")
+	chemistry := createSubject(t, "OPT103", "Options Chemistry", "")
+	firstRoom := createClassroom(t, "Options Hall", "1", 30)
+	secondRoom := createClassroom(t, "Options Hall", "2", 30)
+
+	newSection := func(subjectID, teacherID, roomID int, code, start string, duration int, days []string) schema.Section {
+		section, err := createSection(t, schema.CreateSectionRequest{
+			TermID:          &term.ID,
+			SubjectID:       subjectID,
+			TeacherID:       teacherID,
+			ClassroomID:     roomID,
+			SectionCode:     code,
+			StartTime:       start,
+			DurationMinutes: duration,
+			MaxEnrollment:   30,
+			Days:            days,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		return section
+	}
+
+	mwf := []string{"monday", "wednesday", "friday"}
+	tth := []string{"tuesday", "thursday"}
+
+	algebraEarly := newSection(algebra.ID, firstTeacher.ID, firstRoom.ID, "001", "08:00:00", 50, mwf)
+	algebraLate := newSection(algebra.ID, firstTeacher.ID, firstRoom.ID, "002", "10:00:00", 80, tth)
+	newSection(biology.ID, secondTeacher.ID, secondRoom.ID, "001", "10:00:00", 80, tth)
+	biologyMWF := newSection(biology.ID, secondTeacher.ID, secondRoom.ID, "002", "10:00:00", 50, mwf)
+	chemistrySection := newSection(chemistry.ID, firstTeacher.ID, firstRoom.ID, "001", "13:00:00", 50, mwf)
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "options_001",
+		FirstName: "Options",
+		LastName:  "Student",
+		Email:     "options.student@university.edu",
+	})
+
+	if _, err := enrollStudent(t, student.ID, chemistrySection.ID); err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/students/%d/schedule-options", apiURL, student.ID)
+
+	resp, err := postJSON(t, url, schema.ScheduleOptionsRequest{
+		SubjectIDs: []int{algebra.ID, biology.ID},
+		TermID:     &term.ID,
+		Preferences: schema.SchedulePreferences{
+			NoClassesBefore: "09:00",
+			FreeDays:        []string{"friday"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to request schedule options: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var options schema.ScheduleOptionsResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&options); err != nil {
+		t.Fatalf("Failed to decode schedule options: %v", err)
+	}
+
+	// Both TTh 10:00 sections clash, so three of the four combinations remain
+	if len(options.Options) != 3 {
+		t.Fatalf("Expected 3 schedule options, got %d: %+v", len(options.Options), options)
+	}
+
+	best := options.Options[0]
+
+	if best.Sections[0].SectionID != algebraLate.ID || best.Sections[1].SectionID != biologyMWF.ID {
+		t.Errorf("Expected the best option to pick sections %d and %d, got %+v", algebraLate.ID, biologyMWF.ID, best.Sections)
+	}
+
+	if len(best.UnmetPreferences) != 1 || best.UnmetPreferences[0] != "Classes on friday" {
+		t.Errorf("Expected only the free Friday preference to be unmet, got %v", best.UnmetPreferences)
+	}
+
+	if last := options.Options[2]; last.Sections[0].SectionID != algebraEarly.ID || last.Score <= best.Score {
+		t.Errorf("Expected the early algebra section to rank last, got %+v", last)
+	}
+
+	// A subject the student is already taking cannot be scheduled again
+	resp, err = postJSON(t, url, schema.ScheduleOptionsRequest{
+		SubjectIDs: []int{algebra.ID, chemistry.ID},
+		TermID:     &term.ID,
+	})
+	if err != nil {
+		t.Fatalf("Failed to request schedule options: %v", err)
+	}
+	defer resp.Body.Close()
+
+	options = schema.ScheduleOptionsResponse{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&options); err != nil {
+		t.Fatalf("Failed to decode schedule options: %v", err)
+	}
+
+	if len(options.Options) != 0 || len(options.Unavailable) != 1 || options.Unavailable[0].SubjectID != chemistry.ID {
+		t.Errorf("Expected chemistry to be reported as unavailable, got %+v", options)
+	}
+}
+
+func TestSectionSwap(t *testing.T) {
+	t.Log("===== TESTING SECTION SWAP =====")
+
+	firstTeacher := createTeacher(t, "Swap", "First", "swap.first@university.edu")
+	secondTeacher := createTeacher(t, "Swap", "Second", "swap.second@university.edu")
+	subject := createSubject(t, "SWP101", "Swapping Basics", "")
+	other := createSubject(t, "SWP102", "Swapping Theory", "")
+	firstRoom := createClassroom(t, "Swap Hall", "1", 30)
+	secondRoom := createClassroom(t, "Swap Hall", "2", 30)
+
+	newSection := func(subjectID, teacherID, roomID int, code, start string, maxEnrollment int, days []string) schema.Section {
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subjectID,
+			TeacherID:       teacherID,
+			ClassroomID:     roomID,
+			SectionCode:     code,
+			StartTime:       start,
+			DurationMinutes: 50,
+			MaxEnrollment:   maxEnrollment,
+			Days:            days,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		return section
+	}
+
+	current := newSection(subject.ID, firstTeacher.ID, firstRoom.ID, "001", "09:00:00", 30, []string{"monday"})
+	sameTime := newSection(subject.ID, secondTeacher.ID, secondRoom.ID, "002", "09:00:00", 30, []string{"monday"})
+	full := newSection(subject.ID, firstTeacher.ID, firstRoom.ID, "003", "14:00:00", 1, []string{"wednesday"})
+	clashing := newSection(subject.ID, firstTeacher.ID, firstRoom.ID, "004", "11:00:00", 30, []string{"tuesday"})
+	otherSection := newSection(other.ID, secondTeacher.ID, secondRoom.ID, "001", "11:00:00", 30, []string{"tuesday"})
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "swap_001",
+		FirstName: "Swap",
+		LastName:  "Student",
+		Email:     "swap.student@university.edu",
+	})
+	blocker := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "swap_002",
+		FirstName: "Swap",
+		LastName:  "Blocker",
+		Email:     "swap.blocker@university.edu",
+	})
+
+	for _, enrollment := range []struct{ studentID, sectionID int }{
+		{student.ID, current.ID},
+		{student.ID, otherSection.ID},
+		{blocker.ID, full.ID},
+	} {
+		if _, err := enrollStudent(t, enrollment.studentID, enrollment.sectionID); err != nil {
+			t.Fatalf("Failed to enroll student: %v", err)
+		}
+	}
+
+	swapURL := fmt.Sprintf("%s/students/%d/swap", apiURL, student.ID)
+
+	// The new section overlaps the dropped one, which must not count as a conflict
+	resp := doJSON(t, http.MethodPost, swapURL, handlers.SwapRequest{DropSectionID: current.ID, EnrollSectionID: sameTime.ID})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var swap handlers.SwapResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&swap); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if swap.Enrollment.SectionID != sameTime.ID || swap.Enrollment.ID == 0 {
+		t.Errorf("Expected a new enrollment in section %d, got %+v", sameTime.ID, swap.Enrollment)
+	}
+
+	resp = doJSON(t, http.MethodPost, swapURL, handlers.SwapRequest{DropSectionID: sameTime.ID, EnrollSectionID: full.ID})
+	defer resp.Body.Close()
+
+	var errResp ErrorResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict || errResp.Code != "section_full" {
+		t.Errorf("Expected swapping into a full section to fail with %d and code section_full, got %d: %s",
+			http.StatusConflict, resp.StatusCode, errResp.Code)
+	}
+
+	resp = doJSON(t, http.MethodPost, swapURL, handlers.SwapRequest{DropSectionID: sameTime.ID, EnrollSectionID: clashing.ID})
+	defer resp.Body.Close()
+
+	var conflict handlers.ConflictResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].SectionID != otherSection.ID {
+		t.Errorf("Expected the conflict to name section %d, got %+v", otherSection.ID, conflict)
+	}
+
+	// Refused swaps leave the student's enrollments untouched
+	schedule := getStudentSchedule(t, student.ID)
+
+	enrolled := make(map[int]bool, len(schedule))
+	for _, item := range schedule {
+		enrolled[item.SectionID] = true
+	}
+
+	if len(schedule) != 2 || !enrolled[sameTime.ID] || !enrolled[otherSection.ID] {
+		t.Errorf("Expected enrollments in sections %d and %d, got %+v", sameTime.ID, otherSection.ID, schedule)
+	}
+}
+
+func TestBatchEnrollment(t *testing.T) {
+	t.Log("===== TESTING BATCH ENROLLMENT =====")
+
+	teacher := createTeacher(t, "Batch", "Teacher", "batch.teacher@university.edu")
+	otherTeacher := createTeacher(t, "Batch", "Other", "batch.other@university.edu")
+	room := createClassroom(t, "Batch Hall", "1", 30)
+	otherRoom := createClassroom(t, "Batch Hall", "2", 30)
+
+	var sections []schema.Section
+
+	for i, spec := range []struct {
+		teacherID, roomID int
+		start, day        string
+	}{
+		{teacher.ID, room.ID, "09:00:00", "monday"},
+		{otherTeacher.ID, otherRoom.ID, "09:00:00", "monday"},
+		{teacher.ID, room.ID, "10:00:00", "tuesday"},
+	} {
+		subject := createSubject(t, fmt.Sprintf("BAT10%d", i+1), fmt.Sprintf("Batch Subject %d", i+1), "")
+
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       spec.teacherID,
+			ClassroomID:     spec.roomID,
+			SectionCode:     "001",
+			StartTime:       spec.start,
+			DurationMinutes: 50,
+			MaxEnrollment:   30,
+			Days:            []string{spec.day},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		sections = append(sections, section)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "batch_001",
+		FirstName: "Batch",
+		LastName:  "Student",
+		Email:     "batch.student@university.edu",
+	})
+
+	// The second section clashes with the first one of the same batch
+	batch := handlers.BatchEnrollmentRequest{}
+	for _, section := range sections {
+		batch.Enrollments = append(batch.Enrollments, handlers.EnrollmentRequest{StudentID: student.ID, SectionID: section.ID})
+	}
+
+	batchEnroll := func(mode string, wantStatus int, wantItems []int) {
+		resp, err := postJSON(t, apiURL+"/enrollments/batch?mode="+mode, batch)
+		if err != nil {
+			t.Fatalf("Failed to enroll batch: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != wantStatus {
+			t.Errorf("Expected %s batch status %d, got %d", mode, wantStatus, resp.StatusCode)
+		}
+
+		var result handlers.BatchEnrollmentResponse
+
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(result.Results) != len(wantItems) {
+			t.Fatalf("Expected %d results, got %d", len(wantItems), len(result.Results))
+		}
+
+		for i, want := range wantItems {
+			if result.Results[i].Status != want {
+				t.Errorf("Expected %s item %d status %d, got %d (%s)", mode, i, want, result.Results[i].Status, result.Results[i].Error)
+			}
+		}
+	}
+
+	batchEnroll("atomic", http.StatusUnprocessableEntity,
+		[]int{http.StatusFailedDependency, http.StatusConflict, http.StatusFailedDependency})
+
+	if schedule := getStudentSchedule(t, student.ID); len(schedule) != 0 {
+		t.Errorf("Expected a failed atomic batch to enroll nothing, got %d enrollments", len(schedule))
+	}
+
+	batchEnroll("best_effort", http.StatusMultiStatus,
+		[]int{http.StatusCreated, http.StatusConflict, http.StatusCreated})
+
+	if schedule := getStudentSchedule(t, student.ID); len(schedule) != 2 {
+		t.Errorf("Expected 2 enrollments after a best effort batch, got %d", len(schedule))
+	}
+}
+
+func TestEnrollmentEligibility(t *testing.T) {
+	t.Log("===== TESTING ENROLLMENT ELIGIBILITY =====")
+
+	teacher := createTeacher(t, "Eligibility", "Teacher", "eligibility.teacher@university.edu")
+	otherTeacher := createTeacher(t, "Eligibility", "Other", "eligibility.other@university.edu")
+	subject := createSubject(t, "ELIG101", "Eligibility Basics", "")
+	room := createClassroom(t, "Eligibility Hall", "1", 30)
+	otherRoom := createClassroom(t, "Eligibility Hall", "2", 30)
+
+	newSection := func(teacherID, roomID int, code, start string, maxEnrollment int) schema.Section {
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacherID,
+			ClassroomID:     roomID,
+			SectionCode:     code,
+			StartTime:       start,
+			DurationMinutes: 50,
+			MaxEnrollment:   maxEnrollment,
+			Days:            []string{"thursday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		return section
+	}
+
+	enrolled := newSection(teacher.ID, room.ID, "001", "12:00:00", 30)
+	fullAndClashing := newSection(otherTeacher.ID, otherRoom.ID, "002", "12:00:00", 1)
+	open := newSection(teacher.ID, room.ID, "003", "15:00:00", 30)
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "eligibility_001",
+		FirstName: "Eligibility",
+		LastName:  "Student",
+		Email:     "eligibility.student@university.edu",
+	})
+	blocker := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "eligibility_002",
+		FirstName: "Eligibility",
+		LastName:  "Blocker",
+		Email:     "eligibility.blocker@university.edu",
+	})
+
+	if _, err := enrollStudent(t, student.ID, enrolled.ID); err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	if _, err := enrollStudent(t, blocker.ID, fullAndClashing.ID); err != nil {
+		t.Fatalf("Failed to enroll blocker: %v", err)
+	}
+
+	var eligibility schema.Eligibility
+
+	getJSON(t, fmt.Sprintf("%s/students/%d/eligibility?section_id=%d", apiURL, student.ID, fullAndClashing.ID), &eligibility)
+
+	if eligibility.Eligible {
+		t.Errorf("Expected section %d to be ineligible", fullAndClashing.ID)
+	}
+
+	var codes []string
+	for _, reason := range eligibility.Reasons {
+		codes = append(codes, reason.Code)
+	}
+
+	if strings.Join(codes, ",") != "schedule_conflict,section_full" {
+		t.Errorf("Expected schedule_conflict and section_full reasons, got %v", codes)
+	}
+
+	if len(eligibility.Conflicts) != 1 || eligibility.Conflicts[0].SectionID != enrolled.ID {
+		t.Errorf("Expected the conflict to name section %d, got %+v", enrolled.ID, eligibility.Conflicts)
+	}
+
+	var results []schema.Eligibility
+
+	getJSON(t, fmt.Sprintf("%s/students/%d/eligibility?section_ids=%d,%d", apiURL, student.ID, enrolled.ID, open.ID), &results)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Eligible || len(results[0].Reasons) != 1 || results[0].Reasons[0].Code != "already_enrolled" {
+		t.Errorf("Expected section %d to be reported as already enrolled, got %+v", enrolled.ID, results[0])
+	}
+
+	if !results[1].Eligible || len(results[1].Reasons) != 0 {
+		t.Errorf("Expected section %d to be eligible, got %+v", open.ID, results[1])
+	}
+
+	// Checking eligibility must not enroll the student
+	if schedule := getStudentSchedule(t, student.ID); len(schedule) != 1 {
+		t.Errorf("Expected 1 enrollment, got %d", len(schedule))
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	t.Log("===== TESTING PROBLEM DETAILS =====")
+
+	decodeProblem := func(resp *http.Response) ErrorResponse {
+		t.Helper()
+
+		if contentType := resp.Header.Get("Content-Type"); contentType != "application/problem+json" {
+			t.Errorf("Expected content type application/problem+json, got %q", contentType)
+		}
+
+		var problem ErrorResponse
+
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatalf("Failed to decode problem: %v", err)
+		}
+
+		if problem.Status != resp.StatusCode || problem.Type == "" || problem.Title == "" {
+			t.Errorf("Expected a complete problem with status %d, got %+v", resp.StatusCode, problem)
+		}
+
+		return problem
+	}
+
+	resp := doJSON(t, http.MethodPost, apiURL+"/teachers", schema.Teacher{FirstName: "Problem"})
+	defer resp.Body.Close()
+
+	problem := decodeProblem(resp)
+
+	if resp.StatusCode != http.StatusBadRequest || problem.Code != "validation_failed" {
+		t.Errorf("Expected status %d with code validation_failed, got %d with %q",
+			http.StatusBadRequest, resp.StatusCode, problem.Code)
+	}
+
+	var fields []string
+	for _, fieldErr := range problem.Errors {
+		fields = append(fields, fieldErr.Field+":"+fieldErr.Code)
+	}
+
+	if strings.Join(fields, ",") != "last_name:required,email:required" {
+		t.Errorf("Expected last_name and email to be required, got %v", fields)
+	}
+
+	teacher := createTeacher(t, "Problem", "Teacher", "problem.teacher@university.edu")
+	subject := createSubject(t, "PROB101", "Problem Solving", "")
+	room := createClassroom(t, "Problem Hall", "1", 30)
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "13:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   10,
+		Days:            []string{"friday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "problem_001",
+		FirstName: "Problem",
+		LastName:  "Student",
+		Email:     "problem.student@university.edu",
+	})
+
+	// The waitlist trigger raises a custom SQLSTATE that must surface as a stable code
+	resp = doJSON(t, http.MethodPost, fmt.Sprintf("%s/sections/%d/waitlist", apiURL, section.ID),
+		handlers.WaitlistRequest{StudentID: student.ID})
+	defer resp.Body.Close()
+
+	if problem := decodeProblem(resp); resp.StatusCode != http.StatusConflict || problem.Code != "section_has_open_seats" {
+		t.Errorf("Expected status %d with code section_has_open_seats, got %d with %q",
+			http.StatusConflict, resp.StatusCode, problem.Code)
+	}
+
+	resp = doJSON(t, http.MethodGet, fmt.Sprintf("%s/teachers/%d", apiURL, 999999), nil)
+	defer resp.Body.Close()
+
+	if problem := decodeProblem(resp); resp.StatusCode != http.StatusNotFound || problem.Code != "not_found" {
+		t.Errorf("Expected status %d with code not_found, got %d with %q", http.StatusNotFound, resp.StatusCode, problem.Code)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Log("===== TESTING AUTHENTICATION =====")
+
+	anonymous := &http.Client{Transport: baseTransport}
+
+	request := func(method, url, credential string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequest(method, url, http.NoBody)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+
+		resp, err := anonymous.Do(req)
+		if err != nil {
+			t.Fatalf("Error making request: %v", err)
+		}
+
+		return resp
+	}
+
+	expectStatus := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		defer resp.Body.Close()
+
+		var problem ErrorResponse
+
+		if code != "" {
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+		}
+
+		if resp.StatusCode != status || problem.Code != code {
+			t.Errorf("Expected status %d with code %q, got %d with %q", status, code, resp.StatusCode, problem.Code)
+		}
+	}
+
+	resp := request(http.MethodGet, apiURL+"/teachers", "")
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Error("Expected a WWW-Authenticate challenge without credentials")
+	}
+
+	expectStatus(resp, http.StatusUnauthorized, "unauthenticated")
+
+	expired, err := auth.SignHS256(auth.Claims{
+		Subject:   "integration-tests",
+		Role:      auth.RoleAdmin,
+		ExpiresAt: time.Now().Add(-time.Hour).Unix(),
+	}, jwtSecret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	expectStatus(request(http.MethodGet, apiURL+"/teachers", expired), http.StatusUnauthorized, "unauthenticated")
+	expectStatus(request(http.MethodGet, apiURL+"/teachers", "not-a-token"), http.StatusUnauthorized, "unauthenticated")
+	expectStatus(request(http.MethodGet, apiURL+"/teachers", "sk_00000000_unknown"), http.StatusUnauthorized, "unauthenticated")
+
+	// Keys are created by an admin, here the JWT principal set up in TestMain
+	created := createAPIKey(t, schema.CreateAPIKeyRequest{Name: "Registrar integration", Role: auth.RoleRegistrar})
+
+	if !strings.HasPrefix(created.Key, "sk_"+created.Prefix+"_") {
+		t.Errorf("Expected key to start with sk_%s_, got %q", created.Prefix, created.Key)
+	}
+
+	expectStatus(request(http.MethodGet, apiURL+"/teachers", created.Key), http.StatusOK, "")
+	expectStatus(request(http.MethodGet, apiURL+"/admin/api-keys", created.Key), http.StatusForbidden, "forbidden")
+
+	resp = doJSON(t, http.MethodGet, apiURL+"/admin/api-keys", nil)
+	defer resp.Body.Close()
+
+	var keys []schema.APIKey
+
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		t.Fatalf("Failed to decode API keys: %v", err)
+	}
+
+	listed := false
+	for _, key := range keys {
+		if key.ID == created.ID && key.Prefix == created.Prefix && key.LastUsedAt != nil {
+			listed = true
+		}
+	}
+
+	if !listed {
+		t.Errorf("Expected API key %d to be listed with its last use", created.ID)
+	}
+
+	resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/admin/api-keys/%d", apiURL, created.ID), nil)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d when revoking, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	expectStatus(request(http.MethodGet, apiURL+"/teachers", created.Key), http.StatusUnauthorized, "unauthenticated")
+
+	resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/admin/api-keys/%d", apiURL, created.ID), nil)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d when revoking twice, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	t.Log("===== TESTING AUTHORIZATION =====")
+
+	teacher := createTeacher(t, "Authz", "Teacher", "authz.teacher@university.edu")
+	otherTeacher := createTeacher(t, "Authz", "Other", "authz.other@university.edu")
+	subject := createSubject(t, "AUTHZ101", "Access Control", "")
+	room := createClassroom(t, "Authz Hall", "1", 30)
+
+	newSection := func(teacherID int, code, startTime string) schema.Section {
+		t.Helper()
+
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacherID,
+			ClassroomID:     room.ID,
+			SectionCode:     code,
+			StartTime:       startTime,
+			DurationMinutes: 50,
+			MaxEnrollment:   10,
+			Days:            []string{"monday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		return section
+	}
+
+	section := newSection(teacher.ID, "001", "08:00:00")
+	otherSection := newSection(otherTeacher.ID, "002", "10:00:00")
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "authz_001",
+		FirstName: "Authz",
+		LastName:  "Student",
+		Email:     "authz.student@university.edu",
+	})
+	otherStudent := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "authz_002",
+		FirstName: "Authz",
+		LastName:  "Other",
+		Email:     "authz.other.student@university.edu",
+	})
+
+	if _, err := enrollStudent(t, otherStudent.ID, section.ID); err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	resp := doJSON(t, http.MethodPost, apiURL+"/admin/api-keys", schema.CreateAPIKeyRequest{Name: "No owner", Role: auth.RoleStudent})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a student key without a student, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	studentKey := createAPIKey(t, schema.CreateAPIKeyRequest{Name: "Authz student", Role: auth.RoleStudent, StudentID: &student.ID}).Key
+	teacherKey := createAPIKey(t, schema.CreateAPIKeyRequest{Name: "Authz teacher", Role: auth.RoleTeacher, TeacherID: &teacher.ID}).Key
+	registrarKey := createAPIKey(t, schema.CreateAPIKeyRequest{Name: "Authz registrar", Role: auth.RoleRegistrar}).Key
+
+	tests := []struct {
+		name       string
+		credential string
+		method     string
+		url        string
+		data       any
+		status     int
+	}{
+		{"student reads own schedule", studentKey, http.MethodGet, fmt.Sprintf("%s/students/%d/schedule", apiURL, student.ID), nil, http.StatusOK},
+		{"student reads other schedule", studentKey, http.MethodGet, fmt.Sprintf("%s/students/%d/schedule", apiURL, otherStudent.ID), nil, http.StatusForbidden},
+		{"student lists students", studentKey, http.MethodGet, apiURL + "/students", nil, http.StatusForbidden},
+		{"student reads catalog", studentKey, http.MethodGet, apiURL + "/sections", nil, http.StatusOK},
+		{"student creates section", studentKey, http.MethodPost, apiURL + "/sections", schema.CreateSectionRequest{}, http.StatusForbidden},
+		{"student drops other student", studentKey, http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, otherStudent.ID, section.ID), nil, http.StatusForbidden},
+		{"student enrolls other student", studentKey, http.MethodPost, apiURL + "/enrollments", handlers.EnrollmentRequest{StudentID: otherStudent.ID, SectionID: otherSection.ID}, http.StatusForbidden},
+		{"student enrolls other student by key case", studentKey, http.MethodPost, apiURL + "/enrollments", json.RawMessage(fmt.Sprintf(`{"student_id":%d,"STUDENT_ID":%d,"section_id":%d}`, student.ID, otherStudent.ID, otherSection.ID)), http.StatusForbidden},
+		{"student waitlists other student by key case", studentKey, http.MethodPost, fmt.Sprintf("%s/sections/%d/waitlist", apiURL, section.ID), json.RawMessage(fmt.Sprintf(`{"student_id":%d,"STUDENT_ID":%d}`, student.ID, otherStudent.ID)), http.StatusForbidden},
+		{"student enrolls self", studentKey, http.MethodPost, apiURL + "/enrollments", handlers.EnrollmentRequest{StudentID: student.ID, SectionID: otherSection.ID}, http.StatusCreated},
+		{"student drops own section", studentKey, http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, student.ID, otherSection.ID), nil, http.StatusOK},
+		{"student reads roster", studentKey, http.MethodGet, fmt.Sprintf("%s/sections/%d/roster", apiURL, section.ID), nil, http.StatusForbidden},
+		{"teacher reads own roster", teacherKey, http.MethodGet, fmt.Sprintf("%s/sections/%d/roster", apiURL, section.ID), nil, http.StatusOK},
+		{"teacher reads other roster", teacherKey, http.MethodGet, fmt.Sprintf("%s/sections/%d/roster", apiURL, otherSection.ID), nil, http.StatusForbidden},
+		{"teacher reads student schedule", teacherKey, http.MethodGet, fmt.Sprintf("%s/students/%d/schedule", apiURL, otherStudent.ID), nil, http.StatusForbidden},
+		{"teacher creates subject", teacherKey, http.MethodPost, apiURL + "/subjects", schema.Subject{}, http.StatusForbidden},
+		{"registrar reads any schedule", registrarKey, http.MethodGet, fmt.Sprintf("%s/students/%d/schedule", apiURL, otherStudent.ID), nil, http.StatusOK},
+		{"registrar creates subject", registrarKey, http.MethodPost, apiURL + "/subjects", schema.Subject{}, http.StatusForbidden},
+		{"registrar manages API keys", registrarKey, http.MethodGet, apiURL + "/admin/api-keys", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		resp := doJSONAs(t, tt.credential, tt.method, tt.url, tt.data)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+
+	var roster []schema.Student
+
+	resp = doJSONAs(t, teacherKey, http.MethodGet, fmt.Sprintf("%s/sections/%d/roster", apiURL, section.ID), nil)
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&roster); err != nil {
+		t.Fatalf("Failed to decode roster: %v", err)
+	}
+
+	if len(roster) != 1 || roster[0].ID != otherStudent.ID {
+		t.Errorf("Expected the roster to hold student %d, got %+v", otherStudent.ID, roster)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	t.Log("===== TESTING AUDIT LOG =====")
+
+	since := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	teacher := createTeacher(t, "Audit", "Teacher", "audit.teacher@university.edu")
+	subject := createSubject(t, "AUDIT101", "Record Keeping", "")
+	room := createClassroom(t, "Audit Hall", "1", 30)
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       teacher.ID,
+		ClassroomID:     room.ID,
+		SectionCode:     "001",
+		StartTime:       "09:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   10,
+		Days:            []string{"tuesday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "audit_001",
+		FirstName: "Audit",
+		LastName:  "Student",
+		Email:     "audit.student@university.edu",
+	})
+
+	enrollment, err := enrollStudent(t, student.ID, section.ID)
+	if err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	// The drop is what a student might later dispute; its request ID ties it to the request
+	requestID := fmt.Sprintf("audit-drop-%d", enrollment.ID)
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, student.ID, section.ID), http.NoBody)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	req.Header.Set("X-Request-ID", requestID)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to drop section: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Request-ID") != requestID {
+		t.Fatalf("Expected status %d echoing request ID %q, got %d with %q",
+			http.StatusOK, requestID, resp.StatusCode, resp.Header.Get("X-Request-ID"))
+	}
+
+	var entries []schema.AuditEntry
+
+	getJSON(t, fmt.Sprintf("%s/audit?entity=enrollments&entity_id=%d&actor=integration-tests&since=%s&sort=id",
+		apiURL, enrollment.ID, since), &entries)
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected the enrollment to be created and deleted, got %d entries", len(entries))
+	}
+
+	created, deleted := entries[0], entries[1]
+
+	if created.Action != "create" || created.Before != nil || created.After == nil {
+		t.Errorf("Expected a create entry with only an after image, got %+v", created)
+	}
+
+	if deleted.Action != "delete" || deleted.After != nil || deleted.RequestID == nil || *deleted.RequestID != requestID {
+		t.Errorf("Expected a delete entry from request %q, got %+v", requestID, deleted)
+	}
+
+	var before schema.Enrollment
+
+	if err := json.Unmarshal(deleted.Before, &before); err != nil {
+		t.Fatalf("Failed to decode before image: %v", err)
+	}
+
+	if before.StudentID != student.ID || before.SectionID != section.ID {
+		t.Errorf("Expected the deleted enrollment of student %d in section %d, got %+v", student.ID, section.ID, before)
+	}
+
+	if deleted.ActorRole == nil || *deleted.ActorRole != "admin" {
+		t.Errorf("Expected the admin role to be recorded, got %v", deleted.ActorRole)
+	}
+
+	resp = doJSON(t, http.MethodGet, apiURL+"/audit?action=truncate", nil)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown action, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestConcurrentEnrollment(t *testing.T) {
+	t.Log("===== TESTING CONCURRENT ENROLLMENT =====")
+
+	const (
+		rounds   = 5
+		parallel = 8
+	)
+
+	subject := createSubject(t, "RACE101", "Concurrency", "")
+
+	// Every section meets at the same time in its own room with its own teacher,
+	// so a student can hold at most one of them
+	sections := make([]schema.Section, parallel)
+
+	for i := range sections {
+		teacher := createTeacher(t, "Race", fmt.Sprintf("Teacher%d", i), fmt.Sprintf("race.teacher%d@university.edu", i))
+		room := createClassroom(t, "Race Hall", strconv.Itoa(i+1), 30)
+
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacher.ID,
+			ClassroomID:     room.ID,
+			SectionCode:     fmt.Sprintf("%03d", i+1),
+			StartTime:       "14:00:00",
+			DurationMinutes: 50,
+			MaxEnrollment:   rounds,
+			Days:            []string{"wednesday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		sections[i] = section
+	}
+
+	for round := range rounds {
+		student := createStudent(t, schema.CreateStudentRequest{
+			StudentID: fmt.Sprintf("race_%03d", round),
+			FirstName: "Race",
+			LastName:  fmt.Sprintf("Student%d", round),
+			Email:     fmt.Sprintf("race.student%d@university.edu", round),
+		})
+
+		statuses := make([]int, parallel)
+		start := make(chan struct{})
+
+		var wg sync.WaitGroup
+
+		for i, section := range sections {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				data, _ := json.Marshal(handlers.EnrollmentRequest{StudentID: student.ID, SectionID: section.ID})
+
+				<-start
+
+				resp, err := http.Post(apiURL+"/enrollments", "application/json", bytes.NewReader(data))
+				if err != nil {
+					t.Errorf("Failed to enroll student: %v", err)
+
+					return
+				}
+				resp.Body.Close()
+
+				statuses[i] = resp.StatusCode
+			}()
+		}
+
+		close(start)
+		wg.Wait()
+
+		created, conflicts := 0, 0
+
+		for _, status := range statuses {
+			switch status {
+			case http.StatusCreated:
+				created++
+			case http.StatusConflict:
+				conflicts++
+			}
+		}
+
+		if created != 1 || conflicts != parallel-1 {
+			t.Errorf("Round %d: expected 1 enrollment and %d conflicts, got statuses %v", round, parallel-1, statuses)
+		}
+
+		if schedule := getStudentSchedule(t, student.ID); len(schedule) != 1 {
+			t.Errorf("Round %d: expected exactly one section in the schedule, got %d", round, len(schedule))
+		}
+	}
+}
+
+func TestMeetingExclusion(t *testing.T) {
+	t.Log("===== TESTING MEETING EXCLUSION CONSTRAINTS =====")
+
+	subject := createSubject(t, "MEET101", "Meeting Times", "")
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "meet_001",
+		FirstName: "Meeting",
+		LastName:  "Student",
+		Email:     "meeting.student@university.edu",
+	})
+
+	sections := make([]schema.Section, 2)
+
+	for i, start := range []string{"09:00:00", "11:00:00"} {
+		teacher := createTeacher(t, "Meeting", fmt.Sprintf("Teacher%d", i), fmt.Sprintf("meeting.teacher%d@university.edu", i))
+		room := createClassroom(t, "Meeting Hall", strconv.Itoa(i+1), 30)
+
+		section, err := createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       teacher.ID,
+			ClassroomID:     room.ID,
+			SectionCode:     fmt.Sprintf("%03d", i+1),
+			StartTime:       start,
+			DurationMinutes: 80,
+			MaxEnrollment:   10,
+			Days:            []string{"tuesday", "thursday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create section: %v", err)
+		}
+
+		if _, err := enrollStudent(t, student.ID, section.ID); err != nil {
+			t.Fatalf("Failed to enroll student: %v", err)
+		}
+
+		sections[i] = section
+	}
+
+	// Moving the second section onto the first one's time would double-book the enrolled student
+	resp := doJSON(t, http.MethodPatch, fmt.Sprintf("%s/sections/%d", apiURL, sections[1].ID),
+		map[string]any{"start_time": "09:30:00"})
+	defer resp.Body.Close()
+
+	var problem ErrorResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict || problem.Code != "student_schedule_conflict" {
+		t.Errorf("Expected status %d with code student_schedule_conflict, got %d with %q",
+			http.StatusConflict, resp.StatusCode, problem.Code)
+	}
+
+	// Back-to-back meetings do not overlap
+	resp = doJSON(t, http.MethodPatch, fmt.Sprintf("%s/sections/%d", apiURL, sections[1].ID),
+		map[string]any{"start_time": "10:20:00"})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d moving the section next to the other, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if schedule := getStudentSchedule(t, student.ID); len(schedule) != 2 {
+		t.Errorf("Expected both sections to stay in the schedule, got %d", len(schedule))
+	}
+}
+
+func TestMultiMeetingSections(t *testing.T) {
+	t.Log("===== TESTING MULTI-MEETING SECTIONS =====")
+
+	subject := createSubject(t, "LAB101", "Lecture and Lab", "")
+	teacher := createTeacher(t, "Lab", "Teacher", "lab.teacher@university.edu")
+	otherTeacher := createTeacher(t, "Lab", "Guest", "lab.guest@university.edu")
+	lectureHall := createClassroom(t, "Lab Building", "100", 40)
+	lab := createClassroom(t, "Lab Building", "B1", 20)
+	student := createStudent(t, schema.CreateStudentRequest{
+		StudentID: "lab_001",
+		FirstName: "Lab",
+		LastName:  "Student",
+		Email:     "lab.student@university.edu",
+	})
+
+	section, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:     subject.ID,
+		TeacherID:     teacher.ID,
+		SectionCode:   "001",
+		MaxEnrollment: 20,
+		Meetings: []schema.Meeting{
+			{ClassroomID: lectureHall.ID, StartTime: "10:00:00", DurationMinutes: 50, Days: []string{"monday", "wednesday"}},
+			{ClassroomID: lab.ID, StartTime: "14:00:00", DurationMinutes: 80, Days: []string{"friday"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create section: %v", err)
+	}
+
+	if len(section.Meetings) != 2 {
+		t.Fatalf("Expected 2 meetings, got %d", len(section.Meetings))
+	}
+
+	if section.StartTime != "" || section.ClassroomID != 0 {
+		t.Errorf("Expected no single meeting shorthand, got start %q in classroom %d", section.StartTime, section.ClassroomID)
+	}
+
+	if lab := section.Meetings[1]; lab.EndTime != "15:20:00" || len(lab.Days) != 1 || lab.Days[0] != "friday" {
+		t.Errorf("Expected the lab on friday until 15:20:00, got %v until %s", lab.Days, lab.EndTime)
+	}
+
+	// The lab room is taken on friday afternoon
+	_, err = createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       otherTeacher.ID,
+		ClassroomID:     lab.ID,
+		SectionCode:     "002",
+		StartTime:       "14:30:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"friday"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "classroom_schedule_conflict") {
+		t.Errorf("Expected a classroom_schedule_conflict for the lab room, got %v", err)
+	}
+
+	if _, err := enrollStudent(t, student.ID, section.ID); err != nil {
+		t.Fatalf("Failed to enroll student: %v", err)
+	}
+
+	if schedule := getStudentSchedule(t, student.ID); len(schedule) != 2 {
+		t.Errorf("Expected one schedule item per meeting, got %d", len(schedule))
+	}
+}
+
+func TestLinkedSections(t *testing.T) {
+	t.Log("===== TESTING LINKED SECTIONS =====")
+
+	subject := createSubject(t, "LNK101", "Linked Sections", "")
+	lecturer := createTeacher(t, "Linked", "Lecturer", "linked.lecturer@university.edu")
+	assistant := createTeacher(t, "Linked", "Assistant", "linked.assistant@university.edu")
+	hall := createClassroom(t, "Linked Hall", "1", 40)
+	lab := createClassroom(t, "Linked Hall", "L1", 20)
+	students := make([]schema.Student, 3)
+
+	for i := range students {
+		students[i] = createStudent(t, schema.CreateStudentRequest{
+			StudentID: fmt.Sprintf("linked_%03d", i+1),
+			FirstName: "Linked",
+			LastName:  fmt.Sprintf("Student%d", i+1),
+			Email:     fmt.Sprintf("linked.student%d@university.edu", i+1),
+		})
+	}
+
+	lecture, err := createSection(t, schema.CreateSectionRequest{
+		SubjectID:       subject.ID,
+		TeacherID:       lecturer.ID,
+		ClassroomID:     hall.ID,
+		SectionCode:     "001",
+		StartTime:       "09:00:00",
+		DurationMinutes: 50,
+		MaxEnrollment:   20,
+		Days:            []string{"monday", "wednesday"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create lecture: %v", err)
+	}
+
+	labs := make([]schema.Section, 2)
+
+	for i, start := range []string{"09:00:00", "11:00:00"} {
+		labs[i], err = createSection(t, schema.CreateSectionRequest{
+			SubjectID:       subject.ID,
+			TeacherID:       assistant.ID,
+			ClassroomID:     lab.ID,
+			SectionCode:     fmt.Sprintf("L%02d", i+1),
+			ComponentType:   "lab",
+			ParentSectionID: &lecture.ID,
+			StartTime:       start,
+			DurationMinutes: 80,
+			MaxEnrollment:   1,
+			Days:            []string{"friday"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create lab: %v", err)
+		}
+
+		if labs[i].ComponentType != "lab" || labs[i].ParentSectionID == nil || *labs[i].ParentSectionID != lecture.ID {
+			t.Errorf("Expected a lab of section %d, got %q of %v", lecture.ID, labs[i].ComponentType, labs[i].ParentSectionID)
+		}
+	}
+
+	enroll := func(studentID int, componentIDs ...int) (*http.Response, ErrorResponse) {
+		t.Helper()
+
+		resp := doJSON(t, http.MethodPost, apiURL+"/enrollments", handlers.EnrollmentRequest{
+			StudentID: studentID, SectionID: lecture.ID, ComponentSectionIDs: componentIDs,
+		})
+		defer resp.Body.Close()
+
+		var problem ErrorResponse
+
+		if resp.StatusCode != http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return resp, problem
+	}
+
+	// The lecture cannot be taken without a lab
+	if resp, problem := enroll(students[0].ID); resp.StatusCode != http.StatusUnprocessableEntity || problem.Code != "invalid_section_combination" {
+		t.Errorf("Expected status %d with code invalid_section_combination, got %d with %q",
+			http.StatusUnprocessableEntity, resp.StatusCode, problem.Code)
+	}
+
+	if resp, problem := enroll(students[0].ID, labs[0].ID); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the lecture and lab to be enrolled together, got %d: %s", resp.StatusCode, problem.Detail)
+	}
+
+	// The first lab is full, so the second student cannot take it; nothing is stored
+	if resp, problem := enroll(students[1].ID, labs[0].ID); resp.StatusCode != http.StatusConflict || problem.Code != "section_full" {
+		t.Errorf("Expected status %d with code section_full, got %d with %q", http.StatusConflict, resp.StatusCode, problem.Code)
+	}
+
+	if schedule := getStudentSchedule(t, students[1].ID); len(schedule) != 0 {
+		t.Errorf("Expected no enrollment of the second student, got %d schedule items", len(schedule))
+	}
+
+	// Eligibility reports what enrolling the lecture alone would leave out
+	var eligibility schema.Eligibility
+
+	getJSON(t, fmt.Sprintf("%s/students/%d/eligibility?section_id=%d", apiURL, students[1].ID, lecture.ID), &eligibility)
+
+	if eligibility.Eligible || len(eligibility.Reasons) != 1 || eligibility.Reasons[0].Code != "missing_components" ||
+		strings.Join(eligibility.MissingComponentTypes, ",") != "lab" {
+		t.Errorf("Expected the lecture to be ineligible for a missing lab, got %+v", eligibility)
+	}
+
+	// A best effort batch rolls back only the items of the student whose lab is full, the lecture item included
+	batch := handlers.BatchEnrollmentRequest{Enrollments: []handlers.EnrollmentRequest{
+		{StudentID: students[1].ID, SectionID: lecture.ID},
+		{StudentID: students[1].ID, SectionID: labs[0].ID},
+		{StudentID: students[2].ID, SectionID: lecture.ID, ComponentSectionIDs: []int{labs[1].ID}},
+	}}
+
+	batchResp, err := postJSON(t, apiURL+"/enrollments/batch?mode=best_effort", batch)
+	if err != nil {
+		t.Fatalf("Failed to enroll batch: %v", err)
+	}
+	defer batchResp.Body.Close()
+
+	var batchResult handlers.BatchEnrollmentResponse
+
+	if err := json.NewDecoder(batchResp.Body).Decode(&batchResult); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if batchResp.StatusCode != http.StatusMultiStatus || batchResult.Enrolled != 1 || len(batchResult.Results) != 3 {
+		t.Fatalf("Expected status %d with 1 of 3 items enrolled, got %d with %+v",
+			http.StatusMultiStatus, batchResp.StatusCode, batchResult)
+	}
+
+	for i, want := range []struct {
+		code   string
+		status int
+	}{
+		{"invalid_section_combination", http.StatusUnprocessableEntity},
+		{"section_full", http.StatusConflict},
+		{"", http.StatusCreated},
+	} {
+		if got := batchResult.Results[i]; got.Status != want.status || got.Code != want.code {
+			t.Errorf("Expected item %d status %d with code %q, got %d with %q", i, want.status, want.code, got.Status, got.Code)
+		}
+	}
+
+	if schedule := getStudentSchedule(t, students[1].ID); len(schedule) != 0 {
+		t.Errorf("Expected no enrollment of the second student after the batch, got %d schedule items", len(schedule))
+	}
+
+	if schedule := getStudentSchedule(t, students[2].ID); len(schedule) != 2 {
+		t.Errorf("Expected the third student in the lecture and lab after the batch, got %d schedule items", len(schedule))
+	}
+
+	// A waitlist promotion could not pick the components, so linked sections cannot be waitlisted
+	resp := doJSON(t, http.MethodPost, fmt.Sprintf("%s/sections/%d/waitlist", apiURL, labs[0].ID), handlers.WaitlistRequest{StudentID: students[1].ID})
+	defer resp.Body.Close()
+
+	var problem ErrorResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict || problem.Code != "linked_section_waitlist" {
+		t.Errorf("Expected status %d with code linked_section_waitlist, got %d with %q",
+			http.StatusConflict, resp.StatusCode, problem.Code)
+	}
+
+	// A lab cannot be dropped while keeping the lecture
+	resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, students[0].ID, labs[0].ID), nil)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d dropping the lab alone, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	// Dropping the lecture drops the lab as well
+	resp = doJSON(t, http.MethodDelete, fmt.Sprintf("%s/students/%d/sections/%d", apiURL, students[0].ID, lecture.ID), nil)
+	defer resp.Body.Close()
+
+	var dropped handlers.DropResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&dropped); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || len(dropped.DroppedComponentIDs) != 1 || dropped.DroppedComponentIDs[0] != labs[0].ID {
+		t.Errorf("Expected status %d dropping lab %d with the lecture, got %d dropping %v",
+			http.StatusOK, labs[0].ID, resp.StatusCode, dropped.DroppedComponentIDs)
+	}
+
+	if schedule := getStudentSchedule(t, students[0].ID); len(schedule) != 0 {
+		t.Errorf("Expected an empty schedule after dropping the lecture, got %d items", len(schedule))
+	}
+}
+
+func TestSchedulingPolicy(t *testing.T) {
+	t.Log("===== TESTING SCHEDULING POLICY =====")
+
+	var policy schema.SchedulingPolicy
+
+	getJSON(t, apiURL+"/policy", &policy)
+
+	if strings.Join(policy.Days, ",") != "monday,tuesday,wednesday,thursday,friday" {
+		t.Errorf("Expected Monday to Friday by default, got %v", policy.Days)
+	}
+
+	if len(policy.Durations) != 2 || policy.Durations[0] != 50 || policy.Durations[1] != 80 {
+		t.Errorf("Expected 50 and 80 minute durations by default, got %v", policy.Durations)
+	}
+
+	if policy.DayStart != "07:30:00" || policy.DayEnd != "22:00:00" {
+		t.Errorf("Expected a 07:30:00-22:00:00 window by default, got %s-%s", policy.DayStart, policy.DayEnd)
+	}
+
+	if len(policy.Patterns) != 2 || policy.Patterns[0].Code != "MWF" || policy.Patterns[1].Code != "TTh" {
+		t.Errorf("Expected the MWF and TTh patterns by default, got %+v", policy.Patterns)
+	}
+
+	teacher := createTeacher(t, "Policy", "Teacher", "policy.teacher@university.edu")
+	subject := createSubject(t, "POL101", "Scheduling Policy", "")
+	room := createClassroom(t, "Policy Hall", "1", 30)
+
+	tests := []struct {
+		name            string
+		startTime       string
+		field           string
+		days            []string
+		durationMinutes int
+	}{
+		{"EveningBlock", "18:00:00", "duration_minutes", []string{"tuesday"}, 75},
+		{"Saturday", "09:00:00", "days", []string{"saturday"}, 50},
+		{"PastDayEnd", "21:00:00", "start_time", []string{"monday"}, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doJSON(t, http.MethodPost, apiURL+"/sections", schema.CreateSectionRequest{
+				TermID:          defaultTerm(t),
+				SubjectID:       subject.ID,
+				TeacherID:       teacher.ID,
+				ClassroomID:     room.ID,
+				SectionCode:     "P-" + tt.name,
+				StartTime:       tt.startTime,
+				DurationMinutes: tt.durationMinutes,
+				MaxEnrollment:   20,
+				Days:            tt.days,
+			})
+			defer resp.Body.Close()
+
+			var problem ErrorResponse
+
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusBadRequest || problem.Code != "validation_failed" {
+				t.Fatalf("Expected status %d with code validation_failed, got %d with %q",
+					http.StatusBadRequest, resp.StatusCode, problem.Code)
+			}
+
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field {
+				t.Errorf("Expected a single %s error, got %+v", tt.field, problem.Errors)
+			}
+		})
+	}
+}
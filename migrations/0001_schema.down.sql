@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS section_days;
+DROP TABLE IF EXISTS sections;
+DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS classrooms;
+DROP TABLE IF EXISTS subjects;
+DROP TABLE IF EXISTS teachers;
+
+DROP TYPE IF EXISTS day_of_week;
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS update_updated_at_column;
+DROP FUNCTION IF EXISTS update_enrollment_count;
+DROP FUNCTION IF EXISTS prevent_enrollment_conflicts;
+DROP FUNCTION IF EXISTS check_schedule_conflict;
@@ -0,0 +1,10 @@
+-- Update timestamp triggers
+DROP TRIGGER IF EXISTS update_sections_updated_at ON sections;
+DROP TRIGGER IF EXISTS update_students_updated_at ON students;
+DROP TRIGGER IF EXISTS update_classrooms_updated_at ON classrooms;
+DROP TRIGGER IF EXISTS update_subjects_updated_at ON subjects;
+DROP TRIGGER IF EXISTS update_teachers_updated_at ON teachers;
+
+-- Enrollment triggers
+DROP TRIGGER IF EXISTS trg_update_enrollment_count ON enrollments;
+DROP TRIGGER IF EXISTS trg_prevent_enrollment_conflicts ON enrollments;
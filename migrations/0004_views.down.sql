@@ -0,0 +1 @@
+DROP VIEW IF EXISTS student_schedule_view;
@@ -0,0 +1,680 @@
+-- Scheduling rules added on top of the schema of the former sql-bootstrap init scripts (versions 1-4):
+-- academic terms with registration windows, section waitlists, subject requirements and completed courses,
+-- teacher availability, teacher and classroom double-booking checks and classroom capacity.
+
+-- Subject requirement kinds
+CREATE TYPE requirement_kind AS ENUM ('prerequisite', 'corequisite');
+
+-- Academic terms table
+CREATE TABLE terms (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL, -- e.g., "2025FA"
+    name VARCHAR(100) NOT NULL, -- e.g., "Fall 2025"
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    registration_opens_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    registration_closes_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date > start_date),
+    CHECK (registration_closes_at > registration_opens_at)
+);
+
+-- Sections are scoped to a term, so section codes only need to be unique within one
+ALTER TABLE sections
+    ADD COLUMN term_id INTEGER REFERENCES terms(id), -- NULL for sections not scoped to a term
+    ADD COLUMN max_waitlist INTEGER NOT NULL DEFAULT 0 CHECK (max_waitlist >= 0), -- 0 disables the waitlist
+    DROP CONSTRAINT sections_subject_id_section_code_key,
+    ADD UNIQUE NULLS NOT DISTINCT (term_id, subject_id, section_code);
+
+-- Section waitlists (position is derived from join order)
+CREATE TABLE waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(student_id, section_id)
+);
+
+-- Subject requirements
+-- Requirements of a subject sharing kind and group number are alternatives (OR),
+-- while distinct groups must all be satisfied (AND).
+CREATE TABLE subject_requirements (
+    id SERIAL PRIMARY KEY,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    required_subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    kind requirement_kind NOT NULL,
+    group_number INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(subject_id, kind, group_number, required_subject_id),
+    CHECK (subject_id <> required_subject_id),
+    CHECK (group_number > 0)
+);
+
+-- Courses a student has completed
+CREATE TABLE completed_courses (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    term_id INTEGER REFERENCES terms(id) ON DELETE SET NULL, -- NULL for transfer credit
+    grade VARCHAR(5), -- e.g., "A-", "P"
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(student_id, subject_id)
+);
+
+-- Teacher availability windows (teachers without windows are available at any time)
+CREATE TABLE teacher_availability (
+    id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    day day_of_week NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_time > start_time)
+);
+
+-- Indexes for performance
+CREATE INDEX idx_sections_term_id ON sections(term_id);
+CREATE INDEX idx_waitlist_entries_section_id ON waitlist_entries(section_id, created_at, id);
+CREATE INDEX idx_subject_requirements_subject_id ON subject_requirements(subject_id);
+CREATE INDEX idx_completed_courses_student_id ON completed_courses(student_id);
+CREATE INDEX idx_teacher_availability_teacher_id ON teacher_availability(teacher_id);
+
+-- Enrollment and waitlist triggers raise errors with custom SQLSTATEs of class SC,
+-- carrying a JSON object in DETAIL, so clients never depend on the wording of MESSAGE:
+--   SC001 registration_closed     {"section_id"}
+--   SC002 requirements_not_met    {"student_id", "section_id"}
+--   SC003 schedule_conflict       {"section_id", "conflicting_section_ids"}
+--   SC004 section_full            {"section_id", "max_enrollment"}
+--   SC005 already_enrolled        {"student_id", "section_id"}
+--   SC006 section_has_open_seats  {"section_id", "seats_available"}
+--   SC007 waitlist_full           {"section_id", "max_waitlist"}
+
+-- Function to list the enrolled sections of a student that clash with a section
+-- Only sections of the same term sharing a day and an overlapping time range clash;
+-- p_exclude_section_id leaves one enrolled section out, e.g. the one being swapped away.
+CREATE OR REPLACE FUNCTION find_schedule_conflicts(
+    p_student_id INTEGER,
+    p_section_id INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    WITH new_section_info AS (
+        SELECT
+            s.term_id,
+            s.start_time,
+            s.start_time + (s.duration_minutes || ' minutes')::INTERVAL as end_time,
+            array_agg(sd.day) as days
+        FROM sections s
+        JOIN section_days sd ON s.id = sd.section_id
+        WHERE s.id = p_section_id
+        GROUP BY s.id, s.term_id, s.start_time, s.duration_minutes
+    ),
+    enrolled_sections AS (
+        SELECT
+            s.id,
+            s.term_id,
+            s.start_time,
+            s.start_time + (s.duration_minutes || ' minutes')::INTERVAL as end_time,
+            array_agg(sd.day) as days
+        FROM sections s
+        JOIN section_days sd ON s.id = sd.section_id
+        JOIN enrollments e ON s.id = e.section_id
+        WHERE e.student_id = p_student_id
+          AND s.id IS DISTINCT FROM p_exclude_section_id
+        GROUP BY s.id, s.term_id, s.start_time, s.duration_minutes
+    )
+    SELECT es.id
+    FROM new_section_info nsi, enrolled_sections es
+    WHERE
+        nsi.term_id IS NOT DISTINCT FROM es.term_id  -- only sections of the same term can clash
+        AND nsi.days && es.days  -- arrays have common elements (days overlap)
+        AND (
+            (nsi.start_time >= es.start_time AND nsi.start_time < es.end_time)
+            OR (nsi.end_time > es.start_time AND nsi.end_time <= es.end_time)
+            OR (nsi.start_time <= es.start_time AND nsi.end_time >= es.end_time)
+        )
+    ORDER BY es.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to check for schedule conflicts
+CREATE OR REPLACE FUNCTION check_schedule_conflict(
+    p_student_id INTEGER,
+    p_section_id INTEGER
+) RETURNS BOOLEAN AS $$
+BEGIN
+    RETURN EXISTS (SELECT 1 FROM find_schedule_conflicts(p_student_id, p_section_id));
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to check whether the section's term is accepting registrations
+CREATE OR REPLACE FUNCTION is_registration_open(
+    p_section_id INTEGER
+) RETURNS BOOLEAN AS $$
+BEGIN
+    -- Sections without a term are always open for registration
+    RETURN NOT EXISTS (
+        SELECT 1
+        FROM sections s
+        JOIN terms t ON s.term_id = t.id
+        WHERE s.id = p_section_id
+          AND CURRENT_TIMESTAMP NOT BETWEEN t.registration_opens_at AND t.registration_closes_at
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to list the requirements of a section's subject that a student does not meet
+-- Returns every requirement row of each unsatisfied group, i.e. all alternatives still open.
+-- Prerequisites are met by a completed course; corequisites also by an enrollment in the same term.
+CREATE OR REPLACE FUNCTION find_unmet_requirements(
+    p_student_id INTEGER,
+    p_section_id INTEGER
+) RETURNS SETOF subject_requirements AS $$
+BEGIN
+    RETURN QUERY
+    WITH target AS (
+        SELECT subject_id, term_id
+        FROM sections
+        WHERE id = p_section_id
+    ),
+    satisfied AS (
+        SELECT sr.subject_id, sr.kind, sr.group_number
+        FROM subject_requirements sr
+        JOIN target t ON sr.subject_id = t.subject_id
+        WHERE EXISTS (
+            SELECT 1
+            FROM completed_courses cc
+            WHERE cc.student_id = p_student_id AND cc.subject_id = sr.required_subject_id
+        ) OR (
+            sr.kind = 'corequisite' AND EXISTS (
+                SELECT 1
+                FROM enrollments e
+                JOIN sections s ON s.id = e.section_id
+                WHERE e.student_id = p_student_id
+                    AND s.subject_id = sr.required_subject_id
+                    AND s.term_id IS NOT DISTINCT FROM t.term_id
+            )
+        )
+    )
+    SELECT sr.*
+    FROM subject_requirements sr
+    JOIN target t ON sr.subject_id = t.subject_id
+    WHERE NOT EXISTS (
+        SELECT 1
+        FROM satisfied sat
+        WHERE sat.subject_id = sr.subject_id
+            AND sat.kind = sr.kind
+            AND sat.group_number = sr.group_number
+    )
+    ORDER BY sr.kind, sr.group_number, sr.required_subject_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent enrollment conflicts (returns trigger)
+CREATE OR REPLACE FUNCTION prevent_enrollment_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_conflicts INTEGER[];
+BEGIN
+    IF NOT is_registration_open(NEW.section_id) THEN
+        RAISE EXCEPTION 'Registration is closed for this term.'
+            USING ERRCODE = 'SC001',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(NEW.student_id, NEW.section_id)) THEN
+        RAISE EXCEPTION 'Enrollment requirements are not met.'
+            USING ERRCODE = 'SC002',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    v_conflicts := ARRAY(SELECT find_schedule_conflicts(NEW.student_id, NEW.section_id));
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Schedule conflict detected. Cannot enroll in this section.'
+            USING ERRCODE = 'SC003',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to list every reason an enrollment would be rejected, without inserting it.
+-- Mirrors trg_prevent_enrollment_conflicts and trg_update_enrollment_count, but reports all failures
+-- instead of raising on the first one.
+CREATE OR REPLACE FUNCTION find_enrollment_blockers(
+    p_student_id INTEGER,
+    p_section_id INTEGER
+) RETURNS SETOF TEXT AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+BEGIN
+    SELECT * INTO v_section FROM sections WHERE id = p_section_id;
+
+    IF NOT FOUND THEN
+        RETURN NEXT 'section_not_found';
+        RETURN;
+    END IF;
+
+    IF NOT is_registration_open(p_section_id) THEN
+        RETURN NEXT 'registration_closed';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(p_student_id, p_section_id)) THEN
+        RETURN NEXT 'requirements_not_met';
+    END IF;
+
+    -- An enrolled section overlaps itself, so the conflict check only applies to other sections
+    IF EXISTS (
+        SELECT 1 FROM enrollments WHERE student_id = p_student_id AND section_id = p_section_id
+    ) THEN
+        RETURN NEXT 'already_enrolled';
+    ELSIF check_schedule_conflict(p_student_id, p_section_id) THEN
+        RETURN NEXT 'schedule_conflict';
+    END IF;
+
+    IF v_section.current_enrollment >= v_section.max_enrollment THEN
+        RETURN NEXT 'section_full';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to find a teacher's sections that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_teacher_conflicts(
+    p_teacher_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    SELECT s.id
+    FROM sections s
+    WHERE s.teacher_id = p_teacher_id
+      AND s.term_id IS NOT DISTINCT FROM p_term_id
+      AND s.id IS DISTINCT FROM p_exclude_section_id
+      AND EXISTS (
+          SELECT 1
+          FROM section_days sd
+          WHERE sd.section_id = s.id AND sd.day = ANY(p_days)
+      )
+      AND s.start_time < p_start_time + (p_duration_minutes || ' minutes')::INTERVAL
+      AND p_start_time < s.start_time + (s.duration_minutes || ' minutes')::INTERVAL
+    ORDER BY s.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent a teacher from being double-booked (returns trigger)
+-- Fires for section_days inserts and for section time or teacher changes.
+CREATE OR REPLACE FUNCTION prevent_teacher_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_days day_of_week[];
+    v_conflicts INTEGER[];
+BEGIN
+    IF TG_TABLE_NAME = 'sections' THEN
+        v_section := NEW;
+    ELSE
+        SELECT * INTO v_section FROM sections WHERE id = NEW.section_id;
+    END IF;
+
+    SELECT array_agg(day) INTO v_days FROM section_days WHERE section_id = v_section.id;
+
+    -- Sections without days do not occupy any time yet
+    IF v_days IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Serialize concurrent scheduling for the same teacher
+    PERFORM pg_advisory_xact_lock(hashtext('teacher_schedule'), v_section.teacher_id);
+
+    v_conflicts := ARRAY(
+        SELECT find_teacher_conflicts(
+            v_section.teacher_id, v_section.term_id, v_days,
+            v_section.start_time, v_section.duration_minutes, v_section.id
+        )
+    );
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Teacher schedule conflict detected.'
+            USING ERRCODE = 'exclusion_violation',
+                  CONSTRAINT = 'sections_teacher_schedule_conflict',
+                  DETAIL = json_build_object('conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to find sections held in a classroom that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_classroom_conflicts(
+    p_classroom_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    SELECT s.id
+    FROM sections s
+    WHERE s.classroom_id = p_classroom_id
+      AND s.term_id IS NOT DISTINCT FROM p_term_id
+      AND s.id IS DISTINCT FROM p_exclude_section_id
+      AND EXISTS (
+          SELECT 1
+          FROM section_days sd
+          WHERE sd.section_id = s.id AND sd.day = ANY(p_days)
+      )
+      AND s.start_time < p_start_time + (p_duration_minutes || ' minutes')::INTERVAL
+      AND p_start_time < s.start_time + (s.duration_minutes || ' minutes')::INTERVAL
+    ORDER BY s.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent a classroom from being double-booked (returns trigger)
+-- Fires for section_days inserts and for section time or classroom changes.
+CREATE OR REPLACE FUNCTION prevent_classroom_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_days day_of_week[];
+    v_conflicts INTEGER[];
+BEGIN
+    IF TG_TABLE_NAME = 'sections' THEN
+        v_section := NEW;
+    ELSE
+        SELECT * INTO v_section FROM sections WHERE id = NEW.section_id;
+    END IF;
+
+    SELECT array_agg(day) INTO v_days FROM section_days WHERE section_id = v_section.id;
+
+    -- Sections without days do not occupy any time yet
+    IF v_days IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Serialize concurrent scheduling for the same classroom
+    PERFORM pg_advisory_xact_lock(hashtext('classroom_schedule'), v_section.classroom_id);
+
+    v_conflicts := ARRAY(
+        SELECT find_classroom_conflicts(
+            v_section.classroom_id, v_section.term_id, v_days,
+            v_section.start_time, v_section.duration_minutes, v_section.id
+        )
+    );
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Classroom schedule conflict detected.'
+            USING ERRCODE = 'exclusion_violation',
+                  CONSTRAINT = 'sections_classroom_schedule_conflict',
+                  DETAIL = json_build_object('conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to ensure a section fits in its classroom (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_section_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_capacity INTEGER;
+BEGIN
+    SELECT capacity INTO v_capacity FROM classrooms WHERE id = NEW.classroom_id;
+
+    IF NEW.max_enrollment > v_capacity THEN
+        RAISE EXCEPTION 'Section max enrollment exceeds classroom capacity.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'sections_max_enrollment_capacity',
+                  DETAIL = json_build_object('max_enrollment', NEW.max_enrollment, 'capacity', v_capacity)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent shrinking a classroom below the sections it hosts (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_classroom_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_sections INTEGER[];
+BEGIN
+    v_sections := ARRAY(
+        SELECT id
+        FROM sections
+        WHERE classroom_id = NEW.id AND max_enrollment > NEW.capacity
+        ORDER BY id
+    );
+
+    IF cardinality(v_sections) > 0 THEN
+        RAISE EXCEPTION 'Classroom capacity is below the max enrollment of hosted sections.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'classrooms_capacity_hosted_sections',
+                  DETAIL = json_build_object('capacity', NEW.capacity, 'section_ids', v_sections)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to update current enrollment count (returns trigger)
+CREATE OR REPLACE FUNCTION update_enrollment_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE sections
+        SET current_enrollment = current_enrollment + 1
+        WHERE id = NEW.section_id;
+
+        -- Check if we exceed max enrollment
+        IF (SELECT current_enrollment FROM sections WHERE id = NEW.section_id) >
+           (SELECT max_enrollment FROM sections WHERE id = NEW.section_id) THEN
+            RAISE EXCEPTION 'Section is full. Cannot enroll.'
+                USING ERRCODE = 'SC004',
+                      DETAIL = json_build_object(
+                          'section_id', NEW.section_id,
+                          'max_enrollment', (SELECT max_enrollment FROM sections WHERE id = NEW.section_id)
+                      )::TEXT;
+        END IF;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE sections
+        SET current_enrollment = current_enrollment - 1
+        WHERE id = OLD.section_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to validate a new waitlist entry (returns trigger)
+CREATE OR REPLACE FUNCTION check_waitlist_entry()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_waitlisted INTEGER;
+BEGIN
+    -- Lock the section so concurrent joins see a consistent waitlist length
+    SELECT * INTO v_section FROM sections WHERE id = NEW.section_id FOR UPDATE;
+
+    IF EXISTS (
+        SELECT 1 FROM enrollments WHERE student_id = NEW.student_id AND section_id = NEW.section_id
+    ) THEN
+        RAISE EXCEPTION 'Student is already enrolled in this section.'
+            USING ERRCODE = 'SC005',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF v_section.current_enrollment < v_section.max_enrollment THEN
+        RAISE EXCEPTION 'Section has open seats. Enroll directly.'
+            USING ERRCODE = 'SC006',
+                  DETAIL = json_build_object(
+                      'section_id', NEW.section_id,
+                      'seats_available', v_section.max_enrollment - v_section.current_enrollment
+                  )::TEXT;
+    END IF;
+
+    SELECT COUNT(*) INTO v_waitlisted FROM waitlist_entries WHERE section_id = NEW.section_id;
+
+    IF v_waitlisted >= v_section.max_waitlist THEN
+        RAISE EXCEPTION 'Waitlist is full.'
+            USING ERRCODE = 'SC007',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'max_waitlist', v_section.max_waitlist)::TEXT;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to remove a student from the waitlist once enrolled (returns trigger)
+CREATE OR REPLACE FUNCTION remove_waitlist_entry()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM waitlist_entries
+    WHERE student_id = NEW.student_id AND section_id = NEW.section_id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to enroll waitlisted students into the free seats of a section
+-- Students with a schedule conflict or unmet requirements are skipped and keep their position.
+-- Returns the IDs of the promoted students in waitlist order.
+CREATE OR REPLACE FUNCTION promote_from_waitlist(
+    p_section_id INTEGER
+) RETURNS SETOF INTEGER AS $$
+DECLARE
+    v_entry RECORD;
+    v_free_seats INTEGER;
+BEGIN
+    SELECT max_enrollment - current_enrollment
+    INTO v_free_seats
+    FROM sections
+    WHERE id = p_section_id
+    FOR UPDATE;
+
+    IF v_free_seats IS NULL OR v_free_seats <= 0 OR NOT is_registration_open(p_section_id) THEN
+        RETURN;
+    END IF;
+
+    FOR v_entry IN
+        SELECT id, student_id
+        FROM waitlist_entries
+        WHERE section_id = p_section_id
+        ORDER BY created_at, id
+    LOOP
+        EXIT WHEN v_free_seats <= 0;
+
+        CONTINUE WHEN check_schedule_conflict(v_entry.student_id, p_section_id);
+
+        CONTINUE WHEN EXISTS (SELECT 1 FROM find_unmet_requirements(v_entry.student_id, p_section_id));
+
+        DELETE FROM waitlist_entries WHERE id = v_entry.id;
+
+        INSERT INTO enrollments (student_id, section_id)
+        VALUES (v_entry.student_id, p_section_id);
+
+        v_free_seats := v_free_seats - 1;
+
+        RETURN NEXT v_entry.student_id;
+    END LOOP;
+
+    RETURN;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger to validate waitlist entries
+CREATE TRIGGER trg_check_waitlist_entry
+BEFORE INSERT ON waitlist_entries
+FOR EACH ROW
+EXECUTE FUNCTION check_waitlist_entry();
+
+-- Trigger to remove enrolled students from the section waitlist
+CREATE TRIGGER trg_remove_waitlist_entry
+AFTER INSERT ON enrollments
+FOR EACH ROW
+EXECUTE FUNCTION remove_waitlist_entry();
+
+-- Triggers to prevent teacher double-booking
+CREATE TRIGGER trg_prevent_teacher_conflicts
+AFTER INSERT ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION prevent_teacher_conflicts();
+
+CREATE TRIGGER trg_prevent_teacher_conflicts
+AFTER UPDATE OF term_id, teacher_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION prevent_teacher_conflicts();
+
+-- Triggers to prevent classroom double-booking
+CREATE TRIGGER trg_prevent_classroom_conflicts
+AFTER INSERT ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION prevent_classroom_conflicts();
+
+CREATE TRIGGER trg_prevent_classroom_conflicts
+AFTER UPDATE OF term_id, classroom_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION prevent_classroom_conflicts();
+
+-- Constraint triggers to keep sections within their classroom capacity
+CREATE CONSTRAINT TRIGGER trg_enforce_section_capacity
+AFTER INSERT OR UPDATE OF classroom_id, max_enrollment ON sections
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_capacity();
+
+CREATE CONSTRAINT TRIGGER trg_enforce_classroom_capacity
+AFTER UPDATE OF capacity ON classrooms
+FOR EACH ROW
+EXECUTE FUNCTION enforce_classroom_capacity();
+
+-- Update timestamp triggers
+CREATE TRIGGER update_terms_updated_at
+BEFORE UPDATE ON terms
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Student schedules carry the term, and the section ID is the section's rather than the student's
+DROP VIEW student_schedule_view;
+
+-- View for student schedules (for PDF generation)
+CREATE VIEW student_schedule_view AS
+SELECT
+    e.student_id,
+    sec.id as section_id,
+    sec.term_id,
+    sub.code as subject_code,
+    sub.name as subject_name,
+    sec.section_code,
+    t.first_name as teacher_first_name,
+    t.last_name as teacher_last_name,
+    c.building,
+    c.room_number,
+    sec.start_time,
+    sec.start_time + (sec.duration_minutes || ' minutes')::INTERVAL as end_time,
+    sec.duration_minutes,
+    array_agg(sd.day ORDER BY sd.day) as days
+FROM enrollments e
+JOIN sections sec ON e.section_id = sec.id
+JOIN subjects sub ON sec.subject_id = sub.id
+JOIN teachers t ON sec.teacher_id = t.id
+JOIN classrooms c ON sec.classroom_id = c.id
+JOIN section_days sd ON sec.id = sd.section_id
+JOIN students s ON e.student_id = s.id
+GROUP BY
+    e.student_id, s.id, sec.id, sub.id, t.id, c.id;
+
+-- View for waitlist positions (1-based, in join order)
+CREATE VIEW waitlist_position_view AS
+SELECT
+    w.id,
+    w.student_id,
+    w.section_id,
+    w.created_at,
+    ROW_NUMBER() OVER (PARTITION BY w.section_id ORDER BY w.created_at, w.id) as position
+FROM waitlist_entries w;
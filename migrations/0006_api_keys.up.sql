@@ -0,0 +1,15 @@
+-- Principal roles
+CREATE TYPE principal_role AS ENUM ('student', 'teacher', 'registrar', 'admin');
+
+-- API keys for service integrations; only a SHA-256 hash of the secret is stored
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL, -- public part of the key used for lookup
+    key_hash CHAR(64) NOT NULL, -- hex encoded SHA-256 of the full key
+    role principal_role NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS teacher_id,
+    DROP COLUMN IF EXISTS student_id;
@@ -0,0 +1,4 @@
+-- Student and teacher API keys act on behalf of their own student or teacher record
+ALTER TABLE api_keys
+    ADD COLUMN student_id INTEGER REFERENCES students(id) ON DELETE CASCADE,
+    ADD COLUMN teacher_id INTEGER REFERENCES teachers(id) ON DELETE CASCADE;
@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS trg_audit_api_keys ON api_keys;
+DROP TRIGGER IF EXISTS trg_audit_teacher_availability ON teacher_availability;
+DROP TRIGGER IF EXISTS trg_audit_completed_courses ON completed_courses;
+DROP TRIGGER IF EXISTS trg_audit_subject_requirements ON subject_requirements;
+DROP TRIGGER IF EXISTS trg_audit_waitlist_entries ON waitlist_entries;
+DROP TRIGGER IF EXISTS trg_audit_enrollments ON enrollments;
+DROP TRIGGER IF EXISTS trg_audit_section_days ON section_days;
+DROP TRIGGER IF EXISTS trg_audit_sections ON sections;
+DROP TRIGGER IF EXISTS trg_audit_terms ON terms;
+DROP TRIGGER IF EXISTS trg_audit_students ON students;
+DROP TRIGGER IF EXISTS trg_audit_classrooms ON classrooms;
+DROP TRIGGER IF EXISTS trg_audit_subjects ON subjects;
+DROP TRIGGER IF EXISTS trg_audit_teachers ON teachers;
+
+DROP TABLE IF EXISTS audit_log;
+
+DROP FUNCTION IF EXISTS prevent_audit_log_changes();
+DROP FUNCTION IF EXISTS audit_changes();
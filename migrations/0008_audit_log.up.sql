@@ -0,0 +1,142 @@
+-- Append-only audit trail of every row created, updated or deleted.
+-- The API sets app.actor, app.actor_role and app.request_id on its connections;
+-- changes made directly in SQL are attributed to the database user.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor TEXT NOT NULL,
+    actor_role TEXT,
+    request_id TEXT,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity TEXT NOT NULL, -- table name
+    entity_id INTEGER,
+    before JSONB, -- NULL for creates
+    after JSONB -- NULL for deletes
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at);
+
+-- Function to record a row change in the audit log.
+-- The optional trigger argument names the column identifying the entity (default id).
+-- Updates that only touch bookkeeping columns are not recorded, and secrets are never copied.
+CREATE OR REPLACE FUNCTION audit_changes()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_id_column TEXT := COALESCE(TG_ARGV[0], 'id');
+    v_ignored TEXT[] := ARRAY['updated_at', 'last_used_at', 'current_enrollment'];
+    v_before JSONB;
+    v_after JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        v_before := to_jsonb(OLD) - 'key_hash';
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        v_after := to_jsonb(NEW) - 'key_hash';
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND v_before - v_ignored = v_after - v_ignored THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log (actor, actor_role, request_id, action, entity, entity_id, before, after)
+    VALUES (
+        COALESCE(NULLIF(current_setting('app.actor', TRUE), ''), 'db:' || session_user),
+        NULLIF(current_setting('app.actor_role', TRUE), ''),
+        NULLIF(current_setting('app.request_id', TRUE), ''),
+        CASE TG_OP WHEN 'INSERT' THEN 'create' ELSE lower(TG_OP) END,
+        TG_TABLE_NAME,
+        (COALESCE(v_after, v_before) ->> v_id_column)::INTEGER,
+        v_before,
+        v_after
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to keep the audit log append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'The audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_prevent_audit_log_changes
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION prevent_audit_log_changes();
+
+CREATE TRIGGER trg_prevent_audit_log_truncate
+BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT
+EXECUTE FUNCTION prevent_audit_log_changes();
+
+-- Audit triggers
+CREATE TRIGGER trg_audit_teachers
+AFTER INSERT OR UPDATE OR DELETE ON teachers
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_subjects
+AFTER INSERT OR UPDATE OR DELETE ON subjects
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_classrooms
+AFTER INSERT OR UPDATE OR DELETE ON classrooms
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_students
+AFTER INSERT OR UPDATE OR DELETE ON students
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_terms
+AFTER INSERT OR UPDATE OR DELETE ON terms
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_sections
+AFTER INSERT OR UPDATE OR DELETE ON sections
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_section_days
+AFTER INSERT OR UPDATE OR DELETE ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes('section_id');
+
+CREATE TRIGGER trg_audit_enrollments
+AFTER INSERT OR UPDATE OR DELETE ON enrollments
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_waitlist_entries
+AFTER INSERT OR UPDATE OR DELETE ON waitlist_entries
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_subject_requirements
+AFTER INSERT OR UPDATE OR DELETE ON subject_requirements
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_completed_courses
+AFTER INSERT OR UPDATE OR DELETE ON completed_courses
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_teacher_availability
+AFTER INSERT OR UPDATE OR DELETE ON teacher_availability
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_api_keys
+AFTER INSERT OR UPDATE OR DELETE ON api_keys
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
@@ -0,0 +1,30 @@
+-- Restore the enrollment check without the student schedule lock
+CREATE OR REPLACE FUNCTION prevent_enrollment_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_conflicts INTEGER[];
+BEGIN
+    IF NOT is_registration_open(NEW.section_id) THEN
+        RAISE EXCEPTION 'Registration is closed for this term.'
+            USING ERRCODE = 'SC001',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(NEW.student_id, NEW.section_id)) THEN
+        RAISE EXCEPTION 'Enrollment requirements are not met.'
+            USING ERRCODE = 'SC002',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    v_conflicts := ARRAY(SELECT find_schedule_conflicts(NEW.student_id, NEW.section_id));
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Schedule conflict detected. Cannot enroll in this section.'
+            USING ERRCODE = 'SC003',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS lock_student_schedule(INTEGER);
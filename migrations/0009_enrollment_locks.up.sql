@@ -0,0 +1,41 @@
+-- Function to serialize changes to a student's schedule until the end of the transaction.
+-- Enrollment conflict checks run under READ COMMITTED, so without it two concurrent
+-- enrollments of one student into overlapping sections could both pass.
+-- The lock namespace 0x73636864 ('schd') keeps the keys apart from other advisory locks.
+CREATE OR REPLACE FUNCTION lock_student_schedule(p_student_id INTEGER)
+RETURNS VOID AS $$
+    SELECT pg_advisory_xact_lock(x'73636864'::INTEGER, p_student_id);
+$$ LANGUAGE sql;
+
+-- Function to check for registration, requirement and schedule conflicts before enrollment,
+-- holding the student's schedule lock
+CREATE OR REPLACE FUNCTION prevent_enrollment_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_conflicts INTEGER[];
+BEGIN
+    -- Concurrent enrollments of the same student wait here, so each sees the others' committed rows
+    PERFORM lock_student_schedule(NEW.student_id);
+
+    IF NOT is_registration_open(NEW.section_id) THEN
+        RAISE EXCEPTION 'Registration is closed for this term.'
+            USING ERRCODE = 'SC001',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(NEW.student_id, NEW.section_id)) THEN
+        RAISE EXCEPTION 'Enrollment requirements are not met.'
+            USING ERRCODE = 'SC002',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    v_conflicts := ARRAY(SELECT find_schedule_conflicts(NEW.student_id, NEW.section_id));
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Schedule conflict detected. Cannot enroll in this section.'
+            USING ERRCODE = 'SC003',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
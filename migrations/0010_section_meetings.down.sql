@@ -0,0 +1,265 @@
+DROP TRIGGER IF EXISTS trg_occupy_student_meetings ON enrollments;
+DROP TRIGGER IF EXISTS trg_sync_student_meetings ON section_meetings;
+DROP TRIGGER IF EXISTS trg_sync_section_meetings ON sections;
+DROP TRIGGER IF EXISTS trg_sync_section_day_meetings ON section_days;
+
+DROP TABLE IF EXISTS student_meetings;
+DROP TABLE IF EXISTS section_meetings;
+
+DROP FUNCTION IF EXISTS sync_enrollment_meetings();
+DROP FUNCTION IF EXISTS sync_student_meetings();
+DROP FUNCTION IF EXISTS sync_section_meetings();
+DROP FUNCTION IF EXISTS sync_section_day_meetings();
+
+-- Restore the hand-written overlap checks and the double-booking triggers
+DROP FUNCTION IF EXISTS find_schedule_conflicts(INTEGER, INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS find_teacher_conflicts(INTEGER, INTEGER, day_of_week[], TIME, INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS find_classroom_conflicts(INTEGER, INTEGER, day_of_week[], TIME, INTEGER, INTEGER);
+
+-- Function to list the enrolled sections of a student that clash with a section
+-- Only sections of the same term sharing a day and an overlapping time range clash;
+-- p_exclude_section_id leaves one enrolled section out, e.g. the one being swapped away.
+CREATE OR REPLACE FUNCTION find_schedule_conflicts(
+    p_student_id INTEGER,
+    p_section_id INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    WITH new_section_info AS (
+        SELECT
+            s.term_id,
+            s.start_time,
+            s.start_time + (s.duration_minutes || ' minutes')::INTERVAL as end_time,
+            array_agg(sd.day) as days
+        FROM sections s
+        JOIN section_days sd ON s.id = sd.section_id
+        WHERE s.id = p_section_id
+        GROUP BY s.id, s.term_id, s.start_time, s.duration_minutes
+    ),
+    enrolled_sections AS (
+        SELECT
+            s.id,
+            s.term_id,
+            s.start_time,
+            s.start_time + (s.duration_minutes || ' minutes')::INTERVAL as end_time,
+            array_agg(sd.day) as days
+        FROM sections s
+        JOIN section_days sd ON s.id = sd.section_id
+        JOIN enrollments e ON s.id = e.section_id
+        WHERE e.student_id = p_student_id
+          AND s.id IS DISTINCT FROM p_exclude_section_id
+        GROUP BY s.id, s.term_id, s.start_time, s.duration_minutes
+    )
+    SELECT es.id
+    FROM new_section_info nsi, enrolled_sections es
+    WHERE
+        nsi.term_id IS NOT DISTINCT FROM es.term_id  -- only sections of the same term can clash
+        AND nsi.days && es.days  -- arrays have common elements (days overlap)
+        AND (
+            (nsi.start_time >= es.start_time AND nsi.start_time < es.end_time)
+            OR (nsi.end_time > es.start_time AND nsi.end_time <= es.end_time)
+            OR (nsi.start_time <= es.start_time AND nsi.end_time >= es.end_time)
+        )
+    ORDER BY es.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to find a teacher's sections that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_teacher_conflicts(
+    p_teacher_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    SELECT s.id
+    FROM sections s
+    WHERE s.teacher_id = p_teacher_id
+      AND s.term_id IS NOT DISTINCT FROM p_term_id
+      AND s.id IS DISTINCT FROM p_exclude_section_id
+      AND EXISTS (
+          SELECT 1
+          FROM section_days sd
+          WHERE sd.section_id = s.id AND sd.day = ANY(p_days)
+      )
+      AND s.start_time < p_start_time + (p_duration_minutes || ' minutes')::INTERVAL
+      AND p_start_time < s.start_time + (s.duration_minutes || ' minutes')::INTERVAL
+    ORDER BY s.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent a teacher from being double-booked (returns trigger)
+-- Fires for section_days inserts and for section time or teacher changes.
+CREATE OR REPLACE FUNCTION prevent_teacher_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_days day_of_week[];
+    v_conflicts INTEGER[];
+BEGIN
+    IF TG_TABLE_NAME = 'sections' THEN
+        v_section := NEW;
+    ELSE
+        SELECT * INTO v_section FROM sections WHERE id = NEW.section_id;
+    END IF;
+
+    SELECT array_agg(day) INTO v_days FROM section_days WHERE section_id = v_section.id;
+
+    -- Sections without days do not occupy any time yet
+    IF v_days IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Serialize concurrent scheduling for the same teacher
+    PERFORM pg_advisory_xact_lock(hashtext('teacher_schedule'), v_section.teacher_id);
+
+    v_conflicts := ARRAY(
+        SELECT find_teacher_conflicts(
+            v_section.teacher_id, v_section.term_id, v_days,
+            v_section.start_time, v_section.duration_minutes, v_section.id
+        )
+    );
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Teacher schedule conflict detected.'
+            USING ERRCODE = 'exclusion_violation',
+                  CONSTRAINT = 'sections_teacher_schedule_conflict',
+                  DETAIL = json_build_object('conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to find sections held in a classroom that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_classroom_conflicts(
+    p_classroom_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+BEGIN
+    RETURN QUERY
+    SELECT s.id
+    FROM sections s
+    WHERE s.classroom_id = p_classroom_id
+      AND s.term_id IS NOT DISTINCT FROM p_term_id
+      AND s.id IS DISTINCT FROM p_exclude_section_id
+      AND EXISTS (
+          SELECT 1
+          FROM section_days sd
+          WHERE sd.section_id = s.id AND sd.day = ANY(p_days)
+      )
+      AND s.start_time < p_start_time + (p_duration_minutes || ' minutes')::INTERVAL
+      AND p_start_time < s.start_time + (s.duration_minutes || ' minutes')::INTERVAL
+    ORDER BY s.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent a classroom from being double-booked (returns trigger)
+-- Fires for section_days inserts and for section time or classroom changes.
+CREATE OR REPLACE FUNCTION prevent_classroom_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_days day_of_week[];
+    v_conflicts INTEGER[];
+BEGIN
+    IF TG_TABLE_NAME = 'sections' THEN
+        v_section := NEW;
+    ELSE
+        SELECT * INTO v_section FROM sections WHERE id = NEW.section_id;
+    END IF;
+
+    SELECT array_agg(day) INTO v_days FROM section_days WHERE section_id = v_section.id;
+
+    -- Sections without days do not occupy any time yet
+    IF v_days IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Serialize concurrent scheduling for the same classroom
+    PERFORM pg_advisory_xact_lock(hashtext('classroom_schedule'), v_section.classroom_id);
+
+    v_conflicts := ARRAY(
+        SELECT find_classroom_conflicts(
+            v_section.classroom_id, v_section.term_id, v_days,
+            v_section.start_time, v_section.duration_minutes, v_section.id
+        )
+    );
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Classroom schedule conflict detected.'
+            USING ERRCODE = 'exclusion_violation',
+                  CONSTRAINT = 'sections_classroom_schedule_conflict',
+                  DETAIL = json_build_object('conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to check for registration, requirement and schedule conflicts before enrollment,
+-- holding the student's schedule lock
+CREATE OR REPLACE FUNCTION prevent_enrollment_conflicts()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_conflicts INTEGER[];
+BEGIN
+    -- Concurrent enrollments of the same student wait here, so each sees the others' committed rows
+    PERFORM lock_student_schedule(NEW.student_id);
+
+    IF NOT is_registration_open(NEW.section_id) THEN
+        RAISE EXCEPTION 'Registration is closed for this term.'
+            USING ERRCODE = 'SC001',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(NEW.student_id, NEW.section_id)) THEN
+        RAISE EXCEPTION 'Enrollment requirements are not met.'
+            USING ERRCODE = 'SC002',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    v_conflicts := ARRAY(SELECT find_schedule_conflicts(NEW.student_id, NEW.section_id));
+
+    IF cardinality(v_conflicts) > 0 THEN
+        RAISE EXCEPTION 'Schedule conflict detected. Cannot enroll in this section.'
+            USING ERRCODE = 'SC003',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'conflicting_section_ids', v_conflicts)::TEXT;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Triggers to prevent teacher double-booking
+CREATE TRIGGER trg_prevent_teacher_conflicts
+AFTER INSERT ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION prevent_teacher_conflicts();
+
+CREATE TRIGGER trg_prevent_teacher_conflicts
+AFTER UPDATE OF term_id, teacher_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION prevent_teacher_conflicts();
+
+-- Triggers to prevent classroom double-booking
+CREATE TRIGGER trg_prevent_classroom_conflicts
+AFTER INSERT ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION prevent_classroom_conflicts();
+
+CREATE TRIGGER trg_prevent_classroom_conflicts
+AFTER UPDATE OF term_id, classroom_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION prevent_classroom_conflicts();
+
+DROP FUNCTION IF EXISTS meeting_minutes(TIME, INTEGER);
+DROP FUNCTION IF EXISTS day_number(day_of_week);
+-- btree_gist is left installed
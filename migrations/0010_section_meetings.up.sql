@@ -0,0 +1,280 @@
+-- Normalized meeting times with exclusion constraints.
+-- Every section day becomes a section_meetings row holding its time as a range of minutes since midnight,
+-- and every enrolled meeting a student_meetings row. GiST exclusion constraints on these tables replace
+-- the hand-written overlap checks for teachers, classrooms and students.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Function to number the days of the week (Monday = 1), so days can take part in GiST exclusion constraints
+CREATE OR REPLACE FUNCTION day_number(p_day day_of_week)
+RETURNS SMALLINT AS $$
+    SELECT CASE p_day
+        WHEN 'monday' THEN 1
+        WHEN 'tuesday' THEN 2
+        WHEN 'wednesday' THEN 3
+        WHEN 'thursday' THEN 4
+        WHEN 'friday' THEN 5
+    END::SMALLINT;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Function to convert a start time and duration into a half-open range of minutes since midnight,
+-- so back-to-back meetings do not overlap
+CREATE OR REPLACE FUNCTION meeting_minutes(p_start_time TIME, p_duration_minutes INTEGER)
+RETURNS int4range AS $$
+    SELECT int4range(
+        (EXTRACT(EPOCH FROM p_start_time) / 60)::INTEGER,
+        (EXTRACT(EPOCH FROM p_start_time) / 60)::INTEGER + p_duration_minutes
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Section meeting times; term, teacher and classroom are copied from the section for the exclusion constraints.
+-- Sections without a term share the term key 0, so they clash with each other as before.
+CREATE TABLE section_meetings (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    term_id INTEGER REFERENCES terms(id),
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id),
+    classroom_id INTEGER NOT NULL REFERENCES classrooms(id),
+    day day_of_week NOT NULL,
+    minutes int4range NOT NULL,
+    UNIQUE (section_id, day),
+    CONSTRAINT sections_teacher_schedule_conflict EXCLUDE USING gist (
+        teacher_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    ),
+    CONSTRAINT sections_classroom_schedule_conflict EXCLUDE USING gist (
+        classroom_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    )
+);
+
+-- Meeting times occupied by enrolled students
+CREATE TABLE student_meetings (
+    student_id INTEGER NOT NULL REFERENCES students(id),
+    meeting_id INTEGER NOT NULL REFERENCES section_meetings(id) ON DELETE CASCADE,
+    section_id INTEGER NOT NULL REFERENCES sections(id),
+    term_id INTEGER REFERENCES terms(id),
+    day day_of_week NOT NULL,
+    minutes int4range NOT NULL,
+    PRIMARY KEY (student_id, meeting_id),
+    CONSTRAINT student_meetings_schedule_conflict EXCLUDE USING gist (
+        student_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    )
+);
+
+CREATE INDEX idx_student_meetings_section ON student_meetings(section_id);
+
+INSERT INTO section_meetings (section_id, term_id, teacher_id, classroom_id, day, minutes)
+SELECT s.id, s.term_id, s.teacher_id, s.classroom_id, sd.day, meeting_minutes(s.start_time, s.duration_minutes)
+FROM sections s
+JOIN section_days sd ON s.id = sd.section_id;
+
+INSERT INTO student_meetings (student_id, meeting_id, section_id, term_id, day, minutes)
+SELECT e.student_id, m.id, m.section_id, m.term_id, m.day, m.minutes
+FROM enrollments e
+JOIN section_meetings m ON e.section_id = m.section_id;
+
+-- Function to keep section_meetings in step with section_days (returns trigger)
+CREATE OR REPLACE FUNCTION sync_section_day_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM section_meetings WHERE section_id = OLD.section_id AND day = OLD.day;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO section_meetings (section_id, term_id, teacher_id, classroom_id, day, minutes)
+        SELECT s.id, s.term_id, s.teacher_id, s.classroom_id, NEW.day, meeting_minutes(s.start_time, s.duration_minutes)
+        FROM sections s
+        WHERE s.id = NEW.section_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to copy section time, term, teacher and classroom changes to its meetings (returns trigger)
+CREATE OR REPLACE FUNCTION sync_section_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE section_meetings
+    SET term_id = NEW.term_id,
+        teacher_id = NEW.teacher_id,
+        classroom_id = NEW.classroom_id,
+        minutes = meeting_minutes(NEW.start_time, NEW.duration_minutes)
+    WHERE section_id = NEW.id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to copy meeting changes to the students enrolled in the section (returns trigger)
+-- Rescheduling a section into a clash with another section of an enrolled student violates
+-- student_meetings_schedule_conflict.
+CREATE OR REPLACE FUNCTION sync_student_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO student_meetings (student_id, meeting_id, section_id, term_id, day, minutes)
+        SELECT e.student_id, NEW.id, NEW.section_id, NEW.term_id, NEW.day, NEW.minutes
+        FROM enrollments e
+        WHERE e.section_id = NEW.section_id;
+    ELSE
+        UPDATE student_meetings
+        SET term_id = NEW.term_id,
+            day = NEW.day,
+            minutes = NEW.minutes
+        WHERE meeting_id = NEW.id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to occupy or free the meeting times of a student's enrolled section (returns trigger)
+-- A clash with the student's other sections is raised as SC003 schedule_conflict.
+CREATE OR REPLACE FUNCTION sync_enrollment_meetings()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_conflicts INTEGER[];
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM student_meetings WHERE student_id = OLD.student_id AND section_id = OLD.section_id;
+
+        RETURN NULL;
+    END IF;
+
+    BEGIN
+        INSERT INTO student_meetings (student_id, meeting_id, section_id, term_id, day, minutes)
+        SELECT NEW.student_id, m.id, m.section_id, m.term_id, m.day, m.minutes
+        FROM section_meetings m
+        WHERE m.section_id = NEW.section_id;
+    EXCEPTION WHEN exclusion_violation THEN
+        v_conflicts := ARRAY(SELECT find_schedule_conflicts(NEW.student_id, NEW.section_id));
+
+        RAISE EXCEPTION 'Schedule conflict detected. Cannot enroll in this section.'
+            USING ERRCODE = 'SC003',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'conflicting_section_ids', v_conflicts)::TEXT;
+    END;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to list the enrolled sections of a student that clash with a section
+-- Only meetings of the same term on the same day with overlapping minutes clash;
+-- p_exclude_section_id leaves one enrolled section out, e.g. the one being swapped away.
+CREATE OR REPLACE FUNCTION find_schedule_conflicts(
+    p_student_id INTEGER,
+    p_section_id INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+    SELECT DISTINCT sm.section_id
+    FROM section_meetings m
+    JOIN student_meetings sm
+      ON sm.student_id = p_student_id
+     AND COALESCE(sm.term_id, 0) = COALESCE(m.term_id, 0)
+     AND sm.day = m.day
+     AND sm.minutes && m.minutes
+    WHERE m.section_id = p_section_id
+      AND sm.section_id IS DISTINCT FROM p_exclude_section_id
+    ORDER BY sm.section_id;
+$$ LANGUAGE sql STABLE;
+
+-- Function to find a teacher's sections that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_teacher_conflicts(
+    p_teacher_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+    SELECT DISTINCT m.section_id
+    FROM section_meetings m
+    WHERE m.teacher_id = p_teacher_id
+      AND COALESCE(m.term_id, 0) = COALESCE(p_term_id, 0)
+      AND m.section_id IS DISTINCT FROM p_exclude_section_id
+      AND m.day = ANY(p_days)
+      AND m.minutes && meeting_minutes(p_start_time, p_duration_minutes)
+    ORDER BY m.section_id;
+$$ LANGUAGE sql STABLE;
+
+-- Function to find sections held in a classroom that overlap the given meeting time within the same term
+CREATE OR REPLACE FUNCTION find_classroom_conflicts(
+    p_classroom_id INTEGER,
+    p_term_id INTEGER,
+    p_days day_of_week[],
+    p_start_time TIME,
+    p_duration_minutes INTEGER,
+    p_exclude_section_id INTEGER DEFAULT NULL
+) RETURNS SETOF INTEGER AS $$
+    SELECT DISTINCT m.section_id
+    FROM section_meetings m
+    WHERE m.classroom_id = p_classroom_id
+      AND COALESCE(m.term_id, 0) = COALESCE(p_term_id, 0)
+      AND m.section_id IS DISTINCT FROM p_exclude_section_id
+      AND m.day = ANY(p_days)
+      AND m.minutes && meeting_minutes(p_start_time, p_duration_minutes)
+    ORDER BY m.section_id;
+$$ LANGUAGE sql STABLE;
+
+-- Function to check for registration and requirement conflicts before enrollment,
+-- holding the student's schedule lock; schedule conflicts are caught by student_meetings
+CREATE OR REPLACE FUNCTION prevent_enrollment_conflicts()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Concurrent enrollments of the same student wait here, so each sees the others' committed rows
+    PERFORM lock_student_schedule(NEW.student_id);
+
+    IF NOT is_registration_open(NEW.section_id) THEN
+        RAISE EXCEPTION 'Registration is closed for this term.'
+            USING ERRCODE = 'SC001',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(NEW.student_id, NEW.section_id)) THEN
+        RAISE EXCEPTION 'Enrollment requirements are not met.'
+            USING ERRCODE = 'SC002',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The exclusion constraints replace the teacher and classroom double-booking triggers
+DROP TRIGGER IF EXISTS trg_prevent_teacher_conflicts ON section_days;
+DROP TRIGGER IF EXISTS trg_prevent_teacher_conflicts ON sections;
+DROP TRIGGER IF EXISTS trg_prevent_classroom_conflicts ON section_days;
+DROP TRIGGER IF EXISTS trg_prevent_classroom_conflicts ON sections;
+DROP FUNCTION IF EXISTS prevent_teacher_conflicts();
+DROP FUNCTION IF EXISTS prevent_classroom_conflicts();
+
+-- Meeting synchronization triggers
+CREATE TRIGGER trg_sync_section_day_meetings
+AFTER INSERT OR UPDATE OR DELETE ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION sync_section_day_meetings();
+
+CREATE TRIGGER trg_sync_section_meetings
+AFTER UPDATE OF term_id, teacher_id, classroom_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION sync_section_meetings();
+
+CREATE TRIGGER trg_sync_student_meetings
+AFTER INSERT OR UPDATE ON section_meetings
+FOR EACH ROW
+EXECUTE FUNCTION sync_student_meetings();
+
+-- Named to fire before trg_update_enrollment_count, so clashes are reported before a full section
+CREATE TRIGGER trg_occupy_student_meetings
+AFTER INSERT OR DELETE ON enrollments
+FOR EACH ROW
+EXECUTE FUNCTION sync_enrollment_meetings();
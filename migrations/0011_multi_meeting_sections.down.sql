@@ -0,0 +1,226 @@
+-- Sections go back to a single meeting time, classroom and set of days.
+-- Sections without a meeting or with several meeting patterns, e.g. a lecture and a lab, cannot be reverted
+-- without losing data, so the operator has to fix or delete them first.
+DO $$
+DECLARE
+    v_section_ids INTEGER[];
+BEGIN
+    v_section_ids := ARRAY(
+        SELECT s.id
+        FROM sections s
+        LEFT JOIN section_meeting_view m ON m.section_id = s.id
+        GROUP BY s.id
+        HAVING COUNT(m.section_id) <> 1
+        ORDER BY s.id
+    );
+
+    IF cardinality(v_section_ids) > 0 THEN
+        RAISE EXCEPTION 'Sections without exactly one meeting pattern cannot be reverted to a single meeting time.'
+            USING DETAIL = json_build_object('section_ids', v_section_ids)::TEXT,
+                  HINT = 'Give each of these sections one meeting pattern, or delete them, before reverting.';
+    END IF;
+END;
+$$;
+
+DROP VIEW IF EXISTS student_schedule_view;
+
+DROP TRIGGER IF EXISTS trg_audit_section_meetings ON section_meetings;
+DROP TRIGGER IF EXISTS trg_enforce_meeting_capacity ON section_meetings;
+DROP TRIGGER IF EXISTS trg_enforce_section_capacity ON sections;
+DROP TRIGGER IF EXISTS trg_sync_section_meetings ON sections;
+
+ALTER TABLE sections
+    ADD COLUMN classroom_id INTEGER REFERENCES classrooms(id),
+    ADD COLUMN start_time TIME,
+    ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 50;
+
+UPDATE sections s
+SET classroom_id = p.classroom_id,
+    start_time = p.start_time,
+    duration_minutes = p.duration_minutes
+FROM section_meeting_view p
+WHERE s.id = p.section_id;
+
+DROP VIEW IF EXISTS section_meeting_view;
+
+ALTER TABLE sections
+    ALTER COLUMN classroom_id SET NOT NULL,
+    ALTER COLUMN start_time SET NOT NULL,
+    ADD CHECK (start_time >= '07:30:00'),
+    ADD CHECK (start_time + (duration_minutes || ' minutes')::INTERVAL <= '22:00:00'),
+    ADD CHECK (duration_minutes IN (50, 80));
+
+CREATE INDEX idx_sections_classroom_id ON sections(classroom_id);
+
+-- Section days (many-to-many relationship for days)
+CREATE TABLE section_days (
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    day day_of_week NOT NULL,
+    PRIMARY KEY (section_id, day)
+);
+
+CREATE INDEX idx_section_days_section_id ON section_days(section_id);
+
+INSERT INTO section_days (section_id, day)
+SELECT section_id, day
+FROM section_meetings;
+
+DROP INDEX IF EXISTS idx_section_meetings_section_id;
+DROP INDEX IF EXISTS idx_section_meetings_classroom_id;
+
+ALTER TABLE section_meetings
+    DROP CONSTRAINT IF EXISTS sections_teacher_schedule_conflict,
+    DROP CONSTRAINT IF EXISTS sections_classroom_schedule_conflict,
+    DROP CONSTRAINT IF EXISTS section_meetings_start_time_check,
+    DROP CONSTRAINT IF EXISTS section_meetings_end_time_check,
+    DROP CONSTRAINT IF EXISTS section_meetings_duration_minutes_check,
+    ADD COLUMN stored_minutes int4range;
+
+UPDATE section_meetings SET stored_minutes = minutes;
+
+ALTER TABLE section_meetings
+    DROP COLUMN minutes,
+    DROP COLUMN start_time,
+    DROP COLUMN duration_minutes;
+
+ALTER TABLE section_meetings RENAME COLUMN stored_minutes TO minutes;
+
+ALTER TABLE section_meetings
+    ALTER COLUMN minutes SET NOT NULL,
+    ADD UNIQUE (section_id, day),
+    ADD CONSTRAINT sections_teacher_schedule_conflict EXCLUDE USING gist (
+        teacher_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    ),
+    ADD CONSTRAINT sections_classroom_schedule_conflict EXCLUDE USING gist (
+        classroom_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
+
+-- Function to keep section_meetings in step with section_days (returns trigger)
+CREATE OR REPLACE FUNCTION sync_section_day_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM section_meetings WHERE section_id = OLD.section_id AND day = OLD.day;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO section_meetings (section_id, term_id, teacher_id, classroom_id, day, minutes)
+        SELECT s.id, s.term_id, s.teacher_id, s.classroom_id, NEW.day, meeting_minutes(s.start_time, s.duration_minutes)
+        FROM sections s
+        WHERE s.id = NEW.section_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to copy section time, term, teacher and classroom changes to its meetings (returns trigger)
+CREATE OR REPLACE FUNCTION sync_section_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE section_meetings
+    SET term_id = NEW.term_id,
+        teacher_id = NEW.teacher_id,
+        classroom_id = NEW.classroom_id,
+        minutes = meeting_minutes(NEW.start_time, NEW.duration_minutes)
+    WHERE section_id = NEW.id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to ensure a section fits in its classroom (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_section_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_capacity INTEGER;
+BEGIN
+    SELECT capacity INTO v_capacity FROM classrooms WHERE id = NEW.classroom_id;
+
+    IF NEW.max_enrollment > v_capacity THEN
+        RAISE EXCEPTION 'Section max enrollment exceeds classroom capacity.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'sections_max_enrollment_capacity',
+                  DETAIL = json_build_object('max_enrollment', NEW.max_enrollment, 'capacity', v_capacity)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent shrinking a classroom below the sections it hosts (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_classroom_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_sections INTEGER[];
+BEGIN
+    v_sections := ARRAY(
+        SELECT id
+        FROM sections
+        WHERE classroom_id = NEW.id AND max_enrollment > NEW.capacity
+        ORDER BY id
+    );
+
+    IF cardinality(v_sections) > 0 THEN
+        RAISE EXCEPTION 'Classroom capacity is below the max enrollment of hosted sections.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'classrooms_capacity_hosted_sections',
+                  DETAIL = json_build_object('capacity', NEW.capacity, 'section_ids', v_sections)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sync_section_day_meetings
+AFTER INSERT OR UPDATE OR DELETE ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION sync_section_day_meetings();
+
+CREATE TRIGGER trg_sync_section_meetings
+AFTER UPDATE OF term_id, teacher_id, classroom_id, start_time, duration_minutes ON sections
+FOR EACH ROW
+EXECUTE FUNCTION sync_section_meetings();
+
+CREATE CONSTRAINT TRIGGER trg_enforce_section_capacity
+AFTER INSERT OR UPDATE OF classroom_id, max_enrollment ON sections
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_capacity();
+
+CREATE TRIGGER trg_audit_section_days
+AFTER INSERT OR UPDATE OR DELETE ON section_days
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes('section_id');
+
+-- View for student schedules (for PDF generation)
+CREATE VIEW student_schedule_view AS
+SELECT
+    e.student_id,
+    sec.id as section_id,
+    sec.term_id,
+    sub.code as subject_code,
+    sub.name as subject_name,
+    sec.section_code,
+    t.first_name as teacher_first_name,
+    t.last_name as teacher_last_name,
+    c.building,
+    c.room_number,
+    sec.start_time,
+    sec.start_time + (sec.duration_minutes || ' minutes')::INTERVAL as end_time,
+    sec.duration_minutes,
+    array_agg(sd.day ORDER BY sd.day) as days
+FROM enrollments e
+JOIN sections sec ON e.section_id = sec.id
+JOIN subjects sub ON sec.subject_id = sub.id
+JOIN teachers t ON sec.teacher_id = t.id
+JOIN classrooms c ON sec.classroom_id = c.id
+JOIN section_days sd ON sec.id = sd.section_id
+JOIN students s ON e.student_id = s.id
+GROUP BY
+    e.student_id, s.id, sec.id, sub.id, t.id, c.id;
@@ -0,0 +1,182 @@
+-- Multi-meeting sections.
+-- A section owns its meetings, e.g. a lecture on Monday and Wednesday and a lab on Friday in another room,
+-- each with its own start time, duration and classroom. section_meetings becomes the source of truth,
+-- replacing the single start time, duration and classroom of the section and the section_days table.
+
+-- The section_days and section time triggers go away with the columns they watch
+DROP TRIGGER IF EXISTS trg_sync_section_meetings ON sections;
+DROP TRIGGER IF EXISTS trg_enforce_section_capacity ON sections;
+DROP TABLE IF EXISTS section_days;
+DROP FUNCTION IF EXISTS sync_section_day_meetings();
+DROP VIEW IF EXISTS student_schedule_view;
+
+ALTER TABLE section_meetings
+    ADD COLUMN start_time TIME,
+    ADD COLUMN duration_minutes INTEGER;
+
+UPDATE section_meetings m
+SET start_time = s.start_time,
+    duration_minutes = s.duration_minutes
+FROM sections s
+WHERE s.id = m.section_id;
+
+-- A section may meet more than once a day; its teacher cannot be in two of its meetings at once
+ALTER TABLE section_meetings
+    ALTER COLUMN start_time SET NOT NULL,
+    ALTER COLUMN duration_minutes SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS section_meetings_section_id_day_key,
+    DROP COLUMN minutes;
+
+ALTER TABLE section_meetings
+    ADD COLUMN minutes int4range GENERATED ALWAYS AS (meeting_minutes(start_time, duration_minutes)) STORED NOT NULL,
+    ADD CONSTRAINT section_meetings_start_time_check CHECK (start_time >= '07:30:00'),
+    ADD CONSTRAINT section_meetings_end_time_check CHECK (start_time + (duration_minutes || ' minutes')::INTERVAL <= '22:00:00'),
+    ADD CONSTRAINT section_meetings_duration_minutes_check CHECK (duration_minutes IN (50, 80)),
+    ADD CONSTRAINT sections_teacher_schedule_conflict EXCLUDE USING gist (
+        teacher_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    ),
+    ADD CONSTRAINT sections_classroom_schedule_conflict EXCLUDE USING gist (
+        classroom_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
+
+CREATE INDEX idx_section_meetings_section_id ON section_meetings(section_id);
+CREATE INDEX idx_section_meetings_classroom_id ON section_meetings(classroom_id);
+
+ALTER TABLE sections
+    DROP COLUMN classroom_id,
+    DROP COLUMN start_time,
+    DROP COLUMN duration_minutes;
+
+-- Function to copy section term and teacher changes to its meetings (returns trigger)
+CREATE OR REPLACE FUNCTION sync_section_meetings()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE section_meetings
+    SET term_id = NEW.term_id,
+        teacher_id = NEW.teacher_id
+    WHERE section_id = NEW.id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to ensure a section fits in every classroom it meets in (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_section_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section_id INTEGER;
+    v_max_enrollment INTEGER;
+    v_capacity INTEGER;
+BEGIN
+    IF TG_TABLE_NAME = 'sections' THEN
+        v_section_id := NEW.id;
+    ELSE
+        v_section_id := NEW.section_id;
+    END IF;
+
+    SELECT s.max_enrollment, MIN(c.capacity)
+    INTO v_max_enrollment, v_capacity
+    FROM sections s
+    JOIN section_meetings m ON m.section_id = s.id
+    JOIN classrooms c ON c.id = m.classroom_id
+    WHERE s.id = v_section_id
+    GROUP BY s.id;
+
+    IF v_max_enrollment > v_capacity THEN
+        RAISE EXCEPTION 'Section max enrollment exceeds classroom capacity.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'sections_max_enrollment_capacity',
+                  DETAIL = json_build_object('max_enrollment', v_max_enrollment, 'capacity', v_capacity)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to prevent shrinking a classroom below the sections meeting in it (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_classroom_capacity()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_sections INTEGER[];
+BEGIN
+    v_sections := ARRAY(
+        SELECT DISTINCT s.id
+        FROM sections s
+        JOIN section_meetings m ON m.section_id = s.id
+        WHERE m.classroom_id = NEW.id AND s.max_enrollment > NEW.capacity
+        ORDER BY s.id
+    );
+
+    IF cardinality(v_sections) > 0 THEN
+        RAISE EXCEPTION 'Classroom capacity is below the max enrollment of hosted sections.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'classrooms_capacity_hosted_sections',
+                  DETAIL = json_build_object('capacity', NEW.capacity, 'section_ids', v_sections)::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sync_section_meetings
+AFTER UPDATE OF term_id, teacher_id ON sections
+FOR EACH ROW
+EXECUTE FUNCTION sync_section_meetings();
+
+-- Constraint triggers to keep sections within the capacity of every classroom they meet in
+CREATE CONSTRAINT TRIGGER trg_enforce_section_capacity
+AFTER UPDATE OF max_enrollment ON sections
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_capacity();
+
+CREATE CONSTRAINT TRIGGER trg_enforce_meeting_capacity
+AFTER INSERT OR UPDATE OF classroom_id ON section_meetings
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_capacity();
+
+CREATE TRIGGER trg_audit_section_meetings
+AFTER INSERT OR UPDATE OR DELETE ON section_meetings
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes('section_id');
+
+-- View of section meetings as weekly patterns: the days a section meets at the same time in the same classroom
+CREATE VIEW section_meeting_view AS
+SELECT
+    m.section_id,
+    m.classroom_id,
+    m.start_time,
+    m.start_time + (m.duration_minutes || ' minutes')::INTERVAL as end_time,
+    m.duration_minutes,
+    array_agg(m.day ORDER BY m.day) as days
+FROM section_meetings m
+GROUP BY m.section_id, m.classroom_id, m.start_time, m.duration_minutes;
+
+-- View for student schedules (for PDF generation), one row per meeting pattern of each enrolled section
+CREATE VIEW student_schedule_view AS
+SELECT
+    e.student_id,
+    sec.id as section_id,
+    sec.term_id,
+    sub.code as subject_code,
+    sub.name as subject_name,
+    sec.section_code,
+    t.first_name as teacher_first_name,
+    t.last_name as teacher_last_name,
+    c.building,
+    c.room_number,
+    m.start_time,
+    m.end_time,
+    m.duration_minutes,
+    m.days
+FROM enrollments e
+JOIN sections sec ON e.section_id = sec.id
+JOIN subjects sub ON sec.subject_id = sub.id
+JOIN teachers t ON sec.teacher_id = t.id
+JOIN section_meeting_view m ON sec.id = m.section_id
+JOIN classrooms c ON m.classroom_id = c.id;
@@ -0,0 +1,61 @@
+DROP TRIGGER IF EXISTS trg_drop_linked_components ON enrollments;
+DROP TRIGGER IF EXISTS trg_enforce_section_combination ON enrollments;
+DROP TRIGGER IF EXISTS trg_enforce_section_parent ON sections;
+
+DROP FUNCTION IF EXISTS drop_linked_components();
+DROP FUNCTION IF EXISTS enforce_section_combination();
+DROP FUNCTION IF EXISTS find_combination_problems(INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS enforce_section_parent();
+
+-- Function to enroll waitlisted students into the free seats of a section, linked sections included
+-- Students with a schedule conflict or unmet requirements are skipped and keep their position.
+-- Returns the IDs of the promoted students in waitlist order.
+CREATE OR REPLACE FUNCTION promote_from_waitlist(
+    p_section_id INTEGER
+) RETURNS SETOF INTEGER AS $$
+DECLARE
+    v_entry RECORD;
+    v_free_seats INTEGER;
+BEGIN
+    SELECT max_enrollment - current_enrollment
+    INTO v_free_seats
+    FROM sections
+    WHERE id = p_section_id
+    FOR UPDATE;
+
+    IF v_free_seats IS NULL OR v_free_seats <= 0 OR NOT is_registration_open(p_section_id) THEN
+        RETURN;
+    END IF;
+
+    FOR v_entry IN
+        SELECT id, student_id
+        FROM waitlist_entries
+        WHERE section_id = p_section_id
+        ORDER BY created_at, id
+    LOOP
+        EXIT WHEN v_free_seats <= 0;
+
+        CONTINUE WHEN check_schedule_conflict(v_entry.student_id, p_section_id);
+
+        CONTINUE WHEN EXISTS (SELECT 1 FROM find_unmet_requirements(v_entry.student_id, p_section_id));
+
+        DELETE FROM waitlist_entries WHERE id = v_entry.id;
+
+        INSERT INTO enrollments (student_id, section_id)
+        VALUES (v_entry.student_id, p_section_id);
+
+        v_free_seats := v_free_seats - 1;
+
+        RETURN NEXT v_entry.student_id;
+    END LOOP;
+
+    RETURN;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Components become standalone sections
+DROP INDEX IF EXISTS idx_sections_parent_section_id;
+
+ALTER TABLE sections
+    DROP COLUMN parent_section_id,
+    DROP COLUMN component_type;
@@ -0,0 +1,206 @@
+-- Linked sections.
+-- A lecture section may have child components, e.g. labs and recitations of the same subject and term.
+-- A student enrolled in a lecture with components takes exactly one of its components of each type,
+-- and components only together with their lecture. The combination is checked when the transaction
+-- commits, so a lecture and its components can be enrolled one statement at a time:
+--   SC008 invalid_section_combination {"student_id", "section_id", "missing_component_types", "duplicate_component_types"}
+ALTER TABLE sections
+    ADD COLUMN parent_section_id INTEGER REFERENCES sections(id),
+    ADD COLUMN component_type TEXT NOT NULL DEFAULT 'lecture',
+    ADD CONSTRAINT sections_component_type_check CHECK (component_type IN ('lecture', 'lab', 'recitation')),
+    ADD CONSTRAINT sections_component_parent_check CHECK ((parent_section_id IS NULL) = (component_type = 'lecture'));
+
+CREATE INDEX idx_sections_parent_section_id ON sections(parent_section_id);
+
+-- Function to ensure components belong to a lecture of the same subject and term (returns trigger)
+-- A lecture with components can neither become a component nor move to another subject or term.
+CREATE OR REPLACE FUNCTION enforce_section_parent()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.parent_section_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1
+        FROM sections p
+        WHERE p.id = NEW.parent_section_id
+          AND p.id <> NEW.id
+          AND p.parent_section_id IS NULL
+          AND p.subject_id = NEW.subject_id
+          AND p.term_id IS NOT DISTINCT FROM NEW.term_id
+    )) OR (TG_OP = 'UPDATE' AND EXISTS (
+        SELECT 1
+        FROM sections c
+        WHERE c.parent_section_id = NEW.id
+          AND (NEW.parent_section_id IS NOT NULL
+               OR c.subject_id <> NEW.subject_id
+               OR c.term_id IS DISTINCT FROM NEW.term_id)
+    )) THEN
+        RAISE EXCEPTION 'Components must belong to a lecture of the same subject and term.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'sections_parent_section_link',
+                  DETAIL = json_build_object('section_id', NEW.id, 'parent_section_id', NEW.parent_section_id)::TEXT;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to list what keeps a student's enrollments in a lecture and its components from being a valid combination
+-- Returns the component types the student takes none of ('missing') or more than one of ('duplicate');
+-- components taken without their lecture report the lecture as missing.
+CREATE OR REPLACE FUNCTION find_combination_problems(
+    p_student_id INTEGER,
+    p_lecture_id INTEGER
+) RETURNS TABLE (component_type TEXT, problem TEXT) AS $$
+    WITH enrolled AS (
+        SELECT s.id, s.component_type
+        FROM enrollments e
+        JOIN sections s ON s.id = e.section_id
+        WHERE e.student_id = p_student_id
+          AND (s.id = p_lecture_id OR s.parent_section_id = p_lecture_id)
+    ),
+    offered AS (
+        SELECT DISTINCT s.component_type
+        FROM sections s
+        WHERE s.parent_section_id = p_lecture_id
+    )
+    SELECT 'lecture', 'missing'
+    WHERE EXISTS (SELECT 1 FROM enrolled WHERE id <> p_lecture_id)
+      AND NOT EXISTS (SELECT 1 FROM enrolled WHERE id = p_lecture_id)
+    UNION ALL
+    SELECT o.component_type, CASE WHEN COUNT(en.id) = 0 THEN 'missing' ELSE 'duplicate' END
+    FROM offered o
+    LEFT JOIN enrolled en ON en.component_type = o.component_type
+    WHERE EXISTS (SELECT 1 FROM enrolled WHERE id = p_lecture_id)
+    GROUP BY o.component_type
+    HAVING COUNT(en.id) <> 1
+    ORDER BY 1;
+$$ LANGUAGE sql STABLE;
+
+-- Function to check a student's lecture and component combination at commit (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_section_combination()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_enrollment enrollments%ROWTYPE;
+    v_lecture_id INTEGER;
+    v_missing TEXT[];
+    v_duplicate TEXT[];
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_enrollment := OLD;
+    ELSE
+        v_enrollment := NEW;
+    END IF;
+
+    SELECT COALESCE(parent_section_id, id) INTO v_lecture_id FROM sections WHERE id = v_enrollment.section_id;
+
+    -- The section was deleted later in the transaction
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT
+        array_agg(p.component_type) FILTER (WHERE p.problem = 'missing'),
+        array_agg(p.component_type) FILTER (WHERE p.problem = 'duplicate')
+    INTO v_missing, v_duplicate
+    FROM find_combination_problems(v_enrollment.student_id, v_lecture_id) p;
+
+    IF v_missing IS NOT NULL OR v_duplicate IS NOT NULL THEN
+        RAISE EXCEPTION 'Sections do not form a valid combination of a lecture and its components.'
+            USING ERRCODE = 'SC008',
+                  DETAIL = json_build_object(
+                      'student_id', v_enrollment.student_id,
+                      'section_id', v_lecture_id,
+                      'missing_component_types', COALESCE(v_missing, '{}'),
+                      'duplicate_component_types', COALESCE(v_duplicate, '{}')
+                  )::TEXT;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to drop a student's components of a dropped lecture (returns trigger)
+CREATE OR REPLACE FUNCTION drop_linked_components()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM enrollments e
+    USING sections s
+    WHERE s.id = e.section_id
+      AND s.parent_section_id = OLD.section_id
+      AND e.student_id = OLD.student_id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to enroll waitlisted students into the free seats of a section
+-- Students with a schedule conflict or unmet requirements are skipped and keep their position.
+-- Linked sections are left alone: a waitlisted student cannot take a lecture without choosing its components,
+-- nor a component in addition to the one already taken.
+-- Returns the IDs of the promoted students in waitlist order.
+CREATE OR REPLACE FUNCTION promote_from_waitlist(
+    p_section_id INTEGER
+) RETURNS SETOF INTEGER AS $$
+DECLARE
+    v_entry RECORD;
+    v_free_seats INTEGER;
+BEGIN
+    SELECT max_enrollment - current_enrollment
+    INTO v_free_seats
+    FROM sections
+    WHERE id = p_section_id
+    FOR UPDATE;
+
+    IF v_free_seats IS NULL OR v_free_seats <= 0 OR NOT is_registration_open(p_section_id) THEN
+        RETURN;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM sections
+        WHERE (id = p_section_id AND parent_section_id IS NOT NULL) OR parent_section_id = p_section_id
+    ) THEN
+        RETURN;
+    END IF;
+
+    FOR v_entry IN
+        SELECT id, student_id
+        FROM waitlist_entries
+        WHERE section_id = p_section_id
+        ORDER BY created_at, id
+    LOOP
+        EXIT WHEN v_free_seats <= 0;
+
+        CONTINUE WHEN check_schedule_conflict(v_entry.student_id, p_section_id);
+
+        CONTINUE WHEN EXISTS (SELECT 1 FROM find_unmet_requirements(v_entry.student_id, p_section_id));
+
+        DELETE FROM waitlist_entries WHERE id = v_entry.id;
+
+        INSERT INTO enrollments (student_id, section_id)
+        VALUES (v_entry.student_id, p_section_id);
+
+        v_free_seats := v_free_seats - 1;
+
+        RETURN NEXT v_entry.student_id;
+    END LOOP;
+
+    RETURN;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_enforce_section_parent
+BEFORE INSERT OR UPDATE OF parent_section_id, component_type, subject_id, term_id ON sections
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_parent();
+
+-- Deferred until commit, or until SET CONSTRAINTS trg_enforce_section_combination IMMEDIATE
+CREATE CONSTRAINT TRIGGER trg_enforce_section_combination
+AFTER INSERT OR DELETE ON enrollments
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION enforce_section_combination();
+
+CREATE TRIGGER trg_drop_linked_components
+AFTER DELETE ON enrollments
+FOR EACH ROW
+EXECUTE FUNCTION drop_linked_components();
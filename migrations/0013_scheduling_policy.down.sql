@@ -0,0 +1,16 @@
+-- The fixed scheduling rules come back; they are NOT VALID, so meetings already scheduled under a wider policy stay.
+-- The saturday day_of_week value stays, as enum values cannot be dropped.
+DROP TRIGGER IF EXISTS trg_audit_meeting_patterns ON meeting_patterns;
+DROP TRIGGER IF EXISTS trg_audit_scheduling_policy ON scheduling_policy;
+DROP TRIGGER IF EXISTS trg_enforce_scheduling_policy ON section_meetings;
+
+DROP FUNCTION IF EXISTS enforce_scheduling_policy();
+
+DROP TABLE IF EXISTS meeting_patterns;
+DROP TABLE IF EXISTS scheduling_policy;
+
+ALTER TABLE section_meetings
+    ADD CONSTRAINT section_meetings_start_time_check CHECK (start_time >= '07:30:00') NOT VALID,
+    ADD CONSTRAINT section_meetings_end_time_check CHECK (start_time + (duration_minutes || ' minutes')::INTERVAL <= '22:00:00') NOT VALID,
+    ADD CONSTRAINT section_meetings_duration_minutes_check CHECK (duration_minutes IN (50, 80)) NOT VALID,
+    ADD CONSTRAINT section_meetings_day_check CHECK (day_number(day) <= 5) NOT VALID;
@@ -0,0 +1,107 @@
+-- Configurable scheduling policy.
+-- The days, durations and daily window of section meetings move from CHECK constraints into the
+-- single-row scheduling_policy table, and the meeting patterns offered by the timetable solver into
+-- meeting_patterns, so evening and weekend programs can be enabled without a schema change, e.g.
+--   UPDATE scheduling_policy SET days = days || 'saturday'::day_of_week, durations = '{50, 75, 80, 110, 170}';
+-- The defaults keep the previous rules: Monday to Friday, 50 or 80 minutes, 07:30-22:00.
+-- Changing the policy only applies to meetings created or rescheduled afterwards.
+ALTER TYPE day_of_week ADD VALUE IF NOT EXISTS 'saturday';
+
+-- Function to number the days of the week (Monday = 1), so days can take part in GiST exclusion constraints
+-- Compares the day names as text, as the new saturday value cannot be used before this migration commits.
+CREATE OR REPLACE FUNCTION day_number(p_day day_of_week)
+RETURNS SMALLINT AS $$
+    SELECT CASE p_day::text
+        WHEN 'monday' THEN 1
+        WHEN 'tuesday' THEN 2
+        WHEN 'wednesday' THEN 3
+        WHEN 'thursday' THEN 4
+        WHEN 'friday' THEN 5
+        WHEN 'saturday' THEN 6
+    END::SMALLINT;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Institution scheduling policy; the table holds exactly one row
+CREATE TABLE scheduling_policy (
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    days day_of_week[] NOT NULL DEFAULT '{monday, tuesday, wednesday, thursday, friday}',
+    durations INTEGER[] NOT NULL DEFAULT '{50, 80}',
+    day_start TIME NOT NULL DEFAULT '07:30:00',
+    day_end TIME NOT NULL DEFAULT '22:00:00',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (cardinality(days) > 0),
+    CHECK (cardinality(durations) > 0 AND 0 < ALL(durations)),
+    CHECK (day_start < day_end)
+);
+
+INSERT INTO scheduling_policy DEFAULT VALUES;
+
+-- Standard meeting patterns, e.g. MWF and TTh, offered to the timetable solver
+CREATE TABLE meeting_patterns (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    days day_of_week[] NOT NULL CHECK (cardinality(days) > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO meeting_patterns (code, days) VALUES
+    ('MWF', '{monday, wednesday, friday}'),
+    ('TTh', '{tuesday, thursday}');
+
+-- Function to ensure a section meeting keeps to the scheduling policy (returns trigger)
+CREATE OR REPLACE FUNCTION enforce_scheduling_policy()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_policy scheduling_policy%ROWTYPE;
+BEGIN
+    SELECT * INTO v_policy FROM scheduling_policy;
+
+    IF NOT NEW.day = ANY(v_policy.days)
+       OR NOT NEW.duration_minutes = ANY(v_policy.durations)
+       OR NEW.start_time < v_policy.day_start
+       OR upper(meeting_minutes(NEW.start_time, NEW.duration_minutes)) > EXTRACT(EPOCH FROM v_policy.day_end) / 60
+    THEN
+        RAISE EXCEPTION 'Section meeting violates the scheduling policy.'
+            USING ERRCODE = 'check_violation',
+                  CONSTRAINT = 'section_meetings_scheduling_policy',
+                  DETAIL = json_build_object(
+                      'day', NEW.day,
+                      'start_time', NEW.start_time,
+                      'duration_minutes', NEW.duration_minutes
+                  )::TEXT;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE section_meetings
+    DROP CONSTRAINT IF EXISTS section_meetings_start_time_check,
+    DROP CONSTRAINT IF EXISTS section_meetings_end_time_check,
+    DROP CONSTRAINT IF EXISTS section_meetings_duration_minutes_check;
+
+CREATE TRIGGER trg_enforce_scheduling_policy
+BEFORE INSERT OR UPDATE OF day, start_time, duration_minutes ON section_meetings
+FOR EACH ROW
+EXECUTE FUNCTION enforce_scheduling_policy();
+
+CREATE TRIGGER update_scheduling_policy_updated_at
+BEFORE UPDATE ON scheduling_policy
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_meeting_patterns_updated_at
+BEFORE UPDATE ON meeting_patterns
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER trg_audit_scheduling_policy
+AFTER INSERT OR UPDATE OR DELETE ON scheduling_policy
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trg_audit_meeting_patterns
+AFTER INSERT OR UPDATE OR DELETE ON meeting_patterns
+FOR EACH ROW
+EXECUTE FUNCTION audit_changes();
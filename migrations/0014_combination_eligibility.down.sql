@@ -0,0 +1,73 @@
+DROP FUNCTION IF EXISTS find_combination_problems(INTEGER, INTEGER, INTEGER);
+
+-- Function to list what keeps a student's enrollments in a lecture and its components from being a valid combination
+-- Returns the component types the student takes none of ('missing') or more than one of ('duplicate');
+-- components taken without their lecture report the lecture as missing.
+CREATE OR REPLACE FUNCTION find_combination_problems(
+    p_student_id INTEGER,
+    p_lecture_id INTEGER
+) RETURNS TABLE (component_type TEXT, problem TEXT) AS $$
+    WITH enrolled AS (
+        SELECT s.id, s.component_type
+        FROM enrollments e
+        JOIN sections s ON s.id = e.section_id
+        WHERE e.student_id = p_student_id
+          AND (s.id = p_lecture_id OR s.parent_section_id = p_lecture_id)
+    ),
+    offered AS (
+        SELECT DISTINCT s.component_type
+        FROM sections s
+        WHERE s.parent_section_id = p_lecture_id
+    )
+    SELECT 'lecture', 'missing'
+    WHERE EXISTS (SELECT 1 FROM enrolled WHERE id <> p_lecture_id)
+      AND NOT EXISTS (SELECT 1 FROM enrolled WHERE id = p_lecture_id)
+    UNION ALL
+    SELECT o.component_type, CASE WHEN COUNT(en.id) = 0 THEN 'missing' ELSE 'duplicate' END
+    FROM offered o
+    LEFT JOIN enrolled en ON en.component_type = o.component_type
+    WHERE EXISTS (SELECT 1 FROM enrolled WHERE id = p_lecture_id)
+    GROUP BY o.component_type
+    HAVING COUNT(en.id) <> 1
+    ORDER BY 1;
+$$ LANGUAGE sql STABLE;
+
+-- Function to list every reason an enrollment would be rejected, without inserting it.
+-- Mirrors trg_prevent_enrollment_conflicts and trg_update_enrollment_count, but reports all failures
+-- instead of raising on the first one.
+CREATE OR REPLACE FUNCTION find_enrollment_blockers(
+    p_student_id INTEGER,
+    p_section_id INTEGER
+) RETURNS SETOF TEXT AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+BEGIN
+    SELECT * INTO v_section FROM sections WHERE id = p_section_id;
+
+    IF NOT FOUND THEN
+        RETURN NEXT 'section_not_found';
+        RETURN;
+    END IF;
+
+    IF NOT is_registration_open(p_section_id) THEN
+        RETURN NEXT 'registration_closed';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM find_unmet_requirements(p_student_id, p_section_id)) THEN
+        RETURN NEXT 'requirements_not_met';
+    END IF;
+
+    -- An enrolled section overlaps itself, so the conflict check only applies to other sections
+    IF EXISTS (
+        SELECT 1 FROM enrollments WHERE student_id = p_student_id AND section_id = p_section_id
+    ) THEN
+        RETURN NEXT 'already_enrolled';
+    ELSIF check_schedule_conflict(p_student_id, p_section_id) THEN
+        RETURN NEXT 'schedule_conflict';
+    END IF;
+
+    IF v_section.current_enrollment >= v_section.max_enrollment THEN
+        RETURN NEXT 'section_full';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,29 @@
+-- Terms become optional again; sections moved into the LEGACY term stay there.
+ALTER TABLE student_meetings
+    ALTER COLUMN term_id DROP NOT NULL,
+    DROP CONSTRAINT student_meetings_schedule_conflict,
+    ADD CONSTRAINT student_meetings_schedule_conflict EXCLUDE USING gist (
+        student_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
+
+ALTER TABLE section_meetings
+    ALTER COLUMN term_id DROP NOT NULL,
+    DROP CONSTRAINT sections_teacher_schedule_conflict,
+    DROP CONSTRAINT sections_classroom_schedule_conflict,
+    ADD CONSTRAINT sections_teacher_schedule_conflict EXCLUDE USING gist (
+        teacher_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    ),
+    ADD CONSTRAINT sections_classroom_schedule_conflict EXCLUDE USING gist (
+        classroom_id WITH =,
+        (COALESCE(term_id, 0)) WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
+
+ALTER TABLE sections ALTER COLUMN term_id DROP NOT NULL;
@@ -0,0 +1,56 @@
+-- Required section terms.
+-- Every section belongs to a term. Sections created while the term was optional, including those of
+-- databases set up by the former sql-bootstrap init scripts, move into the LEGACY term, created only when
+-- there are such sections. Its registration stays open as it was for them; its dates start on the day the
+-- first of them was created and can be corrected, or the sections moved into their real terms, afterwards.
+INSERT INTO terms (code, name, start_date, end_date, registration_opens_at, registration_closes_at)
+SELECT
+    'LEGACY',
+    'Sections created without a term',
+    MIN(created_at)::DATE,
+    (MIN(created_at) + INTERVAL '1 year')::DATE,
+    MIN(created_at),
+    '9999-12-31'
+FROM sections
+WHERE term_id IS NULL
+HAVING COUNT(*) > 0;
+
+-- Lectures and their components move together, so the parent check would see them half moved
+ALTER TABLE sections DISABLE TRIGGER trg_enforce_section_parent;
+
+-- Meetings and the meetings of enrolled students follow through trg_sync_section_meetings
+UPDATE sections
+SET term_id = (SELECT id FROM terms WHERE code = 'LEGACY')
+WHERE term_id IS NULL;
+
+ALTER TABLE sections ENABLE TRIGGER trg_enforce_section_parent;
+
+ALTER TABLE sections ALTER COLUMN term_id SET NOT NULL;
+
+-- Meetings only clash within their term, which no longer needs a stand-in for sections without one
+ALTER TABLE section_meetings
+    ALTER COLUMN term_id SET NOT NULL,
+    DROP CONSTRAINT sections_teacher_schedule_conflict,
+    DROP CONSTRAINT sections_classroom_schedule_conflict,
+    ADD CONSTRAINT sections_teacher_schedule_conflict EXCLUDE USING gist (
+        teacher_id WITH =,
+        term_id WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    ),
+    ADD CONSTRAINT sections_classroom_schedule_conflict EXCLUDE USING gist (
+        classroom_id WITH =,
+        term_id WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
+
+ALTER TABLE student_meetings
+    ALTER COLUMN term_id SET NOT NULL,
+    DROP CONSTRAINT student_meetings_schedule_conflict,
+    ADD CONSTRAINT student_meetings_schedule_conflict EXCLUDE USING gist (
+        student_id WITH =,
+        term_id WITH =,
+        (day_number(day)) WITH =,
+        minutes WITH &&
+    );
@@ -0,0 +1,107 @@
+-- Waitlists of linked sections.
+-- A promotion could not choose the components of a lecture, so promote_from_waitlist skips linked sections;
+-- students are kept off their waitlists from the start instead of waiting for a seat they never get:
+--   SC009 linked_section_waitlist {"section_id"}
+
+-- Function to validate a new waitlist entry (returns trigger)
+-- Lectures with components and the components themselves cannot be waitlisted.
+CREATE OR REPLACE FUNCTION check_waitlist_entry()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_section sections%ROWTYPE;
+    v_waitlisted INTEGER;
+BEGIN
+    -- Lock the section so concurrent joins see a consistent waitlist length
+    SELECT * INTO v_section FROM sections WHERE id = NEW.section_id FOR UPDATE;
+
+    IF v_section.parent_section_id IS NOT NULL
+       OR EXISTS (SELECT 1 FROM sections WHERE parent_section_id = NEW.section_id) THEN
+        RAISE EXCEPTION 'Linked sections have no waitlist. Enroll in the lecture and its components together.'
+            USING ERRCODE = 'SC009',
+                  DETAIL = json_build_object('section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM enrollments WHERE student_id = NEW.student_id AND section_id = NEW.section_id
+    ) THEN
+        RAISE EXCEPTION 'Student is already enrolled in this section.'
+            USING ERRCODE = 'SC005',
+                  DETAIL = json_build_object('student_id', NEW.student_id, 'section_id', NEW.section_id)::TEXT;
+    END IF;
+
+    IF v_section.current_enrollment < v_section.max_enrollment THEN
+        RAISE EXCEPTION 'Section has open seats. Enroll directly.'
+            USING ERRCODE = 'SC006',
+                  DETAIL = json_build_object(
+                      'section_id', NEW.section_id,
+                      'seats_available', v_section.max_enrollment - v_section.current_enrollment
+                  )::TEXT;
+    END IF;
+
+    SELECT COUNT(*) INTO v_waitlisted FROM waitlist_entries WHERE section_id = NEW.section_id;
+
+    IF v_waitlisted >= v_section.max_waitlist THEN
+        RAISE EXCEPTION 'Waitlist is full.'
+            USING ERRCODE = 'SC007',
+                  DETAIL = json_build_object('section_id', NEW.section_id, 'max_waitlist', v_section.max_waitlist)::TEXT;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function to enroll waitlisted students into the free seats of a section
+-- Students with a schedule conflict or unmet requirements are skipped and keep their position.
+-- Linked sections are left alone: a waitlisted student cannot take a lecture without choosing its components,
+-- nor a component in addition to the one already taken. check_waitlist_entry keeps students off their waitlists.
+-- Returns the IDs of the promoted students in waitlist order.
+CREATE OR REPLACE FUNCTION promote_from_waitlist(
+    p_section_id INTEGER
+) RETURNS SETOF INTEGER AS $$
+DECLARE
+    v_entry RECORD;
+    v_free_seats INTEGER;
+BEGIN
+    SELECT max_enrollment - current_enrollment
+    INTO v_free_seats
+    FROM sections
+    WHERE id = p_section_id
+    FOR UPDATE;
+
+    IF v_free_seats IS NULL OR v_free_seats <= 0 OR NOT is_registration_open(p_section_id) THEN
+        RETURN;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM sections
+        WHERE (id = p_section_id AND parent_section_id IS NOT NULL) OR parent_section_id = p_section_id
+    ) THEN
+        RETURN;
+    END IF;
+
+    FOR v_entry IN
+        SELECT id, student_id
+        FROM waitlist_entries
+        WHERE section_id = p_section_id
+        ORDER BY created_at, id
+    LOOP
+        EXIT WHEN v_free_seats <= 0;
+
+        CONTINUE WHEN check_schedule_conflict(v_entry.student_id, p_section_id);
+
+        CONTINUE WHEN EXISTS (SELECT 1 FROM find_unmet_requirements(v_entry.student_id, p_section_id));
+
+        DELETE FROM waitlist_entries WHERE id = v_entry.id;
+
+        INSERT INTO enrollments (student_id, section_id)
+        VALUES (v_entry.student_id, p_section_id);
+
+        v_free_seats := v_free_seats - 1;
+
+        RETURN NEXT v_entry.student_id;
+    END LOOP;
+
+    RETURN;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations of the database schema.
+// Each version has a NNNN_name.up.sql script and a matching NNNN_name.down.sql script that reverts it.
+package migrations
+
+import "embed"
+
+// FS holds the migration scripts.
+//
+//go:embed *.sql
+var FS embed.FS